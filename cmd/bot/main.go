@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"os"
 
 	"github.com/hcavarsan/slack-opsgenie-bot/internal/api"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/bootstrap"
 	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
 	"github.com/hcavarsan/slack-opsgenie-bot/internal/handler"
-	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
+	mode := flag.String("mode", "http", "how to receive Slack requests: http or socket")
+	flag.Parse()
+
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.JSONFormatter{})
 
@@ -23,24 +29,41 @@ func main() {
 		logger.Fatalf("Failed to load config: %v", err)
 	}
 
-	slackService := service.NewSlackServiceWithLogger(cfg.SlackBotToken, logger)
-	alertService := service.NewAlertServiceWithLogger(
-		cfg.OpsGenieAPIKey,
-		cfg.OpsGenieTeamID,
-		cfg.OpsgenieDomain,
-		logger,
-	)
-	slackHandler := handler.NewSlackHandler(
-		slackService,
-		alertService,
-		cfg.SlackSigningSecret,
-		logger,
-	)
-
-	server := api.NewServer(slackHandler, logger, cfg.Port)
-
-	logger.WithField("port", cfg.Port).Info("Starting server...")
-	if err := server.Start(); err != nil {
-		logger.Fatalf("Server failed to start: %v", err)
+	jobQueue, err := queue.New(cfg.Queue, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create job queue: %v", err)
+	}
+
+	live := config.NewLive(cfg)
+	bot, err := bootstrap.New(live, jobQueue, logger)
+	if err != nil {
+		logger.Fatalf("Failed to start bot: %v", err)
+	}
+
+	jobQueue.Start(context.Background())
+	go bot.WatchSecrets(context.Background())
+
+	switch *mode {
+	case "socket":
+		if cfg.SlackAppToken == "" {
+			logger.Fatal("SLACK_APP_TOKEN is required in socket mode")
+		}
+
+		server := api.NewSocketServer(bot.App, logger, live.SocketTokens)
+
+		logger.Info("Starting Socket Mode client...")
+		if err := server.Start(); err != nil {
+			logger.Fatalf("Socket Mode client failed: %v", err)
+		}
+	case "http":
+		server := api.NewServer(handler.NewHTTPHandler(bot.App, live.SigningSecret, logger), bot.OAuth, logger, cfg.Port)
+		server.HandleMetrics(live.MetricsToken)
+
+		logger.WithField("port", cfg.Port).Info("Starting server...")
+		if err := server.Start(); err != nil {
+			logger.Fatalf("Server failed to start: %v", err)
+		}
+	default:
+		logger.Fatalf("Unknown mode %q, use http or socket", *mode)
 	}
 }
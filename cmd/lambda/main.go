@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/api"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/bootstrap"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/handler"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	if os.Getenv("DEBUG") == "true" {
+		logger.SetLevel(logrus.DebugLevel)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalf("Failed to load config: %v", err)
+	}
+
+	if !cfg.Store.Shared() {
+		logger.Fatalf("AWS Lambda needs store.backend %q, got %q", config.StoreBackendRedis, cfg.Store.Backend)
+	}
+
+	backend, err := queue.NewLambdaBackend(context.Background(), os.Getenv("AWS_LAMBDA_FUNCTION_NAME"))
+	if err != nil {
+		logger.Fatalf("Failed to create job queue: %v", err)
+	}
+	jobQueue := queue.NewWithBackend(backend, 0, cfg.Queue.MaxAttempts, logger)
+
+	live := config.NewLive(cfg)
+	bot, err := bootstrap.New(live, jobQueue, logger)
+	if err != nil {
+		logger.Fatalf("Failed to start bot: %v", err)
+	}
+
+	go bot.WatchSecrets(context.Background())
+
+	server := api.NewServer(handler.NewHTTPHandler(bot.App, live.SigningSecret, logger), bot.OAuth, logger, cfg.Port)
+	server.HandleMetrics(live.MetricsToken)
+	lambda.Start(api.NewLambdaAdapter(server.Handler(), jobQueue, logger).Invoke)
+}
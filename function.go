@@ -1,13 +1,18 @@
 package slack_opsgenie_bot
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"os"
+	"sync"
 
 	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/api"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/bootstrap"
 	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
 	"github.com/hcavarsan/slack-opsgenie-bot/internal/handler"
-	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
 	"github.com/sirupsen/logrus"
 )
 
@@ -15,44 +20,73 @@ func init() {
 	functions.HTTP("SlackOpsGenieBot", slackOpsgenieBotFunction)
 }
 
-func slackOpsgenieBotFunction(w http.ResponseWriter, r *http.Request) {
+var (
+	initMu sync.Mutex
+	router http.Handler
+	logger = newLogger()
+)
+
+func newLogger() *logrus.Logger {
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.JSONFormatter{})
 
 	if os.Getenv("DEBUG") == "true" {
 		logger.SetLevel(logrus.DebugLevel)
 	}
+	return logger
+}
 
-	cfg, err := config.Load()
+func slackOpsgenieBotFunction(w http.ResponseWriter, r *http.Request) {
+	next, err := initialize()
 	if err != nil {
-		logger.Fatalf("Failed to load config: %v", err)
+		logger.WithError(err).Error("Function is not initialized")
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	slackService := service.NewSlackServiceWithLogger(cfg.SlackBotToken, logger)
-	alertService := service.NewAlertServiceWithLogger(
-		cfg.OpsGenieAPIKey,
-		cfg.OpsGenieTeamID,
-		cfg.OpsgenieDomain,
-		logger,
-	)
-	slackHandler := handler.NewSlackHandler(
-		slackService,
-		alertService,
-		cfg.SlackSigningSecret,
-		logger,
-	)
-
-	switch r.URL.Path {
-	case "/slack/commands":
-		slackHandler.HandleSlashCommand(w, r)
-	case "/slack/interactivity":
-		slackHandler.HandleInteractivity(w, r)
-	case "/health":
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	default:
-		http.NotFound(w, r)
+	next.ServeHTTP(w, r)
+}
+
+func initialize() (http.Handler, error) {
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	if router != nil {
+		return router, nil
+	}
+
+	next, err := setup()
+	if err != nil {
+		return nil, err
+	}
+	router = next
+	return router, nil
+}
+
+func setup() (http.Handler, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cfg.Queue.Backend != config.QueueBackendCloudTasks {
+		return nil, fmt.Errorf("cloud functions need queue.backend %q, got %q", config.QueueBackendCloudTasks, cfg.Queue.Backend)
+	}
+	if !cfg.Store.Shared() {
+		return nil, fmt.Errorf("cloud functions need store.backend %q, got %q", config.StoreBackendRedis, cfg.Store.Backend)
+	}
+	live := config.NewLive(cfg)
+	jobQueue := queue.NewWithBackend(queue.NewCloudTasksBackend(cfg.Queue.CloudTasks, live.SigningSecret), 0, cfg.Queue.MaxAttempts, logger)
+	bot, err := bootstrap.New(live, jobQueue, logger)
+	if err != nil {
+		return nil, err
 	}
+
+	go bot.WatchSecrets(context.Background())
+
+	logger.Info("Function initialized")
+	server := api.NewServer(handler.NewHTTPHandler(bot.App, live.SigningSecret, logger), bot.OAuth, logger, cfg.Port)
+	server.HandleMetrics(live.MetricsToken)
+	server.HandleTasks(handler.NewTaskHandler(jobQueue, live.SigningSecret, logger))
+	return server.Handler(), nil
 }
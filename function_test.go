@@ -0,0 +1,74 @@
+package slack_opsgenie_bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const redisStoreConfig = `store:
+  backend: redis
+`
+
+const cloudTasksConfig = `queue:
+  backend: cloudtasks
+  cloud_tasks:
+    queue: projects/demo/locations/us-central1/queues/opsgenie-bot
+    url: https://example.cloudfunctions.net/slack-opsgenie-bot/jobs
+`
+
+func TestInitializeRetriesAfterFailure(t *testing.T) {
+	t.Setenv("SLACK_SIGNING_SECRET", "")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("OPSGENIE_API_KEY", "")
+	t.Setenv("OPSGENIE_TEAM_ID", "")
+	t.Setenv("BOT_CONFIG_FILE", "")
+	t.Setenv("REDIS_URL", "redis://127.0.0.1:6379")
+	t.Cleanup(func() { router = nil })
+
+	recorder := httptest.NewRecorder()
+	slackOpsgenieBotFunction(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+	}
+	if router != nil {
+		t.Fatal("failed initialization was cached")
+	}
+
+	t.Setenv("SLACK_SIGNING_SECRET", "secret")
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("OPSGENIE_API_KEY", "key")
+	t.Setenv("OPSGENIE_TEAM_ID", "team")
+
+	if _, err := initialize(); err == nil {
+		t.Fatal("initialize() succeeded without the cloudtasks queue backend")
+	}
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(cloudTasksConfig), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("BOT_CONFIG_FILE", configFile)
+
+	if _, err := initialize(); err == nil {
+		t.Fatal("initialize() succeeded with a per-instance store")
+	}
+
+	if err := os.WriteFile(configFile, []byte(redisStoreConfig+cloudTasksConfig), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	first, err := initialize()
+	if err != nil {
+		t.Fatalf("initialize() error = %v", err)
+	}
+	second, err := initialize()
+	if err != nil {
+		t.Fatalf("initialize() error = %v", err)
+	}
+	if first != second {
+		t.Fatal("successful initialization was not reused")
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/sirupsen/logrus"
+)
+
+const lambdaPayloadV2 = "2.0"
+
+type LambdaAdapter struct {
+	handler http.Handler
+	jobs    *queue.Queue
+	logger  *logrus.Logger
+}
+
+type lambdaEvent struct {
+	Version string `json:"version"`
+}
+
+func NewLambdaAdapter(handler http.Handler, jobs *queue.Queue, logger *logrus.Logger) *LambdaAdapter {
+	return &LambdaAdapter{
+		handler: handler,
+		jobs:    jobs,
+		logger:  logger,
+	}
+}
+
+func (a *LambdaAdapter) Invoke(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var jobEvent queue.LambdaEvent
+	if err := json.Unmarshal(payload, &jobEvent); err == nil && jobEvent.Job != nil {
+		if err := a.jobs.Process(ctx, *jobEvent.Job); err != nil {
+			a.logger.WithError(err).WithField("job_id", jobEvent.Job.ID).Error("Failed to process job")
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	req, v2, err := requestFromEvent(ctx, payload)
+	if err != nil {
+		a.logger.WithError(err).Error("Failed to convert Lambda event")
+		return nil, err
+	}
+	return a.serve(req, v2), nil
+}
+
+func (a *LambdaAdapter) serve(req *http.Request, v2 bool) interface{} {
+	rec := httptest.NewRecorder()
+	a.handler.ServeHTTP(rec, req)
+
+	headers := make(map[string]string, len(rec.Header()))
+	for key, values := range rec.Header() {
+		headers[key] = strings.Join(values, ",")
+	}
+
+	a.logger.WithFields(logrus.Fields{
+		"method":      req.Method,
+		"path":        req.URL.Path,
+		"status_code": rec.Code,
+	}).Debug("Served Lambda request")
+
+	if v2 {
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode: rec.Code,
+			Headers:    headers,
+			Body:       rec.Body.String(),
+		}
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: rec.Code,
+		Headers:    headers,
+		Body:       rec.Body.String(),
+	}
+}
+
+func requestFromEvent(ctx context.Context, payload json.RawMessage) (*http.Request, bool, error) {
+	var probe lambdaEvent
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return nil, false, fmt.Errorf("error decoding event: %w", err)
+	}
+
+	if probe.Version == lambdaPayloadV2 {
+		var event events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, true, fmt.Errorf("error decoding v2 event: %w", err)
+		}
+		req, err := requestFromV2(ctx, event)
+		return req, true, err
+	}
+
+	var event events.APIGatewayProxyRequest
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, false, fmt.Errorf("error decoding v1 event: %w", err)
+	}
+	req, err := requestFromV1(ctx, event)
+	return req, false, err
+}
+
+func requestFromV2(ctx context.Context, event events.APIGatewayV2HTTPRequest) (*http.Request, error) {
+	path := event.RawPath
+	if stage := event.RequestContext.Stage; stage != "" && stage != "$default" {
+		path = strings.TrimPrefix(path, "/"+stage)
+	}
+
+	req, err := newLambdaRequest(ctx, event.RequestContext.HTTP.Method, path, event.RawQueryString, event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range event.Headers {
+		req.Header.Set(key, value)
+	}
+	for _, cookie := range event.Cookies {
+		req.Header.Add("Cookie", cookie)
+	}
+	return req, nil
+}
+
+func requestFromV1(ctx context.Context, event events.APIGatewayProxyRequest) (*http.Request, error) {
+	query := url.Values{}
+	for key, values := range event.MultiValueQueryStringParameters {
+		query[key] = values
+	}
+	for key, value := range event.QueryStringParameters {
+		if _, ok := query[key]; !ok {
+			query.Set(key, value)
+		}
+	}
+
+	req, err := newLambdaRequest(ctx, event.HTTPMethod, event.Path, query.Encode(), event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range event.MultiValueHeaders {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	for key, value := range event.Headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+	return req, nil
+}
+
+func newLambdaRequest(ctx context.Context, method, path, rawQuery, body string, isBase64 bool) (*http.Request, error) {
+	raw := []byte(body)
+	if isBase64 {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding base64 body: %w", err)
+		}
+		raw = decoded
+	}
+
+	target := path
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.RequestURI = target
+	return req, nil
+}
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/bootstrap"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/handler"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/sirupsen/logrus"
+)
+
+const testSigningSecret = "test-signing-secret"
+
+type fakeInvoker struct {
+	mu     sync.Mutex
+	inputs []*lambda.InvokeInput
+}
+
+func (f *fakeInvoker) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inputs = append(f.inputs, params)
+	return &lambda.InvokeOutput{StatusCode: http.StatusAccepted}, nil
+}
+
+func (f *fakeInvoker) take() []*lambda.InvokeInput {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	inputs := f.inputs
+	f.inputs = nil
+	return inputs
+}
+
+type rewriteTransport struct {
+	hosts map[string]*url.URL
+	next  http.RoundTripper
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for suffix, target := range t.hosts {
+		if req.URL.Host == suffix || strings.HasSuffix(req.URL.Host, "."+suffix) {
+			req = req.Clone(req.Context())
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.Host = target.Host
+			break
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+type fakeSlack struct {
+	mu        sync.Mutex
+	responses []string
+}
+
+func (f *fakeSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if strings.HasPrefix(r.URL.Path, "/commands/") {
+		f.mu.Lock()
+		f.responses = append(f.responses, string(body))
+		f.mu.Unlock()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"ok":true}`))
+}
+
+func (f *fakeSlack) responseBodies() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.responses...)
+}
+
+func fakeOpsGenie(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/maintenance":
+			end := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			w.Write([]byte(`{"data":[{"id":"mw-1","status":"active","description":"Database upgrade","time":{"type":"schedule","endDate":"` + end + `"}}]}`))
+		default:
+			t.Errorf("unexpected OpsGenie request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func newTestAdapter(t *testing.T) (*LambdaAdapter, *fakeInvoker, *fakeSlack) {
+	t.Helper()
+
+	slackAPI := &fakeSlack{}
+	slackServer := httptest.NewServer(slackAPI)
+	t.Cleanup(slackServer.Close)
+	opsgenieServer := httptest.NewServer(fakeOpsGenie(t))
+	t.Cleanup(opsgenieServer.Close)
+
+	slackURL, _ := url.Parse(slackServer.URL)
+	opsgenieURL, _ := url.Parse(opsgenieServer.URL)
+	original := http.DefaultTransport
+	http.DefaultTransport = rewriteTransport{
+		hosts: map[string]*url.URL{"slack.com": slackURL, "api.opsgenie.com": opsgenieURL},
+		next:  original,
+	}
+	t.Cleanup(func() { http.DefaultTransport = original })
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("{}\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("BOT_CONFIG_FILE", configFile)
+	t.Setenv("SLACK_SIGNING_SECRET", testSigningSecret)
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("OPSGENIE_API_KEY", "opsgenie-test")
+	t.Setenv("OPSGENIE_TEAM_ID", "team-1")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	invoker := &fakeInvoker{}
+	jobs := queue.NewWithBackend(queue.NewLambdaBackendWithClient(invoker, "slack-opsgenie-bot"), 0, cfg.Queue.MaxAttempts, logger)
+	live := config.NewLive(cfg)
+	bot, err := bootstrap.New(live, jobs, logger)
+	if err != nil {
+		t.Fatalf("bootstrap.New() error = %v", err)
+	}
+
+	server := NewServer(handler.NewHTTPHandler(bot.App, live.SigningSecret, logger), bot.OAuth, logger, "0")
+	return NewLambdaAdapter(server.Handler(), jobs, logger), invoker, slackAPI
+}
+
+func signedEvent(t *testing.T, file string) json.RawMessage {
+	t.Helper()
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	req, _, err := requestFromEvent(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("requestFromEvent(%s) error = %v", file, err)
+	}
+	body, _ := io.ReadAll(req.Body)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	signature := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	var event map[string]interface{}
+	if err := json.Unmarshal(raw, &event); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", file, err)
+	}
+	for _, key := range []string{"headers", "multiValueHeaders"} {
+		headers, ok := event[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for name := range headers {
+			if strings.EqualFold(name, "X-Slack-Request-Timestamp") || strings.EqualFold(name, "X-Slack-Signature") {
+				delete(headers, name)
+			}
+		}
+		if key == "headers" {
+			headers["X-Slack-Request-Timestamp"] = timestamp
+			headers["X-Slack-Signature"] = signature
+		} else {
+			headers["X-Slack-Request-Timestamp"] = []string{timestamp}
+			headers["X-Slack-Signature"] = []string{signature}
+		}
+	}
+
+	signed, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return signed
+}
+
+func statusAndBody(t *testing.T, resp interface{}) (int, string) {
+	t.Helper()
+	switch r := resp.(type) {
+	case events.APIGatewayV2HTTPResponse:
+		return r.StatusCode, r.Body
+	case events.APIGatewayProxyResponse:
+		return r.StatusCode, r.Body
+	default:
+		t.Fatalf("unexpected response type %T", resp)
+		return 0, ""
+	}
+}
+
+func TestLambdaAdapterAnswersRecordedEvents(t *testing.T) {
+	adapter, invoker, _ := newTestAdapter(t)
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "01-url-verification-function-url.json", want: "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P"},
+		{file: "02-slash-command-api-gateway.json", want: "audit log"},
+		{file: "03-health-http-api.json", want: "OK"},
+		{file: "04-maintenance-list-function-url.json", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			resp, err := adapter.Invoke(context.Background(), signedEvent(t, filepath.Join("..", "..", "testdata", "lambda", tt.file)))
+			if err != nil {
+				t.Fatalf("Invoke() error = %v", err)
+			}
+			status, body := statusAndBody(t, resp)
+			if status != http.StatusOK || !strings.Contains(body, tt.want) {
+				t.Fatalf("Invoke() = %d %q, want 200 containing %q", status, body, tt.want)
+			}
+		})
+	}
+
+	var queued []string
+	for _, input := range invoker.take() {
+		var event queue.LambdaEvent
+		if err := json.Unmarshal(input.Payload, &event); err != nil || event.Job == nil {
+			t.Fatalf("async payload %s is not a job: %v", input.Payload, err)
+		}
+		queued = append(queued, event.Job.Type)
+	}
+	if strings.Join(queued, ",") != "audit.record,maintenance.list" {
+		t.Fatalf("queued jobs %v, want the denied audit entry and the maintenance list", queued)
+	}
+}
+
+func TestLambdaAdapterRejectsUnsignedEvents(t *testing.T) {
+	adapter, invoker, _ := newTestAdapter(t)
+
+	raw, err := os.ReadFile(filepath.Join("..", "..", "testdata", "lambda", "04-maintenance-list-function-url.json"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	resp, err := adapter.Invoke(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if status, _ := statusAndBody(t, resp); status != http.StatusUnauthorized {
+		t.Fatalf("Invoke() status = %d, want 401 for a recorded signature", status)
+	}
+	if inputs := invoker.take(); len(inputs) != 0 {
+		t.Fatalf("unsigned request queued %d jobs", len(inputs))
+	}
+}
+
+func TestLambdaAdapterRunsQueuedJobsInAsyncInvocation(t *testing.T) {
+	adapter, invoker, slackAPI := newTestAdapter(t)
+
+	resp, err := adapter.Invoke(context.Background(), signedEvent(t, filepath.Join("..", "..", "testdata", "lambda", "04-maintenance-list-function-url.json")))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if status, _ := statusAndBody(t, resp); status != http.StatusOK {
+		t.Fatalf("Invoke() status = %d, want 200", status)
+	}
+	if bodies := slackAPI.responseBodies(); len(bodies) != 0 {
+		t.Fatalf("posted %d responses before the request was acknowledged, want 0", len(bodies))
+	}
+
+	inputs := invoker.take()
+	if len(inputs) != 1 {
+		t.Fatalf("queued %d async invocations, want 1", len(inputs))
+	}
+	input := inputs[0]
+	if aws.ToString(input.FunctionName) != "slack-opsgenie-bot" || input.InvocationType != types.InvocationTypeEvent {
+		t.Fatalf("invoked %s with %s, want an Event invocation of slack-opsgenie-bot", aws.ToString(input.FunctionName), input.InvocationType)
+	}
+
+	if _, err := adapter.Invoke(context.Background(), input.Payload); err != nil {
+		t.Fatalf("Invoke(job) error = %v", err)
+	}
+	bodies := slackAPI.responseBodies()
+	if len(bodies) != 1 || !strings.Contains(bodies[0], "mw-1") {
+		t.Fatalf("response_url got %q, want the maintenance window list", bodies)
+	}
+	if inputs := invoker.take(); len(inputs) != 0 {
+		t.Fatalf("successful job was queued again %d times", len(inputs))
+	}
+}
+
+func TestRequestFromEventKeepsBody(t *testing.T) {
+	req, v2, err := requestFromEvent(context.Background(), json.RawMessage(`{"version":"2.0","rawPath":"/slack/events","body":"e30=","isBase64Encoded":true,"requestContext":{"http":{"method":"POST"}}}`))
+	if err != nil {
+		t.Fatalf("requestFromEvent() error = %v", err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if !v2 || req.URL.Path != "/slack/events" || !bytes.Equal(body, []byte("{}")) {
+		t.Fatalf("requestFromEvent() = %v %s %q", v2, req.URL.Path, body)
+	}
+}
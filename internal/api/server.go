@@ -1,6 +1,8 @@
 package api
 
 import (
+	"crypto/subtle"
+	"expvar"
 	"fmt"
 	"net/http"
 
@@ -11,15 +13,17 @@ import (
 
 type Server struct {
 	router       *mux.Router
-	slackHandler *handler.SlackHandler
+	slackHandler *handler.HTTPHandler
+	oauthHandler *handler.OAuthHandler
 	logger       *logrus.Logger
 	port         string
 }
 
-func NewServer(slackHandler *handler.SlackHandler, logger *logrus.Logger, port string) *Server {
+func NewServer(slackHandler *handler.HTTPHandler, oauthHandler *handler.OAuthHandler, logger *logrus.Logger, port string) *Server {
 	server := &Server{
 		router:       mux.NewRouter(),
 		slackHandler: slackHandler,
+		oauthHandler: oauthHandler,
 		logger:       logger,
 		port:         port,
 	}
@@ -30,9 +34,40 @@ func NewServer(slackHandler *handler.SlackHandler, logger *logrus.Logger, port s
 func (s *Server) setupRoutes() {
 	s.router.HandleFunc("/slack/commands", s.slackHandler.HandleSlashCommand).Methods("POST")
 	s.router.HandleFunc("/slack/interactivity", s.slackHandler.HandleInteractivity).Methods("POST")
+	s.router.HandleFunc("/slack/events", s.slackHandler.HandleEvents).Methods("POST")
+	s.router.HandleFunc("/slack/options", s.slackHandler.HandleInteractivity).Methods("POST")
+	if s.oauthHandler != nil {
+		s.router.HandleFunc("/slack/install", s.oauthHandler.HandleInstall).Methods("GET")
+		s.router.HandleFunc("/slack/oauth/callback", s.oauthHandler.HandleCallback).Methods("GET")
+	}
 	s.router.HandleFunc("/health", s.handleHealth).Methods("GET")
 }
 
+func (s *Server) HandleMetrics(token func() string) {
+	metrics := expvar.Handler()
+	s.router.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		expected := token()
+		if expected == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+expected)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		metrics.ServeHTTP(w, r)
+	}).Methods("GET")
+}
+
+func (s *Server) HandleTasks(tasks *handler.TaskHandler) {
+	s.router.HandleFunc("/jobs", tasks.HandleJob).Methods("POST")
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/handler"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMetricsRequireToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantStatus    int
+	}{
+		{"disabled without a token", "", "Bearer anything", http.StatusNotFound},
+		{"missing credentials", "metrics-token", "", http.StatusUnauthorized},
+		{"wrong token", "metrics-token", "Bearer other", http.StatusUnauthorized},
+		{"token without bearer scheme", "metrics-token", "metrics-token", http.StatusUnauthorized},
+		{"valid token", "metrics-token", "Bearer metrics-token", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.PanicLevel)
+			server := NewServer(handler.NewHTTPHandler(nil, func() string { return "" }, logger), nil, logger, "0")
+			server.HandleMetrics(func() string { return tt.token })
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			server.Handler().ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(recorder.Body.String(), "memstats") {
+				t.Fatalf("body = %q, want expvar output", recorder.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK && strings.Contains(recorder.Body.String(), "memstats") {
+				t.Fatal("metrics were served without a valid token")
+			}
+		})
+	}
+}
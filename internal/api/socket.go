@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/handler"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+const (
+	socketReconnectMinDelay  = time.Second
+	socketReconnectMaxDelay  = time.Minute
+	socketTokenCheckInterval = time.Minute
+)
+
+type SocketServer struct {
+	app    handler.App
+	tokens func() (botToken, appToken string)
+	logger *logrus.Logger
+}
+
+func NewSocketServer(app handler.App, logger *logrus.Logger, tokens func() (botToken, appToken string)) *SocketServer {
+	return &SocketServer{
+		app:    app,
+		tokens: tokens,
+		logger: logger,
+	}
+}
+
+func (s *SocketServer) Start() error {
+	delay := socketReconnectMinDelay
+	for {
+		botToken, appToken := s.tokens()
+		client := socketmode.New(slack.New(botToken, slack.OptionAppLevelToken(appToken)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go s.handleEvents(ctx, client)
+		go s.watchTokens(ctx, cancel, botToken, appToken)
+
+		started := time.Now()
+		err := client.RunContext(ctx)
+		rotated := ctx.Err() != nil
+		cancel()
+
+		if rotated {
+			s.logger.Info("Socket Mode tokens changed, reconnecting")
+			delay = socketReconnectMinDelay
+			continue
+		}
+		if time.Since(started) > socketReconnectMaxDelay {
+			delay = socketReconnectMinDelay
+		}
+
+		s.logger.WithError(err).WithField("retry_in", delay.String()).Error("Socket Mode connection failed, reconnecting")
+		time.Sleep(delay)
+
+		delay *= 2
+		if delay > socketReconnectMaxDelay {
+			delay = socketReconnectMaxDelay
+		}
+	}
+}
+
+func (s *SocketServer) watchTokens(ctx context.Context, cancel context.CancelFunc, botToken, appToken string) {
+	ticker := time.NewTicker(socketTokenCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if currentBot, currentApp := s.tokens(); currentBot != botToken || currentApp != appToken {
+			cancel()
+			return
+		}
+	}
+}
+
+func (s *SocketServer) handleEvents(ctx context.Context, client *socketmode.Client) {
+	for {
+		var evt socketmode.Event
+		select {
+		case <-ctx.Done():
+			return
+		case evt = <-client.Events:
+		}
+
+		switch evt.Type {
+		case socketmode.EventTypeConnecting:
+			s.logger.Info("Connecting to Slack with Socket Mode")
+		case socketmode.EventTypeConnected:
+			s.logger.Info("Connected to Slack with Socket Mode")
+		case socketmode.EventTypeConnectionError:
+			s.logger.WithField("error", fmt.Sprint(evt.Data)).Warn("Socket Mode connection error")
+		case socketmode.EventTypeInvalidAuth:
+			s.logger.Fatal("Socket Mode authentication failed, check SLACK_APP_TOKEN")
+		case socketmode.EventTypeDisconnect:
+			s.logger.Info("Slack requested a Socket Mode reconnect")
+		case socketmode.EventTypeEventsAPI, socketmode.EventTypeInteractive, socketmode.EventTypeSlashCommand:
+			go s.handleRequest(client, evt)
+		default:
+			s.logger.WithField("type", evt.Type).Debug("Ignoring Socket Mode event")
+		}
+	}
+}
+
+func (s *SocketServer) handleRequest(client *socketmode.Client, evt socketmode.Event) {
+	if evt.Request == nil {
+		return
+	}
+
+	var resp *handler.Response
+	switch data := evt.Data.(type) {
+	case slackevents.EventsAPIEvent:
+		if err := s.app.HandleCallbackEvent(data, strconv.Itoa(evt.Request.RetryAttempt), evt.Request.RetryReason); err != nil {
+			s.logger.WithError(err).Error("Failed to handle event, leaving it for Slack to redeliver")
+			return
+		}
+		client.Ack(*evt.Request)
+		return
+	case slack.InteractionCallback:
+		resp = s.app.HandleInteraction(data)
+	case slack.SlashCommand:
+		resp = s.app.HandleCommand(data)
+	default:
+		s.logger.WithField("type", evt.Type).Warn("Unexpected Socket Mode payload")
+		client.Ack(*evt.Request)
+		return
+	}
+
+	if resp == nil {
+		resp = &handler.Response{}
+	}
+	if resp.Body != nil {
+		client.Ack(*evt.Request, resp.Body)
+	} else {
+		client.Ack(*evt.Request)
+	}
+}
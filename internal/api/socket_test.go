@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/handler"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+type recordingApp struct {
+	mu          sync.Mutex
+	calls       []string
+	eventErr    error
+	retryNum    string
+	retryReason string
+}
+
+func (a *recordingApp) record(call string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, call)
+}
+
+func (a *recordingApp) HandleCommand(cmd slack.SlashCommand) *handler.Response {
+	a.record("command " + cmd.Command + " " + cmd.Text)
+	return &handler.Response{Body: map[string]string{"text": "usage"}}
+}
+
+func (a *recordingApp) HandleInteraction(payload slack.InteractionCallback) *handler.Response {
+	a.record("interaction " + string(payload.Type))
+	if payload.Type == slack.InteractionTypeViewSubmission {
+		return &handler.Response{Body: map[string]string{"response_action": "clear"}}
+	}
+	return &handler.Response{}
+}
+
+func (a *recordingApp) HandleCallbackEvent(event slackevents.EventsAPIEvent, retryNum, retryReason string) error {
+	a.mu.Lock()
+	a.retryNum, a.retryReason = retryNum, retryReason
+	a.mu.Unlock()
+	a.record("event " + event.InnerEvent.Type)
+	return a.eventErr
+}
+
+func (a *recordingApp) RunJob(ctx context.Context, job queue.Job) error {
+	return nil
+}
+
+type socketAck struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+func startSocketServer(t *testing.T, app handler.App) (*websocket.Conn, chan socketAck) {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apps.connections.open":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "url": "ws" + strings.TrimPrefix(server.URL, "http") + "/socket"})
+		case "/socket":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Errorf("Upgrade() error = %v", err)
+				return
+			}
+			conns <- conn
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	socket := NewSocketServer(app, logger, func() (string, string) { return "xoxb-test", "xapp-test" })
+	client := socketmode.New(slack.New("xoxb-test", slack.OptionAppLevelToken("xapp-test"), slack.OptionAPIURL(server.URL+"/")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go socket.handleEvents(ctx, client)
+	go client.RunContext(ctx)
+
+	var conn *websocket.Conn
+	select {
+	case conn = <-conns:
+	case <-time.After(5 * time.Second):
+		t.Fatal("socket mode client did not connect")
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := conn.WriteJSON(map[string]interface{}{"type": "hello", "num_connections": 1}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	acks := make(chan socketAck, 10)
+	go func() {
+		for {
+			var ack socketAck
+			if err := conn.ReadJSON(&ack); err != nil {
+				return
+			}
+			acks <- ack
+		}
+	}()
+	return conn, acks
+}
+
+func nextAck(t *testing.T, acks chan socketAck) socketAck {
+	t.Helper()
+
+	select {
+	case ack := <-acks:
+		return ack
+	case <-time.After(5 * time.Second):
+		t.Fatal("no socket mode acknowledgement")
+		return socketAck{}
+	}
+}
+
+func TestSocketServerDispatch(t *testing.T) {
+	eventPayload := json.RawMessage(`{"type":"event_callback","event_id":"Ev1","event":{"type":"app_home_opened","user":"U1","tab":"home"}}`)
+
+	tests := []struct {
+		name        string
+		envelope    map[string]interface{}
+		eventErr    error
+		wantCall    string
+		wantAck     bool
+		wantPayload string
+	}{
+		{
+			name:        "slash command replies in the ack",
+			envelope:    map[string]interface{}{"type": "slash_commands", "payload": map[string]string{"command": "/opsgenie", "text": "audit 5", "is_enterprise_install": "false"}},
+			wantCall:    "command /opsgenie audit 5",
+			wantAck:     true,
+			wantPayload: `{"text":"usage"}`,
+		},
+		{
+			name:        "view submission replies in the ack",
+			envelope:    map[string]interface{}{"type": "interactive", "payload": map[string]interface{}{"type": "view_submission"}},
+			wantCall:    "interaction view_submission",
+			wantAck:     true,
+			wantPayload: `{"response_action":"clear"}`,
+		},
+		{
+			name:     "block action is acknowledged without a payload",
+			envelope: map[string]interface{}{"type": "interactive", "payload": map[string]interface{}{"type": "block_actions"}},
+			wantCall: "interaction block_actions",
+			wantAck:  true,
+		},
+		{
+			name:     "event is acknowledged once handled",
+			envelope: map[string]interface{}{"type": "events_api", "payload": eventPayload, "retry_attempt": 2, "retry_reason": "timeout"},
+			wantCall: "event app_home_opened",
+			wantAck:  true,
+		},
+		{
+			name:     "failed event is left for redelivery",
+			envelope: map[string]interface{}{"type": "events_api", "payload": eventPayload},
+			eventErr: errors.New("store unavailable"),
+			wantCall: "event app_home_opened",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &recordingApp{eventErr: tt.eventErr}
+			conn, acks := startSocketServer(t, app)
+
+			tt.envelope["envelope_id"] = "env-1"
+			tt.envelope["accepts_response_payload"] = true
+			if err := conn.WriteJSON(tt.envelope); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+			if err := conn.WriteJSON(map[string]interface{}{
+				"envelope_id": "env-2",
+				"type":        "interactive",
+				"payload":     map[string]interface{}{"type": "shortcut"},
+			}); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+
+			var got []string
+			for i := 0; i < 2; i++ {
+				ack := nextAck(t, acks)
+				got = append(got, ack.EnvelopeID)
+				if ack.EnvelopeID == "env-1" && string(ack.Payload) != tt.wantPayload && !(tt.wantPayload == "" && len(ack.Payload) == 0) {
+					t.Fatalf("ack payload = %s, want %s", ack.Payload, tt.wantPayload)
+				}
+				if !tt.wantAck {
+					break
+				}
+			}
+			if tt.wantAck && !contains(got, "env-1") {
+				t.Fatalf("acks = %v, want env-1 acknowledged", got)
+			}
+			if !tt.wantAck && contains(got, "env-1") {
+				t.Fatalf("acks = %v, want env-1 left unacknowledged", got)
+			}
+
+			app.mu.Lock()
+			defer app.mu.Unlock()
+			if !contains(app.calls, tt.wantCall) {
+				t.Fatalf("calls = %v, want %q", app.calls, tt.wantCall)
+			}
+			if tt.envelope["retry_attempt"] != nil && (app.retryNum != "2" || app.retryReason != "timeout") {
+				t.Fatalf("retry = %q %q, want 2 timeout", app.retryNum, app.retryReason)
+			}
+		})
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
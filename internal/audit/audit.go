@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"fmt"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+type Sink interface {
+	Write(event model.AuditEvent) error
+}
+
+type Reader interface {
+	Recent(teamID string, limit int) ([]model.AuditEvent, error)
+}
+
+func New(auditConfig config.AuditConfig, st store.Store, logger *logrus.Logger) (Sink, error) {
+	switch auditConfig.Sink {
+	case config.AuditSinkLog, "":
+		return NewLogSink(logger), nil
+	case config.AuditSinkFile:
+		return NewFileSink(auditConfig.Path), nil
+	case config.AuditSinkWebhook:
+		return NewWebhookSink(auditConfig.WebhookURL), nil
+	case config.AuditSinkStore:
+		return NewStoreSink(st), nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", auditConfig.Sink)
+	}
+}
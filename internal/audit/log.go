@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+type LogSink struct {
+	logger *logrus.Logger
+}
+
+func NewLogSink(logger *logrus.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Write(event model.AuditEvent) error {
+	s.logger.WithFields(logrus.Fields{
+		"audit":        true,
+		"time":         event.Time,
+		"team_id":      event.TeamID,
+		"action":       event.Action,
+		"outcome":      event.Outcome,
+		"user_id":      event.UserID,
+		"user_name":    event.UserName,
+		"channel_id":   event.ChannelID,
+		"source":       event.Source,
+		"priority":     event.Priority,
+		"payload_hash": event.PayloadHash,
+		"opsgenie_id":  event.OpsGenieID,
+		"tiny_id":      event.TinyID,
+		"kind":         event.Kind,
+		"error":        event.Error,
+	}).Info("Audit event")
+	return nil
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+)
+
+const (
+	storeKey   = "audit:events"
+	storeLimit = 500
+	storeTTL   = 90 * 24 * time.Hour
+)
+
+type StoreSink struct {
+	mu    sync.Mutex
+	store store.Store
+}
+
+func NewStoreSink(st store.Store) *StoreSink {
+	return &StoreSink{store: st}
+}
+
+func (s *StoreSink) Write(event model.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	events = append([]model.AuditEvent{event}, events...)
+	if len(events) > storeLimit {
+		events = events[:storeLimit]
+	}
+
+	data, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit events: %w", err)
+	}
+	return s.store.Set(storeKey, string(data), storeTTL)
+}
+
+func (s *StoreSink) Recent(teamID string, limit int) ([]model.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	recent := make([]model.AuditEvent, 0, limit)
+	for _, event := range events {
+		if len(recent) == limit {
+			break
+		}
+		if teamID != "" && event.TeamID != teamID {
+			continue
+		}
+		recent = append(recent, event)
+	}
+	return recent, nil
+}
+
+func (s *StoreSink) load() ([]model.AuditEvent, error) {
+	data, ok, err := s.store.Get(storeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit events: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	var events []model.AuditEvent
+	if err := json.Unmarshal([]byte(data), &events); err != nil {
+		return nil, fmt.Errorf("failed to parse audit events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+)
+
+func TestRecentOnlyReturnsTheCallersTeam(t *testing.T) {
+	sinks := map[string]interface {
+		Sink
+		Reader
+	}{
+		"store": NewStoreSink(store.NewMemoryStore()),
+		"file":  NewFileSink(t.TempDir() + "/audit.log"),
+	}
+
+	for name, sink := range sinks {
+		t.Run(name, func(t *testing.T) {
+			for _, event := range []model.AuditEvent{
+				{TeamID: "T1", Action: "create"},
+				{TeamID: "T2", Action: "close"},
+				{TeamID: "T1", Action: "note"},
+			} {
+				if err := sink.Write(event); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+
+			events, err := sink.Recent("T1", 10)
+			if err != nil {
+				t.Fatalf("Recent() error = %v", err)
+			}
+			if len(events) != 2 || events[0].Action != "note" || events[1].Action != "create" {
+				t.Fatalf("Recent(T1) = %+v, want note then create", events)
+			}
+
+			events, err = sink.Recent("T2", 1)
+			if err != nil {
+				t.Fatalf("Recent() error = %v", err)
+			}
+			if len(events) != 1 || events[0].TeamID != "T2" {
+				t.Fatalf("Recent(T2) = %+v, want only T2's event", events)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/audit"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/handler"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+const workspaceKeyPrefix = "workspaces:"
+
+type Bot struct {
+	App   handler.App
+	OAuth *handler.OAuthHandler
+
+	live           *config.Live
+	workspaceStore *store.EncryptedStore
+	app            *handler.ReloadableApp
+	build          func(cfg *config.Config) (handler.App, error)
+	logger         *logrus.Logger
+}
+
+func New(live *config.Live, jobs *queue.Queue, logger *logrus.Logger) (*Bot, error) {
+	cfg := live.Get()
+	botStore, err := store.New(cfg.Store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	if !cfg.Store.Durable() {
+		logger.Warn("store.backend is memory, so event deduplication, alert announcements and other bot state are per process and lost on restart")
+	}
+	auditSink, err := audit.New(cfg.Audit, botStore, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit sink: %w", err)
+	}
+	bot := &Bot{live: live, logger: logger}
+
+	var workspaces *service.WorkspaceService
+	if cfg.OAuth.Enabled() {
+		workspaceStore, err := newWorkspaceStore(cfg.OAuth, botStore, logger)
+		if err != nil {
+			return nil, err
+		}
+		bot.workspaceStore = workspaceStore
+		workspaces = service.NewWorkspaceService(live.OAuth, workspaceStore, logger)
+		bot.OAuth = handler.NewOAuthHandler(live.OAuth, workspaces, logger)
+	}
+
+	bot.build = func(cfg *config.Config) (handler.App, error) {
+		if workspaces == nil {
+			app, err := NewIncidentApp(cfg, botStore, auditSink, jobs, logger)
+			if err != nil {
+				return nil, err
+			}
+			return app, nil
+		}
+
+		return handler.NewWorkspaceRouter(workspaces, func(workspace *model.Workspace) (*handler.IncidentApp, error) {
+			return NewIncidentApp(
+				cfg.ForWorkspace(*workspace),
+				store.NewPrefixStore(botStore, workspace.TeamID+":"),
+				auditSink,
+				jobs,
+				logger,
+			)
+		}, logger), nil
+	}
+
+	app, err := bot.build(cfg)
+	if err != nil {
+		return nil, err
+	}
+	bot.app = handler.NewReloadableApp(app)
+	bot.App = bot.app
+	jobs.SetRunner(bot.App.RunJob)
+
+	return bot, nil
+}
+
+func (b *Bot) WatchSecrets(ctx context.Context) {
+	cfg := b.live.Get()
+	if !cfg.HasSecretReferences() {
+		return
+	}
+
+	ticker := time.NewTicker(cfg.Secrets.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		refreshed, changed, err := b.live.Get().RefreshSecrets()
+		if err != nil {
+			b.logger.WithError(err).Warn("Failed to refresh secrets, keeping current credentials")
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		if err := b.rotateKeys(refreshed.OAuth); err != nil {
+			b.logger.WithError(err).Error("Failed to apply refreshed encryption keys, keeping current credentials")
+			continue
+		}
+
+		app, err := b.build(refreshed)
+		if err != nil {
+			b.logger.WithError(err).Error("Failed to rebuild app with refreshed secrets")
+			continue
+		}
+		b.live.Set(refreshed)
+		b.app.Swap(app)
+		b.logger.Info("Reloaded credentials from secret providers")
+	}
+}
+
+func (b *Bot) rotateKeys(oauthConfig config.OAuthConfig) error {
+	if b.workspaceStore == nil {
+		return nil
+	}
+	keys, err := oauthConfig.Keys()
+	if err != nil {
+		return err
+	}
+	return b.workspaceStore.Rotate(keys...)
+}
+
+func NewIncidentApp(cfg *config.Config, botStore store.Store, auditSink audit.Sink, jobs *queue.Queue, logger *logrus.Logger) (*handler.IncidentApp, error) {
+	slackService := service.NewSlackServiceWithLogger(cfg.SlackBotToken, logger)
+	alertService := service.NewAlertServiceWithLogger(
+		cfg.OpsGenieAPIKey,
+		cfg.OpsGenieTeamID,
+		cfg.OpsgenieDomain,
+		logger,
+	)
+	auditService := service.NewAuditService(auditSink, cfg.SlackTeamID, logger)
+	userDirectory := service.NewUserDirectory(slackService, alertService, botStore, logger)
+	incidentService := service.NewIncidentService(
+		cfg.Incidents,
+		alertService,
+		userDirectory,
+		auditService,
+		logger,
+	)
+	historyService := service.NewHistoryService(botStore)
+	announcementService := service.NewAnnouncementService(slackService, botStore, logger)
+	channelService := service.NewIncidentChannelService(
+		cfg.IncidentChannels,
+		slackService,
+		alertService,
+		announcementService,
+		logger,
+	)
+	permissionService := service.NewPermissionService(
+		cfg.Permissions,
+		slackService,
+		botStore,
+		auditService,
+		logger,
+	)
+	rateLimiter := service.NewRateLimiter(
+		cfg.RateLimits,
+		permissionService,
+		botStore,
+		auditService,
+		logger,
+	)
+	app := handler.NewIncidentApp(
+		slackService,
+		alertService,
+		incidentService,
+		historyService,
+		channelService,
+		announcementService,
+		permissionService,
+		rateLimiter,
+		auditService,
+		botStore,
+		jobs,
+		cfg,
+		logger,
+	)
+
+	reactionHandler := handler.NewReactionHandler(
+		cfg.Reactions,
+		slackService,
+		incidentService,
+		historyService,
+		channelService,
+		permissionService,
+		rateLimiter,
+		announcementService,
+		botStore,
+		logger,
+	)
+	reactionHandler.Register(app)
+
+	homeHandler := handler.NewHomeHandler(slackService, alertService, historyService, logger)
+	homeHandler.Register(app)
+
+	catalog := service.NewServiceCatalog(alertService, botStore, logger)
+	serviceOptionsHandler := handler.NewServiceOptionsHandler(catalog, logger)
+	serviceOptionsHandler.Register(app)
+
+	escalationHandler := handler.NewEscalationHandler(
+		slackService,
+		alertService,
+		catalog,
+		userDirectory,
+		permissionService,
+		announcementService,
+		auditService,
+		logger,
+	)
+	escalationHandler.Register(app)
+
+	ownershipHandler := handler.NewOwnershipHandler(
+		alertService,
+		userDirectory,
+		permissionService,
+		announcementService,
+		auditService,
+		logger,
+	)
+	ownershipHandler.Register(app)
+
+	alertStateHandler := handler.NewAlertStateHandler(
+		alertService,
+		userDirectory,
+		permissionService,
+		announcementService,
+		auditService,
+		logger,
+	)
+	alertStateHandler.Register(app)
+
+	maintenanceHandler := handler.NewMaintenanceHandler(
+		slackService,
+		service.NewMaintenanceService(alertService, slackService, botStore, logger),
+		catalog,
+		permissionService,
+		auditService,
+		logger,
+	)
+	maintenanceHandler.Register(app)
+
+	return app, nil
+}
+
+func newWorkspaceStore(oauthConfig config.OAuthConfig, botStore store.Store, logger *logrus.Logger) (*store.EncryptedStore, error) {
+	keys, err := oauthConfig.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var backing store.Store
+	if oauthConfig.StorePath != "" {
+		fileStore, err := store.NewFileStore(oauthConfig.StorePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open workspace store: %w", err)
+		}
+		backing = fileStore
+	} else {
+		backing = store.NewPrefixStore(botStore, workspaceKeyPrefix)
+	}
+
+	return store.NewEncryptedStore(backing, keys[0], keys[1:]...)
+}
@@ -0,0 +1,63 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/sirupsen/logrus"
+)
+
+func TestWatchSecretsUpdatesLiveConfig(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "signing-secret")
+	if err := os.WriteFile(secretFile, []byte("first\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("secrets:\n  refresh_interval: 10ms\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	t.Setenv("BOT_CONFIG_FILE", configFile)
+	t.Setenv("SLACK_SIGNING_SECRET", "file://"+secretFile)
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("OPSGENIE_API_KEY", "opsgenie-test")
+	t.Setenv("OPSGENIE_TEAM_ID", "team-1")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	live := config.NewLive(cfg)
+	bot, err := New(live, queue.NewWithBackend(queue.NewMemoryBackend(), 0, 1, logger), logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if live.SigningSecret() != "first" {
+		t.Fatalf("SigningSecret() = %q, want first", live.SigningSecret())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bot.WatchSecrets(ctx)
+
+	if err := os.WriteFile(secretFile, []byte("second\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for live.SigningSecret() != "second" {
+		if time.Now().After(deadline) {
+			t.Fatalf("SigningSecret() = %q after refresh, want second", live.SigningSecret())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import "fmt"
+
+const (
+	AuditSinkLog     = "log"
+	AuditSinkStore   = "store"
+	AuditSinkFile    = "file"
+	AuditSinkWebhook = "webhook"
+)
+
+type AuditConfig struct {
+	Sink        string   `yaml:"sink"`
+	Path        string   `yaml:"path"`
+	WebhookURL  string   `yaml:"webhook_url"`
+	AdminUsers  []string `yaml:"admin_users"`
+	AdminGroups []string `yaml:"admin_groups"`
+}
+
+func (a AuditConfig) validate(store StoreConfig) error {
+	switch a.Sink {
+	case AuditSinkLog:
+	case AuditSinkStore:
+		if !store.Durable() {
+			return fmt.Errorf("audit store sink needs store.backend file or redis")
+		}
+	case AuditSinkFile:
+		if a.Path == "" {
+			return fmt.Errorf("audit file sink needs a path")
+		}
+	case AuditSinkWebhook:
+		if a.WebhookURL == "" {
+			return fmt.Errorf("audit webhook sink needs a webhook_url")
+		}
+	default:
+		return fmt.Errorf("audit has invalid sink %q", a.Sink)
+	}
+	return nil
+}
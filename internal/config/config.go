@@ -1,22 +1,99 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/secrets"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+const defaultConfigFile = "config.yaml"
+
 type Config struct {
-	SlackSigningSecret string
-	SlackBotToken      string
-	OpsGenieAPIKey     string
-	OpsGenieTeamID     string
-	OpsgenieDomain     string
-	Port               string
+	SlackSigningSecret string                `yaml:"-"`
+	SlackBotToken      string                `yaml:"-"`
+	SlackAppToken      string                `yaml:"-"`
+	SlackTeamID        string                `yaml:"-"`
+	OpsGenieAPIKey     string                `yaml:"-"`
+	OpsGenieTeamID     string                `yaml:"-"`
+	OpsgenieDomain     string                `yaml:"-"`
+	Port               string                `yaml:"-"`
+	MetricsToken       string                `yaml:"-"`
+	Reactions          ReactionConfig        `yaml:"reactions"`
+	IncidentChannels   IncidentChannelConfig `yaml:"incident_channels"`
+	Incidents          IncidentConfig        `yaml:"incidents"`
+	Templates          []IncidentTemplate    `yaml:"templates"`
+	Validation         ValidationConfig      `yaml:"validation"`
+	Urgencies          []UrgencyLevel        `yaml:"urgencies"`
+	Permissions        PermissionConfig      `yaml:"permissions"`
+	Audit              AuditConfig           `yaml:"audit"`
+	RateLimits         RateLimitConfig       `yaml:"rate_limits"`
+	Queue              QueueConfig           `yaml:"queue"`
+	Store              StoreConfig           `yaml:"store"`
+	OAuth              OAuthConfig           `yaml:"oauth"`
+	Secrets            SecretsConfig         `yaml:"secrets"`
+
+	resolver   *secrets.Resolver
+	secretRefs map[string]secrets.Reference
+}
+
+type ReactionConfig struct {
+	Emoji    string            `yaml:"emoji"`
+	Channels []ReactionChannel `yaml:"channels"`
+}
+
+type ReactionChannel struct {
+	ID       string `yaml:"id"`
+	TeamID   string `yaml:"team_id"`
+	Priority string `yaml:"priority"`
+}
+
+type IncidentChannelConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Priorities   []string `yaml:"priorities"`
+	Prefix       string   `yaml:"prefix"`
+	Private      bool     `yaml:"private"`
+	Stakeholders []string `yaml:"stakeholders"`
+}
+
+type IncidentConfig struct {
+	Priorities         []string `yaml:"priorities"`
+	Sources            []string `yaml:"sources"`
+	ImpactedServices   []string `yaml:"impacted_services"`
+	NotifyStakeholders bool     `yaml:"notify_stakeholders"`
+	StatusPageEntry    bool     `yaml:"status_page_entry"`
+}
+
+func (c IncidentConfig) UsesIncidentAPI(priority, source string) bool {
+	return contains(c.Priorities, priority) || contains(c.Sources, source)
+}
+
+func (c IncidentChannelConfig) AppliesTo(priority string) bool {
+	return c.Enabled && contains(c.Priorities, priority)
+}
+
+func (r ReactionConfig) Enabled() bool {
+	return r.Emoji != "" && len(r.Channels) > 0
+}
+
+func (r ReactionConfig) Channel(channelID string) (ReactionChannel, bool) {
+	for _, channel := range r.Channels {
+		if channel.ID == channelID {
+			return channel, true
+		}
+	}
+	return ReactionChannel{}, false
 }
 
 func Load() (*Config, error) {
+	return LoadWithResolver(secrets.NewResolver())
+}
+
+func LoadWithResolver(resolver *secrets.Resolver) (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		fmt.Printf("Warning: .env file not found, using environment variables\n")
 	}
@@ -24,19 +101,33 @@ func Load() (*Config, error) {
 	config := &Config{
 		SlackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
 		SlackBotToken:      os.Getenv("SLACK_BOT_TOKEN"),
+		SlackAppToken:      os.Getenv("SLACK_APP_TOKEN"),
 		OpsGenieAPIKey:     os.Getenv("OPSGENIE_API_KEY"),
 		OpsGenieTeamID:     os.Getenv("OPSGENIE_TEAM_ID"),
 		OpsgenieDomain:     os.Getenv("OPSGENIE_DOMAIN"),
 		Port:               os.Getenv("PORT"),
+		MetricsToken:       os.Getenv("METRICS_TOKEN"),
+		Store: StoreConfig{
+			RedisURL: os.Getenv("REDIS_URL"),
+		},
+		OAuth: OAuthConfig{
+			ClientID:      os.Getenv("SLACK_CLIENT_ID"),
+			ClientSecret:  os.Getenv("SLACK_CLIENT_SECRET"),
+			EncryptionKey: os.Getenv("SLACK_ENCRYPTION_KEY"),
+			PreviousKeys:  os.Getenv("SLACK_PREVIOUS_ENCRYPTION_KEYS"),
+		},
 	}
 
-	if config.OpsgenieDomain == "" {
-		config.OpsgenieDomain = "app"
+	if err := config.loadFile(os.Getenv("BOT_CONFIG_FILE")); err != nil {
+		return nil, err
 	}
-	if config.Port == "" {
-		config.Port = "8080"
+
+	if err := config.resolveSecrets(resolver); err != nil {
+		return nil, err
 	}
 
+	config.applyDefaults()
+
 	if err := config.validate(); err != nil {
 		return nil, err
 	}
@@ -44,6 +135,72 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
+func (c *Config) applyDefaults() {
+	if c.OpsgenieDomain == "" {
+		c.OpsgenieDomain = "app"
+	}
+	if c.Port == "" {
+		c.Port = "8080"
+	}
+	c.Reactions.Emoji = strings.Trim(c.Reactions.Emoji, ":")
+	if len(c.IncidentChannels.Priorities) == 0 {
+		c.IncidentChannels.Priorities = []string{"P1", "P2"}
+	}
+	if c.IncidentChannels.Prefix == "" {
+		c.IncidentChannels.Prefix = "inc"
+	}
+	if c.Audit.Sink == "" {
+		c.Audit.Sink = AuditSinkLog
+		if c.Store.Durable() {
+			c.Audit.Sink = AuditSinkStore
+		}
+	}
+	if len(c.Urgencies) == 0 {
+		c.Urgencies = DefaultUrgencies()
+	}
+	if c.Queue.Workers == 0 {
+		c.Queue.Workers = defaultQueueWorkers
+	}
+	if c.Store.Backend == "" {
+		c.Store.Backend = StoreBackendMemory
+	}
+	if c.Store.KeyPrefix == "" {
+		c.Store.KeyPrefix = defaultStoreKeyPrefix
+	}
+	if c.Queue.Backend == "" {
+		c.Queue.Backend = QueueBackendMemory
+	}
+	if len(c.OAuth.Scopes) == 0 {
+		c.OAuth.Scopes = DefaultBotScopes()
+	}
+	if c.Secrets.RefreshInterval == 0 {
+		c.Secrets.RefreshInterval = defaultSecretsRefreshInterval
+	}
+	if c.Queue.MaxAttempts == 0 {
+		c.Queue.MaxAttempts = defaultQueueMaxAttempts
+	}
+}
+
+func (c *Config) loadFile(path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
 func (c *Config) validate() error {
 	required := map[string]string{
 		"SLACK_SIGNING_SECRET": c.SlackSigningSecret,
@@ -52,6 +209,14 @@ func (c *Config) validate() error {
 		"OPSGENIE_TEAM_ID":     c.OpsGenieTeamID,
 	}
 
+	if c.OAuth.Enabled() {
+		delete(required, "SLACK_BOT_TOKEN")
+		if len(c.OAuth.SharedTeams) == 0 {
+			delete(required, "OPSGENIE_API_KEY")
+			delete(required, "OPSGENIE_TEAM_ID")
+		}
+	}
+
 	var missingVars []string
 	for name, value := range required {
 		if value == "" {
@@ -63,5 +228,79 @@ func (c *Config) validate() error {
 		return fmt.Errorf("missing required environment variables: %v", missingVars)
 	}
 
-	return nil
+	for _, source := range c.Incidents.Sources {
+		switch source {
+		case "command", "shortcut", "reaction", "home", "button":
+		default:
+			return fmt.Errorf("incidents has invalid source %q", source)
+		}
+	}
+
+	for _, priority := range c.Incidents.Priorities {
+		if !validPriority(priority) {
+			return fmt.Errorf("incidents has invalid priority %q", priority)
+		}
+	}
+
+	for _, priority := range c.IncidentChannels.Priorities {
+		if !validPriority(priority) {
+			return fmt.Errorf("incident_channels has invalid priority %q", priority)
+		}
+	}
+
+	for _, channel := range c.Reactions.Channels {
+		if channel.ID == "" {
+			return fmt.Errorf("reaction channel is missing an id")
+		}
+		if channel.Priority != "" && !validPriority(channel.Priority) {
+			return fmt.Errorf("reaction channel %s has invalid priority %q", channel.ID, channel.Priority)
+		}
+	}
+
+	if err := c.Validation.compile(); err != nil {
+		return err
+	}
+
+	if err := c.validateUrgencies(); err != nil {
+		return err
+	}
+
+	if err := c.Permissions.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Audit.validate(c.Store); err != nil {
+		return err
+	}
+
+	if err := c.RateLimits.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Queue.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Store.validate(); err != nil {
+		return err
+	}
+
+	if err := c.OAuth.validate(); err != nil {
+		return err
+	}
+
+	if c.Secrets.RefreshInterval < 0 {
+		return fmt.Errorf("secrets refresh_interval must be positive")
+	}
+
+	return c.validateTemplates()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+)
+
+func validConfig() *Config {
+	cfg := &Config{
+		SlackSigningSecret: "secret",
+		SlackBotToken:      "xoxb-test",
+		OpsGenieAPIKey:     "key",
+		OpsGenieTeamID:     "team",
+	}
+	cfg.applyDefaults()
+	return cfg
+}
+
+func TestValidateIncidentSources(t *testing.T) {
+	for _, source := range []string{"command", "shortcut", "reaction", "home", "button"} {
+		cfg := validConfig()
+		cfg.Incidents.Sources = []string{source}
+		if err := cfg.validate(); err != nil {
+			t.Errorf("source %q: validate() error = %v", source, err)
+		}
+	}
+
+	cfg := validConfig()
+	cfg.Incidents.Sources = []string{"email"}
+	if err := cfg.validate(); err == nil {
+		t.Error("source \"email\": validate() error = nil")
+	}
+}
+
+func TestValidateIncidentPriorities(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr bool
+	}{
+		{"valid incident priorities", func(c *Config) { c.Incidents.Priorities = []string{"P1", "P2"} }, false},
+		{"invalid incident priority", func(c *Config) { c.Incidents.Priorities = []string{"p1"} }, true},
+		{"valid channel priorities", func(c *Config) { c.IncidentChannels.Priorities = []string{"P1"} }, false},
+		{"invalid channel priority", func(c *Config) { c.IncidentChannels.Priorities = []string{"critical"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+			if err := cfg.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRequiresGlobalOpsGenieCredentialsOnlyWhenUsed(t *testing.T) {
+	tests := []struct {
+		name        string
+		oauth       bool
+		sharedTeams []string
+		wantErr     bool
+	}{
+		{"single workspace", false, nil, true},
+		{"workspaces bring their own credentials", true, nil, false},
+		{"workspaces share the global credentials", true, []string{"T_SHARED"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.OpsGenieAPIKey = ""
+			cfg.OpsGenieTeamID = ""
+			if tt.oauth {
+				cfg.SlackBotToken = ""
+				cfg.OAuth.ClientID = "client"
+				cfg.OAuth.ClientSecret = "client-secret"
+				cfg.OAuth.EncryptionKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+				cfg.OAuth.RedirectURL = "https://bot.example.com/slack/oauth/callback"
+				cfg.OAuth.SharedTeams = tt.sharedTeams
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "OPSGENIE_API_KEY") {
+				t.Fatalf("validate() error = %v, want the missing OpsGenie key named", err)
+			}
+		})
+	}
+}
+
+func TestForWorkspaceKeepsGlobalOpsGenieCredentialsForSharedTeamsOnly(t *testing.T) {
+	cfg := validConfig()
+	cfg.OAuth.SharedTeams = []string{"T_SHARED"}
+
+	tests := []struct {
+		name      string
+		workspace model.Workspace
+		wantKey   string
+		wantTeam  string
+	}{
+		{"connected", model.Workspace{TeamID: "T1", OpsGenieAPIKey: "own-key", OpsGenieTeamID: "own-team"}, "own-key", "own-team"},
+		{"shared", model.Workspace{TeamID: "T_SHARED"}, "key", "team"},
+		{"not connected", model.Workspace{TeamID: "T2"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoped := cfg.ForWorkspace(tt.workspace)
+			if scoped.OpsGenieAPIKey != tt.wantKey || scoped.OpsGenieTeamID != tt.wantTeam {
+				t.Fatalf("ForWorkspace() OpsGenie = %q/%q, want %q/%q",
+					scoped.OpsGenieAPIKey, scoped.OpsGenieTeamID, tt.wantKey, tt.wantTeam)
+			}
+		})
+	}
+}
+
+func TestAuditSinkDefaultsToDurableDestination(t *testing.T) {
+	cfg := validConfig()
+	if cfg.Audit.Sink != AuditSinkLog {
+		t.Fatalf("default sink with the memory store = %q, want %q", cfg.Audit.Sink, AuditSinkLog)
+	}
+
+	cfg = &Config{Store: StoreConfig{Backend: StoreBackendRedis}}
+	cfg.applyDefaults()
+	if cfg.Audit.Sink != AuditSinkStore {
+		t.Fatalf("default sink with the redis store = %q, want %q", cfg.Audit.Sink, AuditSinkStore)
+	}
+
+	cfg = validConfig()
+	cfg.Audit.Sink = AuditSinkStore
+	if err := cfg.validate(); err == nil {
+		t.Fatal("validate() accepted the store sink on the memory store")
+	}
+}
+
+func TestOAuthKeysIncludePreviousKeys(t *testing.T) {
+	current := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	previous := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+
+	keys, err := OAuthConfig{EncryptionKey: current, PreviousKeys: " " + previous + ", "}.Keys()
+	if err != nil {
+		t.Fatalf("Keys() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0][0] != 1 || keys[1][0] != 2 {
+		t.Fatalf("Keys() = %v, want the current key then the previous one", keys)
+	}
+
+	if _, err := (OAuthConfig{EncryptionKey: current, PreviousKeys: "c2hvcnQ="}).Keys(); err == nil {
+		t.Fatal("Keys() accepted a short previous key")
+	}
+}
+
+func TestValidatePermissionActions(t *testing.T) {
+	for _, action := range []string{"create", "ack", "close", "escalate", "assign", "maintenance"} {
+		cfg := validConfig()
+		cfg.Permissions.Rules = []PermissionRule{{Effect: EffectDeny, Actions: []string{action}}}
+		if err := cfg.validate(); err != nil {
+			t.Errorf("action %q: validate() error = %v", action, err)
+		}
+	}
+
+	cfg := validConfig()
+	cfg.Permissions.Rules = []PermissionRule{{Effect: EffectDeny, Actions: []string{"note"}}}
+	if err := cfg.validate(); err == nil {
+		t.Error("action \"note\": validate() error = nil")
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	options := []FieldOption{{Label: "Yes", Value: "yes"}}
+	field := func(mutate func(*TemplateField)) []TemplateField {
+		f := TemplateField{ID: "region", Label: "Region", Type: FieldTypeText}
+		mutate(&f)
+		return []TemplateField{f}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*IncidentTemplate)
+		wantErr string
+	}{
+		{"valid", func(tpl *IncidentTemplate) {}, ""},
+		{"missing name", func(tpl *IncidentTemplate) { tpl.Name = "" }, "missing an id or name"},
+		{"unknown urgency", func(tpl *IncidentTemplate) { tpl.Urgency = "sev0" }, "unknown urgency"},
+		{"known urgency", func(tpl *IncidentTemplate) { tpl.Urgency = "critical" }, ""},
+		{"field without label", func(tpl *IncidentTemplate) { tpl.Fields = field(func(f *TemplateField) { f.Label = "" }) }, "without id or label"},
+		{"duplicate field", func(tpl *IncidentTemplate) {
+			tpl.Fields = append(field(func(*TemplateField) {}), field(func(*TemplateField) {})...)
+		}, "duplicate field"},
+		{"invalid type", func(tpl *IncidentTemplate) { tpl.Fields = field(func(f *TemplateField) { f.Type = "number" }) }, "invalid type"},
+		{"select without options", func(tpl *IncidentTemplate) { tpl.Fields = field(func(f *TemplateField) { f.Type = FieldTypeSelect }) }, "needs options"},
+		{"checkbox with options", func(tpl *IncidentTemplate) {
+			tpl.Fields = field(func(f *TemplateField) { f.Type = FieldTypeCheckbox; f.Options = options })
+		}, ""},
+		{"invalid maps_to", func(tpl *IncidentTemplate) { tpl.Fields = field(func(f *TemplateField) { f.MapsTo = "title" }) }, "invalid maps_to"},
+		{"priority mapping with valid values", func(tpl *IncidentTemplate) {
+			tpl.Fields = field(func(f *TemplateField) {
+				f.Type = FieldTypeSelect
+				f.MapsTo = MapToPriority
+				f.Options = []FieldOption{{Label: "Sev 1", Value: "P1"}, {Label: "Sev 2", Value: "P2"}}
+			})
+		}, ""},
+		{"priority mapping with invalid value", func(tpl *IncidentTemplate) {
+			tpl.Fields = field(func(f *TemplateField) { f.Type = FieldTypeSelect; f.MapsTo = MapToPriority; f.Options = options })
+		}, "maps to priority"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tpl := IncidentTemplate{ID: "db-outage", Name: "Database outage"}
+			tt.mutate(&tpl)
+			cfg.Templates = []IncidentTemplate{tpl}
+
+			err := cfg.validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := validConfig()
+	cfg.Templates = []IncidentTemplate{{ID: "a", Name: "A"}, {ID: "a", Name: "B"}}
+	if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), "duplicate template id") {
+		t.Fatalf("validate() error = %v, want a duplicate template id error", err)
+	}
+}
+
+func TestValidateUrgencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		levels  []UrgencyLevel
+		wantErr string
+	}{
+		{"defaults", nil, ""},
+		{"custom levels with P5", []UrgencyLevel{{Value: "sev1", Label: "Sev 1", Priority: "P1"}, {Value: "fyi", Label: "FYI", Priority: "P5"}}, ""},
+		{"team override", []UrgencyLevel{{Value: "sev1", Label: "Sev 1", Priority: "P1", Teams: map[string]string{"team-web": "P2"}}}, ""},
+		{"missing label", []UrgencyLevel{{Value: "sev1", Priority: "P1"}}, "missing a value or label"},
+		{"duplicate value", []UrgencyLevel{{Value: "sev1", Label: "A", Priority: "P1"}, {Value: "sev1", Label: "B", Priority: "P2"}}, "duplicate urgency"},
+		{"invalid priority", []UrgencyLevel{{Value: "sev1", Label: "Sev 1", Priority: "P6"}}, "invalid priority \"P6\""},
+		{"invalid team priority", []UrgencyLevel{{Value: "sev1", Label: "Sev 1", Priority: "P1", Teams: map[string]string{"team-web": "high"}}}, "for team team-web"},
+		{"two defaults", []UrgencyLevel{{Value: "a", Label: "A", Priority: "P1", Default: true}, {Value: "b", Label: "B", Priority: "P2", Default: true}}, "only one urgency"},
+		{"too many levels", make([]UrgencyLevel, maxUrgencyLevels+1), "at most 100 urgencies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			if tt.levels != nil {
+				cfg.Urgencies = tt.levels
+			}
+
+			err := cfg.validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUrgencyPriorityFor(t *testing.T) {
+	level := UrgencyLevel{Value: "sev1", Priority: "P1", Teams: map[string]string{"team-web": "P2"}}
+
+	tests := []struct {
+		teamID string
+		want   string
+	}{
+		{"team-web", "P2"},
+		{"team-dba", "P1"},
+		{"", "P1"},
+	}
+	for _, tt := range tests {
+		if got := level.PriorityFor(tt.teamID); got != tt.want {
+			t.Errorf("PriorityFor(%q) = %q, want %q", tt.teamID, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultUrgency(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []UrgencyLevel
+		want   string
+	}{
+		{"built-in levels", DefaultUrgencies(), "medium"},
+		{"first level without a default", []UrgencyLevel{{Value: "sev1"}, {Value: "sev2"}}, "sev1"},
+		{"marked default", []UrgencyLevel{{Value: "sev1"}, {Value: "sev2", Default: true}}, "sev2"},
+		{"no levels", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultUrgency(tt.levels); got != tt.want {
+				t.Fatalf("DefaultUrgency() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import "sync"
+
+type Live struct {
+	mu  sync.RWMutex
+	cfg *Config
+}
+
+func NewLive(cfg *Config) *Live {
+	return &Live{cfg: cfg}
+}
+
+func (l *Live) Get() *Config {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.cfg
+}
+
+func (l *Live) Set(cfg *Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = cfg
+}
+
+func (l *Live) SigningSecret() string {
+	return l.Get().SlackSigningSecret
+}
+
+func (l *Live) SocketTokens() (string, string) {
+	cfg := l.Get()
+	return cfg.SlackBotToken, cfg.SlackAppToken
+}
+
+func (l *Live) MetricsToken() string {
+	return l.Get().MetricsToken
+}
+
+func (l *Live) OAuth() OAuthConfig {
+	return l.Get().OAuth
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+)
+
+type OAuthConfig struct {
+	ClientID      string   `yaml:"-"`
+	ClientSecret  string   `yaml:"-"`
+	EncryptionKey string   `yaml:"-"`
+	PreviousKeys  string   `yaml:"-"`
+	RedirectURL   string   `yaml:"redirect_url"`
+	SuccessURL    string   `yaml:"success_url"`
+	Scopes        []string `yaml:"scopes"`
+	StorePath     string   `yaml:"store_path"`
+	SharedTeams   []string `yaml:"shared_opsgenie_teams"`
+}
+
+func DefaultBotScopes() []string {
+	return []string{
+		"app_mentions:read",
+		"channels:history",
+		"channels:manage",
+		"chat:write",
+		"commands",
+		"groups:history",
+		"groups:write",
+		"im:history",
+		"im:write",
+		"pins:write",
+		"reactions:read",
+		"usergroups:read",
+		"users:read",
+		"users:read.email",
+	}
+}
+
+func (o OAuthConfig) Enabled() bool {
+	return o.ClientID != ""
+}
+
+func (o OAuthConfig) Keys() ([][]byte, error) {
+	key, err := decodeKey("SLACK_ENCRYPTION_KEY", o.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := [][]byte{key}
+	for _, encoded := range strings.Split(o.PreviousKeys, ",") {
+		if encoded = strings.TrimSpace(encoded); encoded == "" {
+			continue
+		}
+		previous, err := decodeKey("SLACK_PREVIOUS_ENCRYPTION_KEYS", encoded)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, previous)
+	}
+	return keys, nil
+}
+
+func decodeKey(name, encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be base64: %w", name, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("%s must decode to 32 bytes, got %d", name, len(key))
+	}
+	return key, nil
+}
+
+func (o OAuthConfig) SharesOpsGenie(teamID string) bool {
+	for _, shared := range o.SharedTeams {
+		if shared == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+func (o OAuthConfig) validate() error {
+	if !o.Enabled() {
+		return nil
+	}
+	if o.ClientSecret == "" {
+		return fmt.Errorf("missing required environment variables: [SLACK_CLIENT_SECRET]")
+	}
+	if o.EncryptionKey == "" {
+		return fmt.Errorf("missing required environment variables: [SLACK_ENCRYPTION_KEY]")
+	}
+	if _, err := o.Keys(); err != nil {
+		return err
+	}
+	if o.RedirectURL == "" {
+		return fmt.Errorf("oauth needs a redirect_url")
+	}
+	return nil
+}
+
+func (c *Config) ForWorkspace(workspace model.Workspace) *Config {
+	scoped := *c
+	scoped.SlackTeamID = workspace.TeamID
+	scoped.SlackBotToken = workspace.BotToken
+	switch {
+	case workspace.OpsGenieAPIKey != "":
+		scoped.OpsGenieAPIKey = workspace.OpsGenieAPIKey
+		scoped.OpsGenieTeamID = workspace.OpsGenieTeamID
+	case !c.OAuth.SharesOpsGenie(workspace.TeamID):
+		scoped.OpsGenieAPIKey = ""
+		scoped.OpsGenieTeamID = ""
+	}
+	if workspace.OpsGenieDomain != "" {
+		scoped.OpsgenieDomain = workspace.OpsGenieDomain
+	}
+	return &scoped
+}
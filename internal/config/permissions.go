@@ -0,0 +1,64 @@
+package config
+
+import "fmt"
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+type PermissionConfig struct {
+	Default       string           `yaml:"default"`
+	DeniedMessage string           `yaml:"denied_message"`
+	Rules         []PermissionRule `yaml:"rules"`
+}
+
+type PermissionRule struct {
+	Effect     string   `yaml:"effect"`
+	Actions    []string `yaml:"actions"`
+	Priorities []string `yaml:"priorities"`
+	Users      []string `yaml:"users"`
+	Groups     []string `yaml:"groups"`
+	Channels   []string `yaml:"channels"`
+}
+
+func (r PermissionRule) AppliesTo(action, priority string) bool {
+	if len(r.Actions) > 0 && !contains(r.Actions, action) {
+		return false
+	}
+	if priority != "" && len(r.Priorities) > 0 && !contains(r.Priorities, priority) {
+		return false
+	}
+	return true
+}
+
+func (r PermissionRule) Everyone() bool {
+	return len(r.Users) == 0 && len(r.Groups) == 0 && len(r.Channels) == 0
+}
+
+func (p PermissionConfig) validate() error {
+	switch p.Default {
+	case "", EffectAllow, EffectDeny:
+	default:
+		return fmt.Errorf("permissions has invalid default %q", p.Default)
+	}
+
+	for i, rule := range p.Rules {
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return fmt.Errorf("permission rule %d has invalid effect %q", i+1, rule.Effect)
+		}
+		for _, action := range rule.Actions {
+			switch action {
+			case "create", "ack", "close", "escalate", "assign", "maintenance":
+			default:
+				return fmt.Errorf("permission rule %d has invalid action %q", i+1, action)
+			}
+		}
+		for _, priority := range rule.Priorities {
+			if !validPriority(priority) {
+				return fmt.Errorf("permission rule %d has invalid priority %q", i+1, priority)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import "fmt"
+
+const maxUrgencyLevels = 100
+
+type UrgencyLevel struct {
+	Value       string            `yaml:"value"`
+	Label       string            `yaml:"label"`
+	Emoji       string            `yaml:"emoji"`
+	Description string            `yaml:"description"`
+	Priority    string            `yaml:"priority"`
+	Default     bool              `yaml:"default"`
+	Teams       map[string]string `yaml:"teams"`
+}
+
+func DefaultUrgencies() []UrgencyLevel {
+	return []UrgencyLevel{
+		{Value: "critical", Label: "Critical", Priority: "P1"},
+		{Value: "high", Label: "High", Priority: "P2"},
+		{Value: "medium", Label: "Medium", Priority: "P3", Default: true},
+		{Value: "low", Label: "Low", Priority: "P4"},
+	}
+}
+
+func DefaultUrgency(levels []UrgencyLevel) string {
+	for _, level := range levels {
+		if level.Default {
+			return level.Value
+		}
+	}
+	if len(levels) > 0 {
+		return levels[0].Value
+	}
+	return ""
+}
+
+func (c *Config) Urgency(value string) (UrgencyLevel, bool) {
+	for _, level := range c.Urgencies {
+		if level.Value == value {
+			return level, true
+		}
+	}
+	return UrgencyLevel{}, false
+}
+
+func (u UrgencyLevel) PriorityFor(teamID string) string {
+	if priority, ok := u.Teams[teamID]; ok {
+		return priority
+	}
+	return u.Priority
+}
+
+func (c *Config) validateUrgencies() error {
+	if len(c.Urgencies) > maxUrgencyLevels {
+		return fmt.Errorf("at most %d urgencies can be configured", maxUrgencyLevels)
+	}
+
+	seen := make(map[string]bool)
+	defaults := 0
+	for _, level := range c.Urgencies {
+		if level.Value == "" || level.Label == "" {
+			return fmt.Errorf("urgency is missing a value or label")
+		}
+		if seen[level.Value] {
+			return fmt.Errorf("duplicate urgency %q", level.Value)
+		}
+		seen[level.Value] = true
+
+		if !validPriority(level.Priority) {
+			return fmt.Errorf("urgency %s has invalid priority %q", level.Value, level.Priority)
+		}
+		for teamID, priority := range level.Teams {
+			if !validPriority(priority) {
+				return fmt.Errorf("urgency %s has invalid priority %q for team %s", level.Value, priority, teamID)
+			}
+		}
+		if level.Default {
+			defaults++
+		}
+	}
+
+	if defaults > 1 {
+		return fmt.Errorf("only one urgency can be the default")
+	}
+	return nil
+}
+
+func validPriority(priority string) bool {
+	switch priority {
+	case "P1", "P2", "P3", "P4", "P5":
+		return true
+	}
+	return false
+}
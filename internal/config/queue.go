@@ -0,0 +1,48 @@
+package config
+
+import "fmt"
+
+const (
+	QueueBackendMemory     = "memory"
+	QueueBackendFile       = "file"
+	QueueBackendCloudTasks = "cloudtasks"
+
+	defaultQueueWorkers     = 4
+	defaultQueueMaxAttempts = 3
+)
+
+type QueueConfig struct {
+	Workers     int              `yaml:"workers"`
+	Backend     string           `yaml:"backend"`
+	Path        string           `yaml:"path"`
+	MaxAttempts int              `yaml:"max_attempts"`
+	CloudTasks  CloudTasksConfig `yaml:"cloud_tasks"`
+}
+
+type CloudTasksConfig struct {
+	Queue string `yaml:"queue"`
+	URL   string `yaml:"url"`
+}
+
+func (q QueueConfig) validate() error {
+	if q.Workers < 1 {
+		return fmt.Errorf("queue needs at least one worker")
+	}
+	if q.MaxAttempts < 1 {
+		return fmt.Errorf("queue max_attempts must be at least 1")
+	}
+	switch q.Backend {
+	case QueueBackendMemory:
+	case QueueBackendFile:
+		if q.Path == "" {
+			return fmt.Errorf("queue file backend needs a path")
+		}
+	case QueueBackendCloudTasks:
+		if q.CloudTasks.Queue == "" || q.CloudTasks.URL == "" {
+			return fmt.Errorf("queue cloudtasks backend needs cloud_tasks.queue and cloud_tasks.url")
+		}
+	default:
+		return fmt.Errorf("queue has invalid backend %q", q.Backend)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	RateLimitScopeUser    = "user"
+	RateLimitScopeChannel = "channel"
+)
+
+type RateLimitConfig struct {
+	Rules          []RateLimitRule `yaml:"rules"`
+	OverrideUsers  []string        `yaml:"override_users"`
+	OverrideGroups []string        `yaml:"override_groups"`
+}
+
+type RateLimitRule struct {
+	Scope      string        `yaml:"scope"`
+	Priorities []string      `yaml:"priorities"`
+	Max        int           `yaml:"max"`
+	Window     time.Duration `yaml:"window"`
+}
+
+func (r RateLimitRule) AppliesTo(priority string) bool {
+	return len(r.Priorities) == 0 || contains(r.Priorities, priority)
+}
+
+func (r RateLimitConfig) validate() error {
+	for i, rule := range r.Rules {
+		if rule.Scope != RateLimitScopeUser && rule.Scope != RateLimitScopeChannel {
+			return fmt.Errorf("rate limit rule %d has invalid scope %q", i+1, rule.Scope)
+		}
+		if rule.Max <= 0 {
+			return fmt.Errorf("rate limit rule %d needs a positive max", i+1)
+		}
+		if rule.Window < time.Minute {
+			return fmt.Errorf("rate limit rule %d needs a window of at least one minute", i+1)
+		}
+		for _, priority := range rule.Priorities {
+			if !validPriority(priority) {
+				return fmt.Errorf("rate limit rule %d has invalid priority %q", i+1, priority)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/secrets"
+)
+
+const (
+	defaultSecretsRefreshInterval = 15 * time.Minute
+	secretsResolveTimeout         = 30 * time.Second
+)
+
+type SecretsConfig struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"SLACK_SIGNING_SECRET":           &c.SlackSigningSecret,
+		"SLACK_BOT_TOKEN":                &c.SlackBotToken,
+		"SLACK_APP_TOKEN":                &c.SlackAppToken,
+		"SLACK_CLIENT_SECRET":            &c.OAuth.ClientSecret,
+		"SLACK_ENCRYPTION_KEY":           &c.OAuth.EncryptionKey,
+		"SLACK_PREVIOUS_ENCRYPTION_KEYS": &c.OAuth.PreviousKeys,
+		"OPSGENIE_API_KEY":               &c.OpsGenieAPIKey,
+		"OPSGENIE_TEAM_ID":               &c.OpsGenieTeamID,
+		"METRICS_TOKEN":                  &c.MetricsToken,
+	}
+}
+
+func (c *Config) resolveSecrets(resolver *secrets.Resolver) error {
+	c.resolver = resolver
+	c.secretRefs = make(map[string]secrets.Reference)
+
+	for name, field := range c.secretFields() {
+		if ref, ok := resolver.Parse(*field); ok {
+			c.secretRefs[name] = ref
+		}
+	}
+
+	_, err := c.applySecrets()
+	return err
+}
+
+func (c *Config) HasSecretReferences() bool {
+	return len(c.secretRefs) > 0
+}
+
+func (c *Config) RefreshSecrets() (*Config, bool, error) {
+	refreshed := *c
+	changed, err := refreshed.applySecrets()
+	if err != nil {
+		return nil, false, err
+	}
+	return &refreshed, changed, nil
+}
+
+func (c *Config) applySecrets() (bool, error) {
+	if len(c.secretRefs) == 0 {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretsResolveTimeout)
+	defer cancel()
+
+	changed := false
+	fields := c.secretFields()
+	for name, ref := range c.secretRefs {
+		value, err := c.resolver.Resolve(ctx, ref)
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve %s: %w", name, err)
+		}
+		if *fields[name] != value {
+			*fields[name] = value
+			changed = true
+		}
+	}
+	return changed, nil
+}
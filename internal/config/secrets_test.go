@@ -0,0 +1,118 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/secrets"
+)
+
+func setRequiredEnv(t *testing.T, values map[string]string) {
+	t.Helper()
+
+	for _, name := range []string{"SLACK_SIGNING_SECRET", "SLACK_BOT_TOKEN", "OPSGENIE_API_KEY", "OPSGENIE_TEAM_ID", "BOT_CONFIG_FILE", "SLACK_CLIENT_ID"} {
+		t.Setenv(name, values[name])
+	}
+}
+
+func TestLoadResolvesSecretsThroughInjectedProviders(t *testing.T) {
+	setRequiredEnv(t, map[string]string{
+		"SLACK_SIGNING_SECRET": "signing-secret",
+		"SLACK_BOT_TOKEN":      "vault://secret/data/slack#bot_token",
+		"OPSGENIE_API_KEY":     "vault://secret/data/opsgenie#api_key",
+		"OPSGENIE_TEAM_ID":     "team",
+	})
+	t.Setenv("SECRETS_FAKE_FILE", "/does/not/matter.json")
+
+	resolver := secrets.NewResolverWithProviders(map[string]secrets.Provider{
+		secrets.SchemeVault: secrets.Static{
+			"secret/data/slack":    `{"bot_token":"xoxb-injected"}`,
+			"secret/data/opsgenie": `{"api_key":"injected-key"}`,
+		},
+	})
+
+	cfg, err := LoadWithResolver(resolver)
+	if err != nil {
+		t.Fatalf("LoadWithResolver() error = %v", err)
+	}
+	if cfg.SlackBotToken != "xoxb-injected" || cfg.OpsGenieAPIKey != "injected-key" {
+		t.Fatalf("resolved %q/%q, want the injected secrets", cfg.SlackBotToken, cfg.OpsGenieAPIKey)
+	}
+	if cfg.SlackSigningSecret != "signing-secret" {
+		t.Fatalf("plain value changed to %q", cfg.SlackSigningSecret)
+	}
+}
+
+func TestRefreshSecretsDetectsChanges(t *testing.T) {
+	setRequiredEnv(t, map[string]string{
+		"SLACK_SIGNING_SECRET": "signing-secret",
+		"SLACK_BOT_TOKEN":      "vault://secret/data/slack#bot_token",
+		"OPSGENIE_API_KEY":     "vault://secret/data/opsgenie#api_key",
+		"OPSGENIE_TEAM_ID":     "team",
+	})
+
+	values := secrets.Static{
+		"secret/data/slack":    `{"bot_token":"xoxb-first"}`,
+		"secret/data/opsgenie": `{"api_key":"key-first"}`,
+	}
+	cfg, err := LoadWithResolver(secrets.NewResolverWithProviders(map[string]secrets.Provider{secrets.SchemeVault: values}))
+	if err != nil {
+		t.Fatalf("LoadWithResolver() error = %v", err)
+	}
+	if !cfg.HasSecretReferences() {
+		t.Fatal("HasSecretReferences() = false, want true")
+	}
+
+	refreshed, changed, err := cfg.RefreshSecrets()
+	if err != nil {
+		t.Fatalf("RefreshSecrets() error = %v", err)
+	}
+	if changed {
+		t.Fatal("RefreshSecrets() reported a change for unchanged secrets")
+	}
+	if refreshed.SlackBotToken != "xoxb-first" {
+		t.Fatalf("SlackBotToken = %q, want xoxb-first", refreshed.SlackBotToken)
+	}
+
+	values["secret/data/opsgenie"] = `{"api_key":"key-rotated"}`
+	refreshed, changed, err = cfg.RefreshSecrets()
+	if err != nil {
+		t.Fatalf("RefreshSecrets() error = %v", err)
+	}
+	if !changed {
+		t.Fatal("RefreshSecrets() missed a rotated secret")
+	}
+	if refreshed.OpsGenieAPIKey != "key-rotated" || refreshed.SlackBotToken != "xoxb-first" {
+		t.Fatalf("refreshed %q/%q, want the rotated key and the same bot token", refreshed.OpsGenieAPIKey, refreshed.SlackBotToken)
+	}
+	if cfg.OpsGenieAPIKey != "key-first" {
+		t.Fatalf("RefreshSecrets() changed the original config to %q", cfg.OpsGenieAPIKey)
+	}
+	if refreshed.SlackSigningSecret != "signing-secret" {
+		t.Fatalf("plain value changed to %q", refreshed.SlackSigningSecret)
+	}
+
+	delete(values, "secret/data/slack")
+	if _, _, err := cfg.RefreshSecrets(); err == nil {
+		t.Fatal("RefreshSecrets() succeeded with a missing secret")
+	}
+}
+
+func TestRefreshSecretsWithoutReferences(t *testing.T) {
+	setRequiredEnv(t, map[string]string{
+		"SLACK_SIGNING_SECRET": "signing-secret",
+		"SLACK_BOT_TOKEN":      "xoxb-plain",
+		"OPSGENIE_API_KEY":     "key",
+		"OPSGENIE_TEAM_ID":     "team",
+	})
+
+	cfg, err := LoadWithResolver(secrets.NewResolverWithProviders(nil))
+	if err != nil {
+		t.Fatalf("LoadWithResolver() error = %v", err)
+	}
+	if cfg.HasSecretReferences() {
+		t.Fatal("HasSecretReferences() = true for plain values")
+	}
+	if _, changed, err := cfg.RefreshSecrets(); err != nil || changed {
+		t.Fatalf("RefreshSecrets() = %v, %v, want no change", changed, err)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	StoreBackendMemory = "memory"
+	StoreBackendFile   = "file"
+	StoreBackendRedis  = "redis"
+
+	defaultStoreKeyPrefix = "opsgenie-bot:"
+)
+
+type StoreConfig struct {
+	Backend   string `yaml:"backend"`
+	Path      string `yaml:"path"`
+	RedisURL  string `yaml:"-"`
+	KeyPrefix string `yaml:"key_prefix"`
+}
+
+func (s StoreConfig) Durable() bool {
+	return s.Backend == StoreBackendFile || s.Backend == StoreBackendRedis
+}
+
+func (s StoreConfig) Shared() bool {
+	return s.Backend == StoreBackendRedis
+}
+
+func (s StoreConfig) validate() error {
+	switch s.Backend {
+	case StoreBackendMemory:
+	case StoreBackendFile:
+		if s.Path == "" {
+			return fmt.Errorf("store file backend needs a path")
+		}
+	case StoreBackendRedis:
+		if s.RedisURL == "" {
+			return fmt.Errorf("missing required environment variables: [REDIS_URL]")
+		}
+		if _, err := url.Parse(s.RedisURL); err != nil {
+			return fmt.Errorf("REDIS_URL is invalid: %w", err)
+		}
+	default:
+		return fmt.Errorf("store has invalid backend %q", s.Backend)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import "fmt"
+
+const (
+	FieldTypeText        = "text"
+	FieldTypeSelect      = "select"
+	FieldTypeMultiSelect = "multi_select"
+	FieldTypeCheckbox    = "checkbox"
+	FieldTypeDate        = "date"
+	FieldTypeUser        = "user"
+
+	MapToDetails  = "details"
+	MapToTags     = "tags"
+	MapToPriority = "priority"
+	MapToEntity   = "entity"
+	MapToActions  = "actions"
+)
+
+type IncidentTemplate struct {
+	ID          string            `yaml:"id"`
+	Name        string            `yaml:"name"`
+	Title       string            `yaml:"title"`
+	Description string            `yaml:"description"`
+	Urgency     string            `yaml:"urgency"`
+	TeamID      string            `yaml:"team_id"`
+	Entity      string            `yaml:"entity"`
+	Tags        []string          `yaml:"tags"`
+	Details     map[string]string `yaml:"details"`
+	Actions     []string          `yaml:"actions"`
+	Fields      []TemplateField   `yaml:"fields"`
+}
+
+type TemplateField struct {
+	ID          string        `yaml:"id"`
+	Label       string        `yaml:"label"`
+	Type        string        `yaml:"type"`
+	Placeholder string        `yaml:"placeholder"`
+	Multiline   bool          `yaml:"multiline"`
+	Required    bool          `yaml:"required"`
+	Default     string        `yaml:"default"`
+	Options     []FieldOption `yaml:"options"`
+	MapsTo      string        `yaml:"maps_to"`
+	Key         string        `yaml:"key"`
+}
+
+type FieldOption struct {
+	Label string `yaml:"label"`
+	Value string `yaml:"value"`
+}
+
+func (c *Config) Template(id string) (*IncidentTemplate, bool) {
+	for i := range c.Templates {
+		if c.Templates[i].ID == id {
+			return &c.Templates[i], true
+		}
+	}
+	return nil, false
+}
+
+func (f TemplateField) DetailKey() string {
+	if f.Key != "" {
+		return f.Key
+	}
+	return f.ID
+}
+
+func (f TemplateField) Target() string {
+	if f.MapsTo == "" {
+		return MapToDetails
+	}
+	return f.MapsTo
+}
+
+func (c *Config) validateTemplates() error {
+	seen := make(map[string]bool)
+	for _, template := range c.Templates {
+		if template.ID == "" || template.Name == "" {
+			return fmt.Errorf("template is missing an id or name")
+		}
+		if seen[template.ID] {
+			return fmt.Errorf("duplicate template id %q", template.ID)
+		}
+		seen[template.ID] = true
+
+		if template.Urgency != "" {
+			if _, ok := c.Urgency(template.Urgency); !ok {
+				return fmt.Errorf("template %s has unknown urgency %q", template.ID, template.Urgency)
+			}
+		}
+
+		fields := make(map[string]bool)
+		for _, field := range template.Fields {
+			if field.ID == "" || field.Label == "" {
+				return fmt.Errorf("template %s has a field without id or label", template.ID)
+			}
+			if fields[field.ID] {
+				return fmt.Errorf("template %s has duplicate field %q", template.ID, field.ID)
+			}
+			fields[field.ID] = true
+
+			switch field.Type {
+			case FieldTypeText, FieldTypeDate, FieldTypeUser:
+			case FieldTypeSelect, FieldTypeMultiSelect, FieldTypeCheckbox:
+				if len(field.Options) == 0 {
+					return fmt.Errorf("template %s field %s needs options", template.ID, field.ID)
+				}
+			default:
+				return fmt.Errorf("template %s field %s has invalid type %q", template.ID, field.ID, field.Type)
+			}
+
+			switch field.Target() {
+			case MapToDetails, MapToTags, MapToEntity, MapToActions:
+			case MapToPriority:
+				for _, option := range field.Options {
+					if !validPriority(option.Value) {
+						return fmt.Errorf("template %s field %s maps to priority but has value %q", template.ID, field.ID, option.Value)
+					}
+				}
+			default:
+				return fmt.Errorf("template %s field %s has invalid maps_to %q", template.ID, field.ID, field.MapsTo)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type ValidationConfig struct {
+	TitlePattern   string              `yaml:"title_pattern"`
+	TitleHint      string              `yaml:"title_hint"`
+	RequiredFields map[string][]string `yaml:"required_fields"`
+
+	titlePattern *regexp.Regexp
+}
+
+func (v ValidationConfig) MatchTitle(title string) bool {
+	return v.titlePattern == nil || v.titlePattern.MatchString(title)
+}
+
+func (v ValidationConfig) Required(priority string) []string {
+	return v.RequiredFields[priority]
+}
+
+func (v *ValidationConfig) compile() error {
+	for priority := range v.RequiredFields {
+		if !validPriority(priority) {
+			return fmt.Errorf("validation has required fields for invalid priority %q", priority)
+		}
+	}
+
+	if v.TitlePattern == "" {
+		return nil
+	}
+	pattern, err := regexp.Compile(v.TitlePattern)
+	if err != nil {
+		return fmt.Errorf("validation has invalid title_pattern: %w", err)
+	}
+	v.titlePattern = pattern
+	return nil
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	ackCommand        = "ack"
+	closeCommand      = "close"
+	alertStateJobType = "alert.state"
+)
+
+type AlertStateHandler struct {
+	alertService  *service.AlertService
+	users         *service.UserDirectory
+	permissions   *service.PermissionService
+	announcements *service.AnnouncementService
+	audit         *service.AuditService
+	notify        func(userID, responseURL, text string, blocks []slack.Block)
+	enqueue       func(jobType string, payload interface{}) error
+	logger        *logrus.Logger
+}
+
+type alertStateRequest struct {
+	Action      string         `json:"action"`
+	Alert       model.AlertRef `json:"alert"`
+	UserID      string         `json:"userId"`
+	UserName    string         `json:"userName"`
+	ChannelID   string         `json:"channelId"`
+	ResponseURL string         `json:"responseUrl,omitempty"`
+	Source      string         `json:"source"`
+}
+
+type alertStateJob struct {
+	Request alertStateRequest `json:"request"`
+	TinyID  string            `json:"tinyId,omitempty"`
+}
+
+func NewAlertStateHandler(
+	alertService *service.AlertService,
+	users *service.UserDirectory,
+	permissions *service.PermissionService,
+	announcements *service.AnnouncementService,
+	audit *service.AuditService,
+	logger *logrus.Logger,
+) *AlertStateHandler {
+	return &AlertStateHandler{
+		alertService:  alertService,
+		users:         users,
+		permissions:   permissions,
+		announcements: announcements,
+		audit:         audit,
+		logger:        logger,
+	}
+}
+
+func (h *AlertStateHandler) Register(app *IncidentApp) {
+	app.RegisterActionHandler(service.AcknowledgeActionID, h.handleButton(model.ActionAck))
+	app.RegisterActionHandler(service.CloseActionID, h.handleButton(model.ActionClose))
+	app.RegisterCommandHandler(ackCommand, h.handleCommand(model.ActionAck))
+	app.RegisterCommandHandler(closeCommand, h.handleCommand(model.ActionClose))
+	app.RegisterJobHandler(alertStateJobType, h.runAlertState)
+	h.notify = app.notify
+	h.enqueue = app.Enqueue
+}
+
+func (h *AlertStateHandler) handleButton(action string) ActionHandlerFunc {
+	return func(payload slack.InteractionCallback, blockAction *slack.BlockAction) error {
+		var ref model.AlertRef
+		if err := json.Unmarshal([]byte(blockAction.Value), &ref); err != nil {
+			return fmt.Errorf("failed to decode alert reference: %w", err)
+		}
+
+		request := alertStateRequest{
+			Action:      action,
+			Alert:       ref,
+			UserID:      payload.User.ID,
+			UserName:    payload.User.Name,
+			ChannelID:   payload.Channel.ID,
+			ResponseURL: payload.ResponseURL,
+			Source:      model.SourceButton,
+		}
+		if err := h.announcements.Track(ref.ID, payload); err != nil {
+			h.logger.WithError(err).WithField("alert_id", ref.ID).Warn("Failed to track the clicked alert message")
+		}
+		if err := h.enqueue(alertStateJobType, alertStateJob{Request: request}); err != nil {
+			h.logger.WithError(err).WithField("alert_id", ref.ID).Errorf("Failed to queue %s", action)
+			message := fmt.Sprintf("Failed to %s %s.", stateVerb(action), ref.Label())
+			h.notify(payload.User.ID, payload.ResponseURL, message, errorBlocks(message))
+		}
+		return nil
+	}
+}
+
+func (h *AlertStateHandler) handleCommand(action string) CommandHandlerFunc {
+	return func(cmd slack.SlashCommand, args []string) *Response {
+		if len(args) != 1 {
+			return ephemeralResponse(fmt.Sprintf("Usage: `/opsgenie %s <tinyId>`", action), nil)
+		}
+
+		tinyID := strings.TrimPrefix(args[0], "#")
+		job := alertStateJob{
+			Request: alertStateRequest{
+				Action:      action,
+				UserID:      cmd.UserID,
+				UserName:    cmd.UserName,
+				ChannelID:   cmd.ChannelID,
+				ResponseURL: cmd.ResponseURL,
+				Source:      model.SourceSlashCommand,
+			},
+			TinyID: tinyID,
+		}
+		if err := h.enqueue(alertStateJobType, job); err != nil {
+			h.logger.WithError(err).WithField("tiny_id", tinyID).Errorf("Failed to queue %s", action)
+			return ephemeralResponse(fmt.Sprintf("❌ Failed to %s #%s. Please try again.", stateVerb(action), tinyID), nil)
+		}
+		return ephemeralResponse(fmt.Sprintf("⏳ %s #%s…", stateProgress(action), tinyID), nil)
+	}
+}
+
+func (h *AlertStateHandler) runAlertState(ctx context.Context, data json.RawMessage) error {
+	var job alertStateJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("failed to decode alert state job: %w", err)
+	}
+
+	request := job.Request
+	if job.TinyID != "" {
+		result, err := h.alertService.FindByTinyID(job.TinyID)
+		if err != nil {
+			h.logger.WithError(err).WithField("tiny_id", job.TinyID).Warnf("Failed to find alert to %s", request.Action)
+			message := fmt.Sprintf("Could not find alert #%s.", job.TinyID)
+			h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+			return nil
+		}
+		request.Alert = result.Ref()
+	}
+
+	h.apply(request)
+	return nil
+}
+
+func (h *AlertStateHandler) apply(request alertStateRequest) {
+	logger := h.logger.WithFields(logrus.Fields{
+		"alert_id": request.Alert.ID,
+		"user_id":  request.UserID,
+		"action":   request.Action,
+	})
+
+	event := model.AuditEvent{
+		Action:     request.Action,
+		Outcome:    model.AuditOutcomeSuccess,
+		UserID:     request.UserID,
+		UserName:   request.UserName,
+		ChannelID:  request.ChannelID,
+		Source:     request.Source,
+		Priority:   request.Alert.Priority,
+		OpsGenieID: request.Alert.ID,
+		TinyID:     request.Alert.TinyID,
+		Kind:       request.Alert.Kind,
+	}
+
+	if decision := h.permissions.Authorize(model.AccessRequest{
+		UserID:    request.UserID,
+		ChannelID: request.ChannelID,
+		Action:    request.Action,
+		Priority:  request.Alert.Priority,
+	}); !decision.Allowed {
+		h.notify(request.UserID, request.ResponseURL, "⛔ "+decision.Message, nil)
+		return
+	}
+
+	user, _ := h.users.OpsGenieUser(request.UserID)
+
+	var err error
+	if request.Action == model.ActionClose {
+		err = h.alertService.Close(request.Alert, user)
+	} else {
+		err = h.alertService.Acknowledge(request.Alert, user)
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to %s alert", request.Action)
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+		h.audit.Record(event)
+
+		message := fmt.Sprintf("Failed to %s %s.", stateVerb(request.Action), request.Alert.Label())
+		if errors.Is(err, service.ErrAckNotSupported) {
+			message = fmt.Sprintf("%s is an incident, and OpsGenie incidents cannot be acknowledged.", request.Alert.Label())
+		}
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return
+	}
+	h.audit.Record(event)
+	logger.Info("Changed alert state")
+
+	if err := h.announcements.SetState(request.Alert.ID, request.Action, request.UserID); err != nil {
+		logger.WithError(err).Warn("Failed to update alert announcements")
+	}
+
+	if request.Source == model.SourceSlashCommand {
+		message := fmt.Sprintf("%s %s.", stateDone(request.Action), request.Alert.Label())
+		h.notify(request.UserID, request.ResponseURL, message, []slack.Block{markdownSection(message)})
+	}
+}
+
+func stateVerb(action string) string {
+	if action == model.ActionClose {
+		return "close"
+	}
+	return "acknowledge"
+}
+
+func stateProgress(action string) string {
+	if action == model.ActionClose {
+		return "Closing"
+	}
+	return "Acknowledging"
+}
+
+func stateDone(action string) string {
+	if action == model.ActionClose {
+		return "🔒 Closed"
+	}
+	return "✅ Acknowledged"
+}
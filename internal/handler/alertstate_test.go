@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/audit"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+func TestAlertStateCommandsQueueJobs(t *testing.T) {
+	tests := []struct {
+		text       string
+		wantAction string
+		wantReply  string
+	}{
+		{"ack #42", model.ActionAck, "⏳ Acknowledging #42"},
+		{"close 42", model.ActionClose, "⏳ Closing #42"},
+		{"ack", "", "Usage: `/opsgenie ack <tinyId>`"},
+		{"close 42 43", "", "Usage: `/opsgenie close <tinyId>`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			logger := quietLogger()
+			backend := queue.NewMemoryBackend()
+			app := NewIncidentApp(nil, nil, nil, nil, nil, nil, nil, nil, nil, store.NewMemoryStore(),
+				queue.NewWithBackend(backend, 1, 1, logger), &config.Config{SlackTeamID: "T1"}, logger)
+			NewAlertStateHandler(nil, nil, nil, nil, nil, logger).Register(app)
+
+			resp := app.HandleCommand(slack.SlashCommand{
+				TeamID:      "T1",
+				UserID:      "U1",
+				Command:     opsgenieCommand,
+				Text:        tt.text,
+				ResponseURL: "https://hooks.slack.com/commands/1",
+			})
+			msg, ok := resp.Body.(slack.Msg)
+			if !ok || !strings.HasPrefix(msg.Text, tt.wantReply) {
+				t.Fatalf("HandleCommand() body = %#v, want %q", resp.Body, tt.wantReply)
+			}
+			if tt.wantAction == "" {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			job, err := backend.Pop(ctx)
+			if err != nil {
+				t.Fatalf("Pop() error = %v, want a queued job", err)
+			}
+			var payload alertStateJob
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if job.Type != alertStateJobType || payload.TinyID != "42" || payload.Request.Action != tt.wantAction {
+				t.Fatalf("queued %s %+v, want %s of #42", job.Type, payload, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestAlertStateChecksPermissions(t *testing.T) {
+	logger := quietLogger()
+	st := store.NewMemoryStore()
+	auditService := service.NewAuditService(audit.NewStoreSink(st), "", logger)
+	permissions := service.NewPermissionService(config.PermissionConfig{
+		Default: config.EffectAllow,
+		Rules: []config.PermissionRule{
+			{Effect: config.EffectDeny, Actions: []string{model.ActionAck, model.ActionClose}, Priorities: []string{"P1"}},
+		},
+	}, nil, st, auditService, logger)
+
+	for _, action := range []string{model.ActionAck, model.ActionClose} {
+		t.Run(action, func(t *testing.T) {
+			h := NewAlertStateHandler(nil, nil, permissions, nil, auditService, logger)
+			var notified []string
+			h.notify = func(userID, responseURL, text string, blocks []slack.Block) {
+				notified = append(notified, text)
+			}
+
+			h.apply(alertStateRequest{
+				Action: action,
+				Alert:  model.AlertRef{ID: "alert-1", TinyID: "7", Priority: model.PriorityP1},
+				UserID: "U1",
+				Source: model.SourceButton,
+			})
+
+			if len(notified) != 1 || !strings.HasPrefix(notified[0], "⛔ You are not allowed to "+stateVerb(action)+" P1") {
+				t.Fatalf("notified %q, want a permission denial", notified)
+			}
+		})
+	}
+}
@@ -0,0 +1,533 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	messageShortcutCallbackID = "raise_opsgenie_incident"
+	opsgenieCommand           = "/opsgenie"
+)
+
+type IncidentApp struct {
+	slackService    *service.SlackService
+	alertService    *service.AlertService
+	incidents       *service.IncidentService
+	history         *service.HistoryService
+	channels        *service.IncidentChannelService
+	announcements   *service.AnnouncementService
+	permissions     *service.PermissionService
+	rateLimiter     *service.RateLimiter
+	audit           *service.AuditService
+	jobs            *queue.Queue
+	config          *config.Config
+	logger          *logrus.Logger
+	eventHandlers   map[string][]EventHandlerFunc
+	actionHandlers  map[string]ActionHandlerFunc
+	optionHandlers  map[string]OptionsHandlerFunc
+	viewHandlers    map[string]ViewHandlerFunc
+	commandHandlers map[string]CommandHandlerFunc
+	jobHandlers     map[string]queue.HandlerFunc
+	store           store.Store
+}
+
+type App interface {
+	HandleCommand(cmd slack.SlashCommand) *Response
+	HandleInteraction(payload slack.InteractionCallback) *Response
+	HandleCallbackEvent(event slackevents.EventsAPIEvent, retryNum, retryReason string) error
+	RunJob(ctx context.Context, job queue.Job) error
+}
+
+type Response struct {
+	Body interface{}
+}
+
+type ActionHandlerFunc func(payload slack.InteractionCallback, action *slack.BlockAction) error
+
+type OptionsHandlerFunc func(payload slack.InteractionCallback) (*slack.OptionsResponse, error)
+
+type ViewHandlerFunc func(payload slack.InteractionCallback) *Response
+
+type CommandHandlerFunc func(cmd slack.SlashCommand, args []string) *Response
+
+func NewIncidentApp(
+	slackService *service.SlackService,
+	alertService *service.AlertService,
+	incidents *service.IncidentService,
+	history *service.HistoryService,
+	channels *service.IncidentChannelService,
+	announcements *service.AnnouncementService,
+	permissions *service.PermissionService,
+	rateLimiter *service.RateLimiter,
+	audit *service.AuditService,
+	store store.Store,
+	jobs *queue.Queue,
+	cfg *config.Config,
+	logger *logrus.Logger,
+) *IncidentApp {
+	a := &IncidentApp{
+		slackService:    slackService,
+		alertService:    alertService,
+		incidents:       incidents,
+		history:         history,
+		channels:        channels,
+		announcements:   announcements,
+		permissions:     permissions,
+		rateLimiter:     rateLimiter,
+		audit:           audit,
+		store:           store,
+		jobs:            jobs,
+		config:          cfg,
+		logger:          logger,
+		eventHandlers:   make(map[string][]EventHandlerFunc),
+		actionHandlers:  make(map[string]ActionHandlerFunc),
+		optionHandlers:  make(map[string]OptionsHandlerFunc),
+		viewHandlers:    make(map[string]ViewHandlerFunc),
+		commandHandlers: make(map[string]CommandHandlerFunc),
+		jobHandlers:     make(map[string]queue.HandlerFunc),
+	}
+	a.registerDefaultEventHandlers()
+	a.registerModalActionHandlers()
+	a.registerJobHandlers()
+	a.RegisterCommandHandler(auditCommand, a.handleAuditCommand)
+	if audit != nil && jobs != nil {
+		audit.SetEnqueue(func(event model.AuditEvent) error {
+			return a.Enqueue(auditJobType, event)
+		})
+	}
+	return a
+}
+
+func (a *IncidentApp) HandleCommand(cmd slack.SlashCommand) *Response {
+	a.logger.WithFields(logrus.Fields{
+		"command":    cmd.Command,
+		"user_id":    cmd.UserID,
+		"channel_id": cmd.ChannelID,
+		"trigger_id": cmd.TriggerID,
+	}).Info("Received slash command")
+
+	slackCmd := model.SlackCommand{
+		TeamID:      cmd.TeamID,
+		ChannelID:   cmd.ChannelID,
+		ChannelName: cmd.ChannelName,
+		UserID:      cmd.UserID,
+		UserName:    cmd.UserName,
+		Command:     cmd.Command,
+		Text:        cmd.Text,
+		ResponseURL: cmd.ResponseURL,
+		TriggerID:   cmd.TriggerID,
+		TeamDomain:  cmd.TeamDomain,
+	}
+
+	if cmd.Command == opsgenieCommand {
+		args := strings.Fields(cmd.Text)
+		if len(args) > 0 {
+			if fn, ok := a.commandHandlers[args[0]]; ok {
+				return fn(cmd, args[1:])
+			}
+		}
+		return ephemeralResponse(a.commandUsage(), nil)
+	}
+
+	if err := a.OpenIncidentForm(cmd.TriggerID, slackCmd.ModalMetadata(), ""); err != nil {
+		a.logger.WithError(err).Error("Failed to open modal")
+		errorMsg := "Sorry, something went wrong while opening the incident form. Please try again."
+		return ephemeralResponse(errorMsg, errorBlocks(errorMsg))
+	}
+	return &Response{}
+}
+
+func (a *IncidentApp) HandleInteraction(payload slack.InteractionCallback) *Response {
+	switch payload.Type {
+	case slack.InteractionTypeMessageAction:
+		a.handleMessageShortcut(payload)
+		return &Response{}
+	case slack.InteractionTypeBlockActions:
+		a.dispatchBlockActions(payload)
+		return &Response{}
+	case slack.InteractionTypeBlockSuggestion:
+		return a.handleBlockSuggestion(payload)
+	case slack.InteractionTypeViewSubmission:
+		return a.handleViewSubmission(payload)
+	default:
+		return &Response{}
+	}
+}
+
+func (a *IncidentApp) handleMessageShortcut(payload slack.InteractionCallback) {
+	if payload.CallbackID != messageShortcutCallbackID {
+		a.logger.WithField("callback_id", payload.CallbackID).Debug("Ignoring unknown message shortcut")
+		return
+	}
+
+	a.logger.WithFields(logrus.Fields{
+		"user_id":    payload.User.ID,
+		"channel_id": payload.Channel.ID,
+		"message_ts": payload.Message.Timestamp,
+	}).Info("Received message shortcut")
+
+	metadata := model.ModalMetadata{
+		ChannelID:       payload.Channel.ID,
+		ChannelName:     payload.Channel.Name,
+		TeamDomain:      payload.Team.Domain,
+		MessageTS:       payload.Message.Timestamp,
+		MessageAuthorID: payload.Message.User,
+		Source:          model.SourceMessageShortcut,
+	}
+
+	if err := a.OpenIncidentForm(payload.TriggerID, metadata, payload.Message.Text); err != nil {
+		a.logger.WithError(err).Error("Failed to open modal")
+		errorMsg := "Sorry, something went wrong while opening the incident form. Please try again."
+		a.sendErrorMessage(payload.User.ID, errorMsg)
+	}
+}
+
+func (a *IncidentApp) OpenIncidentForm(triggerID string, metadata model.ModalMetadata, description string) error {
+	if len(a.config.Templates) > 0 {
+		return a.slackService.OpenView(triggerID, a.slackService.TemplatePickerModal(metadata, a.config.Templates))
+	}
+	return a.slackService.OpenView(triggerID, a.slackService.IncidentModal(service.IncidentForm{
+		Metadata:    metadata,
+		Description: description,
+		Urgencies:   a.config.Urgencies,
+	}))
+}
+
+func (a *IncidentApp) handleViewSubmission(payload slack.InteractionCallback) *Response {
+	if fn, ok := a.viewHandlers[payload.View.CallbackID]; ok {
+		return fn(payload)
+	}
+	if payload.View.CallbackID == service.TemplatePickerCallbackID {
+		return a.handleTemplatePicked(payload)
+	}
+
+	values := payload.View.State.Values
+	title := values["title_block"]["title"].Value
+	description := values["description_block"]["description"].Value
+	urgency := values[service.UrgencyBlockID][service.UrgencyActionID].SelectedOption.Value
+
+	var services []model.OpsGenieService
+	for _, option := range values["services_block"]["services"].SelectedOptions {
+		services = append(services, model.OpsGenieService{ID: option.Value, Name: option.Text.Text})
+	}
+
+	metadata := a.parseModalMetadata(payload.View.PrivateMetadata)
+	template, hasTemplate := a.config.Template(metadata.TemplateID)
+
+	priority, ok := a.urgencyPriority(urgency, a.responderTeamID(values, template))
+	if !ok {
+		return a.errorsResponse(map[string]string{service.UrgencyBlockID: "Pick an urgency from the list"})
+	}
+
+	alert := &model.Alert{
+		Title:       title,
+		Description: description,
+		Priority:    priority,
+		Source:      "Slack",
+		Tags:        []string{"slack-incident"},
+		Reporter: model.Reporter{
+			ID:       payload.User.ID,
+			Name:     payload.User.Name,
+			Username: payload.User.Name,
+		},
+		Team: model.Team{
+			ID:   payload.Team.ID,
+			Name: payload.Team.Domain,
+		},
+		Details:          a.messageDetails(metadata),
+		ImpactedServices: services,
+		ChannelID:        metadata.ChannelID,
+	}
+
+	if hasTemplate {
+		a.applyTemplate(alert, template, values)
+	}
+	a.applyFormSelections(alert, values)
+
+	access := model.AccessRequest{
+		UserID:    payload.User.ID,
+		ChannelID: metadata.ChannelID,
+		Action:    model.ActionCreate,
+		Priority:  alert.Priority,
+	}
+	if decision := a.permissions.Authorize(access); !decision.Allowed {
+		return a.errorsResponse(map[string]string{service.UrgencyBlockID: decision.Message})
+	}
+
+	if errs := a.validateSubmission(alert, values); len(errs) > 0 {
+		return a.errorsResponse(errs)
+	}
+
+	if decision := a.rateLimiter.Allow(access); !decision.Allowed {
+		return a.errorsResponse(map[string]string{service.UrgencyBlockID: decision.Message})
+	}
+
+	source := metadata.Source
+	if source == "" {
+		source = model.SourceSlashCommand
+	}
+
+	job := createIncidentJob{
+		Alert:       *alert,
+		ChannelID:   alert.ChannelID,
+		Source:      source,
+		UserID:      payload.User.ID,
+		ResponseURL: metadata.ResponseURL,
+	}
+	if err := a.Enqueue(createIncidentJobType, job); err != nil {
+		a.logger.WithError(err).WithField("user_id", job.UserID).Error("Failed to queue incident creation")
+		return a.errorsResponse(map[string]string{service.UrgencyBlockID: "Failed to create incident. Please try again."})
+	}
+	return &Response{Body: map[string]string{"response_action": "clear"}}
+}
+
+func (a *IncidentApp) parseModalMetadata(privateMetadata string) model.ModalMetadata {
+	var metadata model.ModalMetadata
+	if privateMetadata != "" {
+		if err := json.Unmarshal([]byte(privateMetadata), &metadata); err != nil {
+			a.logger.WithError(err).Warn("Failed to parse private metadata")
+		}
+	}
+	return metadata
+}
+
+func (a *IncidentApp) RegisterActionHandler(actionID string, fn ActionHandlerFunc) {
+	a.actionHandlers[actionID] = fn
+}
+
+func (a *IncidentApp) dispatchBlockActions(payload slack.InteractionCallback) {
+	for _, action := range payload.ActionCallback.BlockActions {
+		fn, ok := a.actionHandlers[action.ActionID]
+		if !ok {
+			a.logger.WithField("action_id", action.ActionID).Debug("No handler registered for action")
+			continue
+		}
+
+		if err := fn(payload, action); err != nil {
+			a.logger.WithError(err).WithField("action_id", action.ActionID).Error("Action handler failed")
+		}
+	}
+}
+
+func (a *IncidentApp) RegisterOptionsHandler(actionID string, fn OptionsHandlerFunc) {
+	a.optionHandlers[actionID] = fn
+}
+
+func (a *IncidentApp) RegisterViewHandler(callbackID string, fn ViewHandlerFunc) {
+	a.viewHandlers[callbackID] = fn
+}
+
+func (a *IncidentApp) RegisterCommandHandler(name string, fn CommandHandlerFunc) {
+	a.commandHandlers[name] = fn
+}
+
+func (a *IncidentApp) commandUsage() string {
+	names := make([]string, 0, len(a.commandHandlers)+1)
+	for name := range a.commandHandlers {
+		names = append(names, name)
+	}
+	if a.config.OAuth.Enabled() {
+		names = append(names, connectCommand)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names)+1)
+	lines = append(lines, "Usage: `/opsgenie <command>`. Use `/create-incident` to raise an incident. Commands:")
+	for _, name := range names {
+		lines = append(lines, "• `"+name+"`")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isSubcommand(cmd slack.SlashCommand, name string) bool {
+	args := strings.Fields(cmd.Text)
+	return cmd.Command == opsgenieCommand && len(args) > 0 && args[0] == name
+}
+
+func (a *IncidentApp) handleBlockSuggestion(payload slack.InteractionCallback) *Response {
+	response := &slack.OptionsResponse{Options: []*slack.OptionBlockObject{}}
+
+	if fn, ok := a.optionHandlers[payload.ActionID]; ok {
+		options, err := fn(payload)
+		if err != nil {
+			a.logger.WithError(err).WithField("action_id", payload.ActionID).Error("Options handler failed")
+		} else if options != nil {
+			response = options
+		}
+	} else {
+		a.logger.WithField("action_id", payload.ActionID).Debug("No handler registered for options")
+	}
+
+	return &Response{Body: response}
+}
+
+func (a *IncidentApp) messageDetails(metadata model.ModalMetadata) map[string]string {
+	if metadata.MessageTS == "" {
+		return nil
+	}
+	return buildMessageDetails(a.slackService, a.logger, metadata.ChannelID, metadata.MessageTS, metadata.MessageAuthorID)
+}
+
+func buildMessageDetails(
+	slackService *service.SlackService,
+	logger *logrus.Logger,
+	channelID, messageTS, authorID string,
+) map[string]string {
+	details := map[string]string{
+		"slackChannelId": channelID,
+		"slackMessageTs": messageTS,
+	}
+
+	permalink, err := slackService.GetPermalink(channelID, messageTS)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get message permalink")
+	} else {
+		details["slackMessageLink"] = permalink
+	}
+
+	if authorID != "" {
+		details["messageAuthorId"] = authorID
+		name, err := slackService.GetUserName(authorID)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to get message author")
+		} else {
+			details["messageAuthorName"] = name
+		}
+	}
+
+	return details
+}
+
+func createIncidentChannel(
+	channels *service.IncidentChannelService,
+	logger *logrus.Logger,
+	alert model.Alert,
+	result *model.AlertCreationResult,
+) *model.IncidentChannel {
+	if result.Pending || !channels.ShouldCreate(alert.Priority) {
+		return nil
+	}
+
+	channel, err := channels.CreateForAlert(alert, result)
+	if err != nil {
+		logger.WithError(err).WithField("alert_id", result.ID).Error("Failed to create incident channel")
+		return nil
+	}
+	return channel
+}
+
+func pendingText(result *model.AlertCreationResult) string {
+	return fmt.Sprintf("⏳ *Incident is being processed by OpsGenie.*\n\n"+
+		"*Title:* %s\n"+
+		"*Priority:* %s\n"+
+		"*Request ID:* %s\n\n"+
+		"It will appear in OpsGenie shortly. Please do not submit it again.",
+		result.Title,
+		string(result.Priority),
+		result.RequestID)
+}
+
+func successBlocks(result *model.AlertCreationResult, channel *model.IncidentChannel) []slack.Block {
+	blocks := resultBlocks(result, channel)
+	if result.Pending {
+		return blocks
+	}
+	if actions := service.AlertActionsBlock(result.Ref()); actions != nil {
+		blocks = append(blocks, actions)
+	}
+	return blocks
+}
+
+func resultBlocks(result *model.AlertCreationResult, channel *model.IncidentChannel) []slack.Block {
+	if result.Pending {
+		return []slack.Block{markdownSection(pendingText(result))}
+	}
+
+	blocks := []slack.Block{
+		&slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{
+				Type: slack.MarkdownType,
+				Text: fmt.Sprintf("✅ *Incident created successfully!*\n\n"+
+					"*Title:* %s\n"+
+					"*Priority:* %s\n"+
+					"*ID:* %s",
+					result.Title,
+					string(result.Priority),
+					result.ID),
+			},
+		},
+	}
+
+	if result.URL != "" {
+		blocks = append(blocks, &slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{
+				Type: slack.MarkdownType,
+				Text: fmt.Sprintf("🔗 <%s|View in OpsGenie>", result.URL),
+			},
+		})
+	}
+
+	if channel != nil {
+		blocks = append(blocks, &slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{
+				Type: slack.MarkdownType,
+				Text: fmt.Sprintf("📣 Incident channel: <#%s>", channel.ID),
+			},
+		})
+	}
+
+	return blocks
+}
+
+func errorBlocks(message string) []slack.Block {
+	return []slack.Block{
+		&slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{
+				Type: slack.MarkdownType,
+				Text: "❌ " + message,
+			},
+		},
+	}
+}
+
+func (a *IncidentApp) sendErrorMessage(userID, message string) {
+	if err := a.slackService.SendMessage(userID, message, errorBlocks(message)); err != nil {
+		a.logger.WithError(err).Error("Failed to send error message")
+	}
+}
+
+func (a *IncidentApp) urgencyPriority(urgency, teamID string) (model.AlertPriority, bool) {
+	level, ok := a.config.Urgency(urgency)
+	if !ok {
+		a.logger.WithField("urgency", urgency).Warn("Rejecting unknown urgency")
+		return "", false
+	}
+	return model.AlertPriority(level.PriorityFor(teamID)), true
+}
+
+func (a *IncidentApp) responderTeamID(values map[string]map[string]slack.BlockAction, template *config.IncidentTemplate) string {
+	if team := selectedTeam(values); team != nil {
+		return team.ID
+	}
+	if template != nil && template.TeamID != "" {
+		return template.TeamID
+	}
+	return a.config.OpsGenieTeamID
+}
@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+func TestHandleCommandRunsSubcommandsOnlyForOpsgenie(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		text      string
+		wantRun   bool
+		wantModal bool
+		wantUsage bool
+	}{
+		{"subcommand", "/opsgenie", "audit 5", true, false, false},
+		{"create-incident ignores subcommands", "/create-incident", "audit 5", false, true, false},
+		{"create-incident with a description", "/create-incident", "database is down", false, true, false},
+		{"opsgenie without a subcommand", "/opsgenie", "", false, false, true},
+		{"unknown opsgenie subcommand", "/opsgenie", "bogus", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opened := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/views.open" {
+					opened = true
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+			}))
+			defer server.Close()
+
+			app := newTestApp(store.NewMemoryStore())
+			app.slackService = service.NewSlackServiceWithClient(slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/")), quietLogger())
+
+			var ranWith []string
+			ran := false
+			app.RegisterCommandHandler(auditCommand, func(cmd slack.SlashCommand, args []string) *Response {
+				ran = true
+				ranWith = args
+				return &Response{}
+			})
+
+			resp := app.HandleCommand(slack.SlashCommand{Command: tt.command, Text: tt.text, TriggerID: "trigger"})
+			if ran != tt.wantRun {
+				t.Fatalf("subcommand ran = %v, want %v", ran, tt.wantRun)
+			}
+			if ran && (len(ranWith) != 1 || ranWith[0] != "5") {
+				t.Fatalf("subcommand args = %v, want [5]", ranWith)
+			}
+			if opened != tt.wantModal {
+				t.Fatalf("modal opened = %v, want %v", opened, tt.wantModal)
+			}
+			msg, isMsg := resp.Body.(slack.Msg)
+			gotUsage := isMsg && strings.HasPrefix(msg.Text, "Usage:")
+			if gotUsage != tt.wantUsage {
+				t.Fatalf("usage reply = %v, want %v (body %#v)", gotUsage, tt.wantUsage, resp.Body)
+			}
+			if gotUsage && !strings.Contains(msg.Text, "`audit`") {
+				t.Fatalf("usage does not list registered subcommands: %q", msg.Text)
+			}
+		})
+	}
+}
+
+type slackAPICall struct {
+	Path string
+	Body string
+}
+
+type fakeSlackAPI struct {
+	mu        sync.Mutex
+	calls     []slackAPICall
+	responses map[string]interface{}
+}
+
+func newFakeSlackAPI(t *testing.T, responses map[string]interface{}) (*fakeSlackAPI, *service.SlackService) {
+	t.Helper()
+
+	fake := &fakeSlackAPI{responses: responses}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		fake.mu.Lock()
+		fake.calls = append(fake.calls, slackAPICall{Path: r.URL.Path, Body: string(body)})
+		response, ok := fake.responses[r.URL.Path]
+		fake.mu.Unlock()
+
+		if !ok {
+			response = map[string]interface{}{"ok": true}
+		}
+		if respond, isFunc := response.(func(url.Values) interface{}); isFunc {
+			form, _ := url.ParseQuery(string(body))
+			response = respond(form)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, service.NewSlackServiceWithClient(slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/")), quietLogger())
+}
+
+func (f *fakeSlackAPI) find(path string) []slackAPICall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var found []slackAPICall
+	for _, call := range f.calls {
+		if call.Path == path {
+			found = append(found, call)
+		}
+	}
+	return found
+}
+
+func (f *fakeSlackAPI) openedView(t *testing.T) slack.ModalViewRequest {
+	t.Helper()
+
+	opened := f.find("/views.open")
+	if len(opened) != 1 {
+		t.Fatalf("opened %d views, want 1", len(opened))
+	}
+	var request struct {
+		View slack.ModalViewRequest `json:"view"`
+	}
+	if err := json.Unmarshal([]byte(opened[0].Body), &request); err != nil {
+		t.Fatalf("failed to decode views.open request: %v", err)
+	}
+	return request.View
+}
+
+func inputElement(t *testing.T, view slack.ModalViewRequest, blockID string) slack.BlockElement {
+	t.Helper()
+
+	for _, block := range view.Blocks.BlockSet {
+		if input, ok := block.(*slack.InputBlock); ok && input.BlockID == blockID {
+			return input.Element
+		}
+	}
+	t.Fatalf("view has no %s block", blockID)
+	return nil
+}
+
+func TestMessageShortcutPrefillsIncidentForm(t *testing.T) {
+	fake, slackService := newFakeSlackAPI(t, nil)
+	app := newTestApp(store.NewMemoryStore())
+	app.slackService = slackService
+
+	app.HandleInteraction(slack.InteractionCallback{
+		Type:       slack.InteractionTypeMessageAction,
+		CallbackID: messageShortcutCallbackID,
+		TriggerID:  "trigger",
+		User:       slack.User{ID: "U1"},
+		Channel:    slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}, Name: "ops"}},
+		Team:       slack.Team{Domain: "acme"},
+		Message:    slack.Message{Msg: slack.Msg{Timestamp: "1700000000.000100", User: "U2", Text: "checkout is returning 500s"}},
+	})
+
+	view := fake.openedView(t)
+	description := inputElement(t, view, "description_block").(*slack.PlainTextInputBlockElement)
+	if description.InitialValue != "checkout is returning 500s" {
+		t.Fatalf("description = %q, want the message text", description.InitialValue)
+	}
+
+	var metadata model.ModalMetadata
+	if err := json.Unmarshal([]byte(view.PrivateMetadata), &metadata); err != nil {
+		t.Fatalf("failed to decode private metadata: %v", err)
+	}
+	want := model.ModalMetadata{
+		ChannelID:       "C1",
+		ChannelName:     "ops",
+		TeamDomain:      "acme",
+		MessageTS:       "1700000000.000100",
+		MessageAuthorID: "U2",
+		Source:          model.SourceMessageShortcut,
+	}
+	if metadata != want {
+		t.Fatalf("metadata = %+v, want %+v", metadata, want)
+	}
+}
+
+func TestMessageShortcutIgnoresOtherCallbacks(t *testing.T) {
+	fake, slackService := newFakeSlackAPI(t, nil)
+	app := newTestApp(store.NewMemoryStore())
+	app.slackService = slackService
+
+	app.HandleInteraction(slack.InteractionCallback{
+		Type:       slack.InteractionTypeMessageAction,
+		CallbackID: "other_shortcut",
+		TriggerID:  "trigger",
+	})
+
+	if opened := fake.find("/views.open"); len(opened) != 0 {
+		t.Fatalf("opened %d views for an unknown shortcut, want none", len(opened))
+	}
+}
+
+func TestBuildMessageDetails(t *testing.T) {
+	permalink := map[string]interface{}{"ok": true, "channel": "C1", "permalink": "https://acme.slack.com/archives/C1/p1"}
+	userInfo := map[string]interface{}{"ok": true, "user": map[string]interface{}{"id": "U2", "name": "jdoe", "real_name": "Jane Doe"}}
+	failed := map[string]interface{}{"ok": false, "error": "channel_not_found"}
+
+	tests := []struct {
+		name      string
+		responses map[string]interface{}
+		authorID  string
+		want      map[string]string
+	}{
+		{
+			name:      "message with author",
+			responses: map[string]interface{}{"/chat.getPermalink": permalink, "/users.info": userInfo},
+			authorID:  "U2",
+			want: map[string]string{
+				"slackChannelId":    "C1",
+				"slackMessageTs":    "1.1",
+				"slackMessageLink":  "https://acme.slack.com/archives/C1/p1",
+				"messageAuthorId":   "U2",
+				"messageAuthorName": "Jane Doe",
+			},
+		},
+		{
+			name:      "message without author",
+			responses: map[string]interface{}{"/chat.getPermalink": permalink},
+			want: map[string]string{
+				"slackChannelId":   "C1",
+				"slackMessageTs":   "1.1",
+				"slackMessageLink": "https://acme.slack.com/archives/C1/p1",
+			},
+		},
+		{
+			name:      "lookups fail",
+			responses: map[string]interface{}{"/chat.getPermalink": failed, "/users.info": failed},
+			authorID:  "U2",
+			want: map[string]string{
+				"slackChannelId":  "C1",
+				"slackMessageTs":  "1.1",
+				"messageAuthorId": "U2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, slackService := newFakeSlackAPI(t, tt.responses)
+
+			got := buildMessageDetails(slackService, quietLogger(), "C1", "1.1", tt.authorID)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("details = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUrgencyPriority(t *testing.T) {
+	app := newTestApp(store.NewMemoryStore())
+	app.config = &config.Config{
+		OpsGenieTeamID: "team-default",
+		Urgencies: []config.UrgencyLevel{
+			{Value: "sev1", Label: "Sev 1", Priority: "P1", Teams: map[string]string{"team-web": "P2"}},
+			{Value: "fyi", Label: "FYI", Priority: "P5"},
+		},
+	}
+	template := &config.IncidentTemplate{ID: "web", TeamID: "team-web"}
+
+	tests := []struct {
+		name     string
+		urgency  string
+		values   map[string]map[string]slack.BlockAction
+		template *config.IncidentTemplate
+		want     model.AlertPriority
+		wantOK   bool
+	}{
+		{"default team", "sev1", nil, nil, model.PriorityP1, true},
+		{"P5", "fyi", nil, nil, model.PriorityP5, true},
+		{"template team override", "sev1", nil, template, model.PriorityP2, true},
+		{"selected team override", "sev1", map[string]map[string]slack.BlockAction{service.TeamBlockID: teamSelection("team-web", "Web")}, nil, model.PriorityP2, true},
+		{"selected team wins over the template", "sev1", map[string]map[string]slack.BlockAction{service.TeamBlockID: teamSelection("team-dba", "DBA")}, template, model.PriorityP1, true},
+		{"unknown urgency", "P1", nil, nil, "", false},
+		{"missing urgency", "", nil, nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := app.urgencyPriority(tt.urgency, app.responderTeamID(tt.values, tt.template))
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("urgencyPriority() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestViewSubmissionRejectsUnknownUrgency(t *testing.T) {
+	app := newTestApp(store.NewMemoryStore())
+	app.config = &config.Config{Urgencies: config.DefaultUrgencies()}
+
+	resp := app.HandleInteraction(slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+		View: slack.View{
+			CallbackID: service.IncidentModalCallbackID,
+			State: &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+				"title_block":          {"title": {Value: "Database down"}},
+				service.UrgencyBlockID: {service.UrgencyActionID: {SelectedOption: slack.OptionBlockObject{Value: "P1"}}},
+			}},
+		},
+	})
+
+	errs, ok := resp.Body.(*slack.ViewSubmissionResponse)
+	if !ok || errs.Errors[service.UrgencyBlockID] != "Pick an urgency from the list" {
+		t.Fatalf("response = %#v, want an urgency error", resp.Body)
+	}
+}
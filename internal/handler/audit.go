@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/slack-go/slack"
+)
+
+const (
+	auditCommand        = "audit"
+	auditJobType        = "audit.record"
+	defaultAuditEntries = 20
+	maxAuditEntries     = 45
+)
+
+func (a *IncidentApp) handleAuditCommand(cmd slack.SlashCommand, args []string) *Response {
+	if !a.permissions.IsMember(cmd.UserID, a.config.Audit.AdminUsers, a.config.Audit.AdminGroups) {
+		a.audit.Record(model.AuditEvent{
+			Action:    model.ActionAudit,
+			Outcome:   model.AuditOutcomeDenied,
+			UserID:    cmd.UserID,
+			UserName:  cmd.UserName,
+			ChannelID: cmd.ChannelID,
+			Source:    model.SourceSlashCommand,
+		})
+		return ephemeralResponse("⛔ Only bot admins can view the audit log.", nil)
+	}
+
+	limit := defaultAuditEntries
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			limit = n
+		}
+	}
+	if limit > maxAuditEntries {
+		limit = maxAuditEntries
+	}
+
+	events, err := a.audit.Recent(limit)
+	if err != nil {
+		if errors.Is(err, service.ErrAuditNotQueryable) {
+			return ephemeralResponse("The configured audit sink cannot be queried from Slack.", nil)
+		}
+		a.logger.WithError(err).Error("Failed to read audit log")
+		return ephemeralResponse("❌ Failed to read the audit log.", nil)
+	}
+
+	if len(events) == 0 {
+		return ephemeralResponse("The audit log is empty.", nil)
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType,
+			fmt.Sprintf("Last %d audit entries", len(events)), false, false)),
+	}
+	for _, event := range events {
+		blocks = append(blocks, markdownSection(auditLine(event)))
+	}
+
+	return ephemeralResponse(fmt.Sprintf("Last %d audit entries", len(events)), blocks)
+}
+
+func (a *IncidentApp) runAuditRecord(ctx context.Context, data json.RawMessage) error {
+	var event model.AuditEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("failed to decode audit job: %w", err)
+	}
+	if err := a.audit.Write(event); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+func auditLine(event model.AuditEvent) string {
+	icon := "✅"
+	switch event.Outcome {
+	case model.AuditOutcomeFailure:
+		icon = "❌"
+	case model.AuditOutcomeDenied:
+		icon = "⛔"
+	}
+
+	parts := []string{
+		fmt.Sprintf("%s `%s` *%s* %s by <@%s>", icon, event.Time.Format("2006-01-02 15:04:05"), event.Action, event.Outcome, event.UserID),
+	}
+	if event.ChannelID != "" {
+		parts = append(parts, fmt.Sprintf("in <#%s>", event.ChannelID))
+	}
+	if event.Priority != "" {
+		parts = append(parts, string(event.Priority))
+	}
+	if event.TinyID != "" {
+		parts = append(parts, fmt.Sprintf("#%s", event.TinyID))
+	} else if event.OpsGenieID != "" {
+		parts = append(parts, event.OpsGenieID)
+	}
+	if event.Error != "" {
+		parts = append(parts, fmt.Sprintf("_%s_", truncate(event.Error, 200)))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func ephemeralResponse(text string, blocks []slack.Block) *Response {
+	msg := slack.Msg{
+		ResponseType: slack.ResponseTypeEphemeral,
+		Text:         text,
+	}
+	if len(blocks) > 0 {
+		msg.Blocks = slack.Blocks{BlockSet: blocks}
+	}
+	return &Response{Body: msg}
+}
@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	escalateCommand       = "escalate"
+	escalationOptionLimit = 50
+	escalationTargetLimit = 1000
+	escalationJobType     = "escalation.run"
+)
+
+type EscalationHandler struct {
+	slackService  *service.SlackService
+	alertService  *service.AlertService
+	catalog       *service.ServiceCatalog
+	users         *service.UserDirectory
+	permissions   *service.PermissionService
+	announcements *service.AnnouncementService
+	audit         *service.AuditService
+	notify        func(userID, responseURL, text string, blocks []slack.Block)
+	enqueue       func(jobType string, payload interface{}) error
+	logger        *logrus.Logger
+}
+
+type escalationRequest struct {
+	Alert       model.AlertRef         `json:"alert"`
+	Target      model.EscalationTarget `json:"target"`
+	Note        string                 `json:"note,omitempty"`
+	UserID      string                 `json:"userId"`
+	UserName    string                 `json:"userName"`
+	ChannelID   string                 `json:"channelId"`
+	ResponseURL string                 `json:"responseUrl,omitempty"`
+	Source      string                 `json:"source"`
+}
+
+type escalationJob struct {
+	Request escalationRequest `json:"request"`
+	TinyID  string            `json:"tinyId,omitempty"`
+	Words   []string          `json:"words,omitempty"`
+}
+
+func NewEscalationHandler(
+	slackService *service.SlackService,
+	alertService *service.AlertService,
+	catalog *service.ServiceCatalog,
+	users *service.UserDirectory,
+	permissions *service.PermissionService,
+	announcements *service.AnnouncementService,
+	audit *service.AuditService,
+	logger *logrus.Logger,
+) *EscalationHandler {
+	return &EscalationHandler{
+		slackService:  slackService,
+		alertService:  alertService,
+		catalog:       catalog,
+		users:         users,
+		permissions:   permissions,
+		announcements: announcements,
+		audit:         audit,
+		logger:        logger,
+	}
+}
+
+func (h *EscalationHandler) Register(app *IncidentApp) {
+	app.RegisterActionHandler(service.EscalateActionID, h.handleEscalateButton)
+	app.RegisterOptionsHandler(service.EscalationTargetActionID, h.handleTargetOptions)
+	app.RegisterViewHandler(service.EscalateModalCallbackID, h.handleEscalateSubmission)
+	app.RegisterCommandHandler(escalateCommand, h.handleEscalateCommand)
+	app.RegisterJobHandler(escalationJobType, h.runEscalation)
+	h.notify = app.notify
+	h.enqueue = app.Enqueue
+}
+
+func (h *EscalationHandler) handleEscalateButton(payload slack.InteractionCallback, action *slack.BlockAction) error {
+	var ref model.AlertRef
+	if err := json.Unmarshal([]byte(action.Value), &ref); err != nil {
+		return fmt.Errorf("failed to decode alert reference: %w", err)
+	}
+
+	metadata := model.EscalationMetadata{
+		Alert:       ref,
+		ChannelID:   payload.Channel.ID,
+		ResponseURL: payload.ResponseURL,
+	}
+	if err := h.slackService.OpenView(payload.TriggerID, h.slackService.EscalateModal(metadata)); err != nil {
+		return err
+	}
+	if err := h.announcements.Track(ref.ID, payload); err != nil {
+		h.logger.WithError(err).WithField("alert_id", ref.ID).Warn("Failed to track the clicked alert message")
+	}
+	return nil
+}
+
+func (h *EscalationHandler) handleTargetOptions(payload slack.InteractionCallback) (*slack.OptionsResponse, error) {
+	teams, err := h.catalog.SearchTeams(payload.Value, escalationOptionLimit)
+	if err != nil {
+		return nil, err
+	}
+	escalations, err := h.catalog.SearchEscalations(payload.Value, escalationOptionLimit)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to list escalations")
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(teams)+len(escalations))
+	for _, team := range teams {
+		options = append(options, targetOption(model.EscalationTarget{Type: model.ResponderTeam, ID: team.ID, Name: team.Name}, "👥"))
+	}
+	for _, escalation := range escalations {
+		options = append(options, targetOption(escalation, "🔺"))
+	}
+
+	return &slack.OptionsResponse{Options: options}, nil
+}
+
+func targetOption(target model.EscalationTarget, icon string) *slack.OptionBlockObject {
+	return slack.NewOptionBlockObject(
+		service.EscalationTargetValue(target),
+		slack.NewTextBlockObject(slack.PlainTextType, icon+" "+truncate(target.Name, 72), false, false),
+		nil,
+	)
+}
+
+func (h *EscalationHandler) handleEscalateSubmission(payload slack.InteractionCallback) *Response {
+	var metadata model.EscalationMetadata
+	if err := json.Unmarshal([]byte(payload.View.PrivateMetadata), &metadata); err != nil {
+		h.logger.WithError(err).Warn("Failed to parse escalation metadata")
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.EscalationTargetBlockID: "This escalation form is no longer valid. Open it again.",
+		})}
+	}
+
+	values := payload.View.State.Values
+	option := values[service.EscalationTargetBlockID][service.EscalationTargetActionID].SelectedOption
+	targetType, targetID, ok := strings.Cut(option.Value, ":")
+	if !ok || targetID == "" {
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.EscalationTargetBlockID: "Pick a team or escalation",
+		})}
+	}
+
+	target := model.EscalationTarget{Type: targetType, ID: targetID, Name: targetID}
+	if option.Text != nil && option.Text.Text != "" {
+		_, name, _ := strings.Cut(option.Text.Text, " ")
+		target.Name = name
+	}
+	if metadata.Alert.Kind == model.ResultKindIncident && target.Type != model.ResponderTeam {
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.EscalationTargetBlockID: "Incidents can only be escalated to a team",
+		})}
+	}
+
+	if decision := h.permissions.Authorize(model.AccessRequest{
+		UserID:    payload.User.ID,
+		ChannelID: metadata.ChannelID,
+		Action:    model.ActionEscalate,
+		Priority:  metadata.Alert.Priority,
+	}); !decision.Allowed {
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.EscalationTargetBlockID: decision.Message,
+		})}
+	}
+
+	request := escalationRequest{
+		Alert:       metadata.Alert,
+		Target:      target,
+		Note:        strings.TrimSpace(values[service.EscalationNoteBlockID][service.EscalationNoteActionID].Value),
+		UserID:      payload.User.ID,
+		UserName:    payload.User.Name,
+		ChannelID:   metadata.ChannelID,
+		ResponseURL: metadata.ResponseURL,
+		Source:      model.SourceButton,
+	}
+	if err := h.enqueue(escalationJobType, escalationJob{Request: request}); err != nil {
+		h.logger.WithError(err).WithField("alert_id", request.Alert.ID).Error("Failed to queue escalation")
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.EscalationTargetBlockID: "Failed to escalate. Please try again.",
+		})}
+	}
+	return &Response{Body: map[string]string{"response_action": "clear"}}
+}
+
+func (h *EscalationHandler) handleEscalateCommand(cmd slack.SlashCommand, args []string) *Response {
+	if len(args) < 2 {
+		return ephemeralResponse("Usage: `/opsgenie escalate <tinyId> <team|escalation> [note]`", nil)
+	}
+
+	tinyID := strings.TrimPrefix(args[0], "#")
+	job := escalationJob{
+		Request: escalationRequest{
+			UserID:      cmd.UserID,
+			UserName:    cmd.UserName,
+			ChannelID:   cmd.ChannelID,
+			ResponseURL: cmd.ResponseURL,
+			Source:      model.SourceSlashCommand,
+		},
+		TinyID: tinyID,
+		Words:  args[1:],
+	}
+	if err := h.enqueue(escalationJobType, job); err != nil {
+		h.logger.WithError(err).WithField("tiny_id", tinyID).Error("Failed to queue escalation")
+		return ephemeralResponse(fmt.Sprintf("❌ Failed to escalate #%s. Please try again.", tinyID), nil)
+	}
+	return ephemeralResponse(fmt.Sprintf("⏳ Escalating #%s…", tinyID), nil)
+}
+
+func (h *EscalationHandler) runEscalation(ctx context.Context, data json.RawMessage) error {
+	var job escalationJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("failed to decode escalation job: %w", err)
+	}
+
+	request := job.Request
+	if job.TinyID == "" {
+		h.escalate(request)
+		return nil
+	}
+
+	result, err := h.alertService.FindByTinyID(job.TinyID)
+	if err != nil {
+		h.logger.WithError(err).WithField("tiny_id", job.TinyID).Warn("Failed to find alert to escalate")
+		message := fmt.Sprintf("Could not find alert or incident #%s.", job.TinyID)
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return nil
+	}
+
+	target, note, found, err := h.findTarget(job.Words)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to resolve escalation target")
+		h.notify(request.UserID, request.ResponseURL, "Failed to load OpsGenie teams. Please try again.",
+			errorBlocks("Failed to load OpsGenie teams. Please try again."))
+		return nil
+	}
+	if !found {
+		message := fmt.Sprintf("No OpsGenie team or escalation named %q.", strings.Join(job.Words, " "))
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return nil
+	}
+
+	if decision := h.permissions.Authorize(model.AccessRequest{
+		UserID:    request.UserID,
+		ChannelID: request.ChannelID,
+		Action:    model.ActionEscalate,
+		Priority:  result.Priority,
+	}); !decision.Allowed {
+		h.notify(request.UserID, request.ResponseURL, "⛔ "+decision.Message, nil)
+		return nil
+	}
+
+	request.Alert = result.Ref()
+	request.Target = target
+	request.Note = note
+	h.escalate(request)
+	return nil
+}
+
+func (h *EscalationHandler) findTarget(words []string) (model.EscalationTarget, string, bool, error) {
+	teams, err := h.catalog.SearchTeams("", escalationTargetLimit)
+	if err != nil {
+		return model.EscalationTarget{}, "", false, err
+	}
+	escalations, err := h.catalog.SearchEscalations("", escalationTargetLimit)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to list escalations")
+	}
+
+	targets := make([]model.EscalationTarget, 0, len(teams)+len(escalations))
+	for _, team := range teams {
+		targets = append(targets, model.EscalationTarget{Type: model.ResponderTeam, ID: team.ID, Name: team.Name})
+	}
+	targets = append(targets, escalations...)
+
+	for i := len(words); i > 0; i-- {
+		candidate := strings.Join(words[:i], " ")
+		for _, target := range targets {
+			if target.ID == candidate || strings.EqualFold(target.Name, candidate) {
+				return target, strings.Join(words[i:], " "), true, nil
+			}
+		}
+	}
+
+	return model.EscalationTarget{}, "", false, nil
+}
+
+func (h *EscalationHandler) escalate(request escalationRequest) {
+	logger := h.logger.WithFields(logrus.Fields{
+		"alert_id":    request.Alert.ID,
+		"user_id":     request.UserID,
+		"target_type": request.Target.Type,
+		"target_id":   request.Target.ID,
+	})
+
+	event := model.AuditEvent{
+		Action:     model.ActionEscalate,
+		Outcome:    model.AuditOutcomeSuccess,
+		UserID:     request.UserID,
+		UserName:   request.UserName,
+		ChannelID:  request.ChannelID,
+		Source:     request.Source,
+		Priority:   request.Alert.Priority,
+		OpsGenieID: request.Alert.ID,
+		TinyID:     request.Alert.TinyID,
+		Kind:       request.Alert.Kind,
+	}
+
+	user, _ := h.users.OpsGenieUser(request.UserID)
+	if err := h.alertService.Escalate(request.Alert, request.Target, request.Note, user); err != nil {
+		logger.WithError(err).Error("Failed to escalate alert")
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+		h.audit.Record(event)
+
+		message := fmt.Sprintf("Failed to escalate %s to %s.", request.Alert.Label(), request.Target.Name)
+		if errors.Is(err, service.ErrEscalationNotSupported) {
+			message = fmt.Sprintf("%s is an incident and can only be escalated to a team.", request.Alert.Label())
+		}
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return
+	}
+	h.audit.Record(event)
+	logger.Info("Escalated alert")
+
+	if err := h.announcements.AddResponder(request.Alert.ID, model.AddedResponder{
+		Target: request.Target,
+		UserID: request.UserID,
+	}); err != nil {
+		logger.WithError(err).Warn("Failed to update alert announcements")
+	}
+
+	message := fmt.Sprintf("🔺 Escalated %s to *%s*.", request.Alert.Label(), request.Target.Name)
+	h.notify(request.UserID, request.ResponseURL, message, []slack.Block{markdownSection(message)})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	eventKeyPrefix = "events:"
+	eventDedupeTTL = time.Hour
+	eventJobType   = "event.dispatch"
+)
+
+type EventHandlerFunc func(event slackevents.EventsAPIEvent) error
+
+func (a *IncidentApp) RegisterEventHandler(eventType string, fn EventHandlerFunc) {
+	a.eventHandlers[eventType] = append(a.eventHandlers[eventType], fn)
+}
+
+func (a *IncidentApp) HandleCallbackEvent(event slackevents.EventsAPIEvent, retryNum, retryReason string) error {
+	callback, ok := event.Data.(*slackevents.EventsAPICallbackEvent)
+	if !ok {
+		return nil
+	}
+
+	logger := a.logger.WithFields(logrus.Fields{
+		"event_id":     callback.EventID,
+		"event_type":   event.InnerEvent.Type,
+		"team_id":      event.TeamID,
+		"retry_num":    retryNum,
+		"retry_reason": retryReason,
+	})
+
+	key := eventKeyPrefix + callback.EventID
+	first, err := a.store.SetIfAbsent(key, "1", eventDedupeTTL)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record event, processing it without deduplication")
+	} else if !first {
+		logger.Info("Skipping already processed event")
+		return nil
+	}
+
+	if retryNum != "" && retryNum != "0" {
+		logger.Warn("Processing retried event")
+	}
+
+	if err := a.Enqueue(eventJobType, callback); err != nil {
+		if first {
+			if err := a.store.Delete(key); err != nil {
+				logger.WithError(err).Warn("Failed to release event for redelivery")
+			}
+		}
+		return fmt.Errorf("failed to queue event %s: %w", callback.EventID, err)
+	}
+	return nil
+}
+
+func (a *IncidentApp) runEvent(ctx context.Context, data json.RawMessage) error {
+	event, err := slackevents.ParseEvent(data, slackevents.OptionNoVerifyToken())
+	if err != nil {
+		return fmt.Errorf("failed to decode event job: %w", err)
+	}
+	a.dispatchEvent(event)
+	return nil
+}
+
+func (a *IncidentApp) dispatchEvent(event slackevents.EventsAPIEvent) {
+	if msg, ok := event.InnerEvent.Data.(*slackevents.MessageEvent); ok {
+		if msg.BotID != "" || msg.SubType == "bot_message" {
+			return
+		}
+	}
+
+	handlers := a.eventHandlers[event.InnerEvent.Type]
+	if len(handlers) == 0 {
+		a.logger.WithField("event_type", event.InnerEvent.Type).Debug("No handlers registered for event")
+		return
+	}
+
+	for _, fn := range handlers {
+		if err := fn(event); err != nil {
+			a.logger.WithError(err).WithField("event_type", event.InnerEvent.Type).Error("Event handler failed")
+		}
+	}
+}
+
+func (a *IncidentApp) registerDefaultEventHandlers() {
+	a.RegisterEventHandler(string(slackevents.AppMention), a.handleAppMention)
+}
+
+func (a *IncidentApp) handleAppMention(event slackevents.EventsAPIEvent) error {
+	mention, ok := event.InnerEvent.Data.(*slackevents.AppMentionEvent)
+	if !ok || mention.BotID != "" {
+		return nil
+	}
+
+	threadTS := mention.ThreadTimeStamp
+	if threadTS == "" {
+		threadTS = mention.TimeStamp
+	}
+
+	text := "👋 Use `/create-incident` to raise an OpsGenie incident from Slack."
+	_, err := a.slackService.PostMessage(mention.Channel, threadTS, text, nil)
+	return err
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return logger
+}
+
+func newTestApp(st store.Store) *IncidentApp {
+	logger := quietLogger()
+	jobs := queue.NewWithBackend(queue.NewMemoryBackend(), 1, 1, logger)
+	return NewIncidentApp(nil, nil, nil, nil, nil, nil, nil, nil, nil, st, jobs, &config.Config{}, logger)
+}
+
+func reactionEvent(eventID string) slackevents.EventsAPIEvent {
+	inner := json.RawMessage(`{"type":"reaction_added","user":"U1","reaction":"rotating_light","item":{"type":"message","channel":"C1","ts":"1.1"}}`)
+	return slackevents.EventsAPIEvent{
+		Type: slackevents.CallbackEvent,
+		Data: &slackevents.EventsAPICallbackEvent{
+			Type:       slackevents.CallbackEvent,
+			EventID:    eventID,
+			InnerEvent: &inner,
+		},
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.ReactionAdded),
+			Data: &slackevents.ReactionAddedEvent{},
+		},
+	}
+}
+
+func runQueued(t *testing.T, app *IncidentApp, backend *queue.MemoryBackend) int {
+	t.Helper()
+	ran := 0
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		job, err := backend.Pop(ctx)
+		cancel()
+		if err != nil {
+			return ran
+		}
+		if err := app.RunJob(context.Background(), job); err != nil {
+			t.Fatalf("RunJob(%s) error = %v", job.Type, err)
+		}
+		ran++
+	}
+}
+
+func TestHandleCallbackEventDeduplicatesAcrossInstances(t *testing.T) {
+	shared := store.NewMemoryStore()
+	backend := queue.NewMemoryBackend()
+	calls := 0
+	count := func(event slackevents.EventsAPIEvent) error {
+		if _, ok := event.InnerEvent.Data.(*slackevents.ReactionAddedEvent); !ok {
+			t.Errorf("handler got %T, want a reaction event", event.InnerEvent.Data)
+		}
+		calls++
+		return nil
+	}
+
+	first, second := newTestApp(shared), newTestApp(shared)
+	first.jobs = queue.NewWithBackend(backend, 1, 1, quietLogger())
+	second.jobs = first.jobs
+	first.RegisterEventHandler(string(slackevents.ReactionAdded), count)
+	second.RegisterEventHandler(string(slackevents.ReactionAdded), count)
+
+	if err := first.HandleCallbackEvent(reactionEvent("Ev1"), "", ""); err != nil {
+		t.Fatalf("HandleCallbackEvent() error = %v", err)
+	}
+	if err := second.HandleCallbackEvent(reactionEvent("Ev1"), "1", "http_timeout"); err != nil {
+		t.Fatalf("HandleCallbackEvent(retry) error = %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("event handled %d times before its job ran, want 0", calls)
+	}
+	if ran := runQueued(t, second, backend); ran != 1 || calls != 1 {
+		t.Fatalf("ran %d jobs handling the event %d times, want 1 and 1", ran, calls)
+	}
+
+	if err := second.HandleCallbackEvent(reactionEvent("Ev2"), "", ""); err != nil {
+		t.Fatalf("HandleCallbackEvent() error = %v", err)
+	}
+	runQueued(t, first, backend)
+	if calls != 2 {
+		t.Fatalf("new event handled %d times in total, want 2", calls)
+	}
+}
+
+type fullBackend struct{ *queue.MemoryBackend }
+
+func (fullBackend) Push(job queue.Job) error { return queue.ErrQueueFull }
+
+func TestHandleCallbackEventReleasesEventWhenQueueIsFull(t *testing.T) {
+	shared := store.NewMemoryStore()
+	app := newTestApp(shared)
+	app.jobs = queue.NewWithBackend(fullBackend{queue.NewMemoryBackend()}, 1, 1, quietLogger())
+
+	if err := app.HandleCallbackEvent(reactionEvent("Ev1"), "", ""); !errors.Is(err, queue.ErrQueueFull) {
+		t.Fatalf("HandleCallbackEvent() error = %v, want ErrQueueFull", err)
+	}
+	if _, ok, _ := shared.Get(eventKeyPrefix + "Ev1"); ok {
+		t.Fatalf("event stayed marked as processed, Slack's redelivery would be skipped")
+	}
+}
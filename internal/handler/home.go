@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	homeCreateIncidentActionID = "home_create_incident"
+
+	homeListLimit = 5
+)
+
+type HomeHandler struct {
+	openForm     func(triggerID string, metadata model.ModalMetadata, description string) error
+	slackService *service.SlackService
+	alertService *service.AlertService
+	history      *service.HistoryService
+	logger       *logrus.Logger
+}
+
+func NewHomeHandler(
+	slackService *service.SlackService,
+	alertService *service.AlertService,
+	history *service.HistoryService,
+	logger *logrus.Logger,
+) *HomeHandler {
+	return &HomeHandler{
+		slackService: slackService,
+		alertService: alertService,
+		history:      history,
+		logger:       logger,
+	}
+}
+
+func (h *HomeHandler) Register(app *IncidentApp) {
+	h.openForm = app.OpenIncidentForm
+	app.RegisterEventHandler(string(slackevents.AppHomeOpened), h.handleAppHomeOpened)
+	app.RegisterActionHandler(homeCreateIncidentActionID, h.handleCreateIncident)
+}
+
+func (h *HomeHandler) handleAppHomeOpened(event slackevents.EventsAPIEvent) error {
+	opened, ok := event.InnerEvent.Data.(*slackevents.AppHomeOpenedEvent)
+	if !ok || opened.Tab != "home" {
+		return nil
+	}
+
+	h.logger.WithField("user_id", opened.User).Debug("Publishing app home")
+
+	return h.slackService.PublishHomeView(opened.User, h.buildHomeBlocks(opened.User))
+}
+
+func (h *HomeHandler) handleCreateIncident(payload slack.InteractionCallback, action *slack.BlockAction) error {
+	metadata := model.ModalMetadata{
+		TeamDomain: payload.Team.Domain,
+		Source:     model.SourceAppHome,
+	}
+	return h.openForm(payload.TriggerID, metadata, "")
+}
+
+func (h *HomeHandler) buildHomeBlocks(userID string) []slack.Block {
+	email, err := h.slackService.GetUserEmail(userID)
+	if err != nil {
+		h.logger.WithError(err).WithField("user_id", userID).Warn("Failed to get user email")
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "OpsGenie Incidents", true, false)),
+		slack.NewActionBlock("home_actions",
+			slack.NewButtonBlockElement(homeCreateIncidentActionID, "create",
+				slack.NewTextBlockObject(slack.PlainTextType, "🚨 Create incident", true, false)).
+				WithStyle(slack.StylePrimary),
+		),
+		slack.NewDividerBlock(),
+	}
+
+	blocks = append(blocks, markdownSection("*📝 Your recent incidents*\n"+h.reportedSection(userID)))
+	blocks = append(blocks, markdownSection("*👤 Alerts you own*\n"+h.ownedSection(email)))
+	blocks = append(blocks, markdownSection("*📟 On call now*\n"+h.onCallSection(email)))
+	blocks = append(blocks, slack.NewContextBlock("home_updated",
+		slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("Last updated <!date^%d^{date_short_pretty} at {time}|%s>",
+				time.Now().Unix(), time.Now().UTC().Format(time.RFC1123)),
+			false, false),
+	))
+
+	return blocks
+}
+
+func (h *HomeHandler) reportedSection(userID string) string {
+	reported, err := h.history.RecentReported(userID, homeListLimit)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to load reported incidents")
+		return "_Could not load your recent incidents._"
+	}
+	if len(reported) == 0 {
+		return "_You haven't reported any incidents yet._"
+	}
+
+	lines := make([]string, 0, len(reported))
+	for _, incident := range reported {
+		title := incident.Title
+		if incident.URL != "" {
+			title = fmt.Sprintf("<%s|%s>", incident.URL, incident.Title)
+		}
+		lines = append(lines, fmt.Sprintf("• *%s* %s · <!date^%d^{date_short_pretty}|%s>",
+			string(incident.Priority),
+			title,
+			incident.CreatedAt.Unix(),
+			incident.CreatedAt.UTC().Format(time.RFC1123)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (h *HomeHandler) ownedSection(email string) string {
+	if email == "" {
+		return "_Your Slack email is not visible to the bot._"
+	}
+
+	query := fmt.Sprintf("status:open AND owner:%q", email)
+	alerts, err := h.alertService.ListAlerts(query, homeListLimit)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to load owned alerts")
+		return "_Could not load your alerts._"
+	}
+	if len(alerts) == 0 {
+		return "_You don't own any open alerts._"
+	}
+
+	lines := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		lines = append(lines, fmt.Sprintf("• *%s* <%s|#%s %s> · %s",
+			string(alert.Priority),
+			alert.URL,
+			alert.TinyID,
+			alert.Message,
+			alert.Status))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (h *HomeHandler) onCallSection(email string) string {
+	onCalls, err := h.alertService.GetOnCallForUser(email)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to load on-call")
+		return "_Could not load on-call schedules._"
+	}
+	if len(onCalls) == 0 {
+		return "_No on-call schedules found for your teams._"
+	}
+
+	lines := make([]string, 0, len(onCalls))
+	for _, onCall := range onCalls {
+		recipients := make([]string, 0, len(onCall.Recipients))
+		for _, recipient := range onCall.Recipients {
+			recipients = append(recipients, h.mentionForEmail(recipient))
+		}
+		if len(recipients) == 0 {
+			recipients = append(recipients, "_nobody_")
+		}
+		lines = append(lines, fmt.Sprintf("• *%s* (%s): %s",
+			onCall.TeamName,
+			onCall.ScheduleName,
+			strings.Join(recipients, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (h *HomeHandler) mentionForEmail(email string) string {
+	userID, err := h.slackService.LookupUserIDByEmail(email)
+	if err != nil {
+		return email
+	}
+	return fmt.Sprintf("<@%s>", userID)
+}
+
+func markdownSection(text string) *slack.SectionBlock {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
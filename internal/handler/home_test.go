@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func homeOpenedEvent(tab string) slackevents.EventsAPIEvent {
+	return slackevents.EventsAPIEvent{
+		Type: slackevents.CallbackEvent,
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.AppHomeOpened),
+			Data: &slackevents.AppHomeOpenedEvent{User: "U1", Tab: tab},
+		},
+	}
+}
+
+func TestHomeBlocks(t *testing.T) {
+	userInfo := map[string]interface{}{"ok": true, "user": map[string]interface{}{"id": "U1", "profile": map[string]string{"email": "jane@acme.io"}}}
+
+	tests := []struct {
+		name      string
+		userInfo  map[string]interface{}
+		reported  bool
+		opsgenie  bool
+		want      []string
+		wantQuery string
+	}{
+		{
+			name:      "user with incidents, alerts and on-call",
+			userInfo:  userInfo,
+			reported:  true,
+			opsgenie:  true,
+			wantQuery: `status:open AND owner:"jane@acme.io"`,
+			want: []string{
+				"*P1* <https://acme.app.opsgenie.com/alert/detail/a-1|Database down>",
+				"*P2* <https://acme.app.opsgenie.com/alert/detail/a-2/details|#12 Disk full> · open",
+				"*DBA* (DBA primary): <@U9>",
+			},
+		},
+		{
+			name:     "user with nothing to show",
+			userInfo: userInfo,
+			opsgenie: false,
+			want: []string{
+				"_You haven't reported any incidents yet._",
+				"_Could not load your alerts._",
+				"_Could not load on-call schedules._",
+			},
+		},
+		{
+			name:     "email not visible",
+			userInfo: map[string]interface{}{"ok": false, "error": "user_not_found"},
+			want: []string{
+				"_Your Slack email is not visible to the bot._",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.opsgenie {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				switch r.URL.Path {
+				case "/v2/alerts":
+					queries = append(queries, r.URL.Query().Get("query"))
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]string{
+						{"id": "a-2", "tinyId": "12", "message": "Disk full", "status": "open", "priority": "P2"},
+					}})
+				case "/v2/users/jane@acme.io/teams":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]string{{"id": "team-dba", "name": "DBA"}}})
+				case "/v2/schedules":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]interface{}{
+						{"id": "s-1", "name": "DBA primary", "enabled": true, "ownerTeam": map[string]string{"id": "team-dba"}},
+						{"id": "s-2", "name": "Web primary", "enabled": true, "ownerTeam": map[string]string{"id": "team-web"}},
+					}})
+				case "/v2/schedules/s-1/on-calls":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"onCallRecipients": []string{"bob@acme.io"}}})
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			fake, slackService := newFakeSlackAPI(t, map[string]interface{}{
+				"/users.info":          tt.userInfo,
+				"/users.lookupByEmail": map[string]interface{}{"ok": true, "user": map[string]string{"id": "U9"}},
+			})
+			history := service.NewHistoryService(store.NewMemoryStore())
+			if tt.reported {
+				if err := history.RecordReported("U1", &model.AlertCreationResult{
+					ID:       "a-1",
+					Title:    "Database down",
+					Priority: model.PriorityP1,
+					URL:      "https://acme.app.opsgenie.com/alert/detail/a-1",
+				}); err != nil {
+					t.Fatalf("RecordReported() error = %v", err)
+				}
+			}
+			alertService := service.NewAlertServiceWithEndpoint("key", "team", "acme", server.URL, quietLogger())
+			home := NewHomeHandler(slackService, alertService, history, quietLogger())
+
+			if err := home.handleAppHomeOpened(homeOpenedEvent("home")); err != nil {
+				t.Fatalf("handleAppHomeOpened() error = %v", err)
+			}
+
+			published := fake.find("/views.publish")
+			if len(published) != 1 {
+				t.Fatalf("published %d home views, want 1", len(published))
+			}
+			body := strings.NewReplacer(`\u003c`, "<", `\u003e`, ">", `\u0026`, "&").Replace(published[0].Body)
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Fatalf("home view is missing %q: %s", want, body)
+				}
+			}
+			if tt.wantQuery != "" && (len(queries) != 1 || queries[0] != tt.wantQuery) {
+				t.Fatalf("alert queries = %q, want [%q]", queries, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestHomeIgnoresOtherTabs(t *testing.T) {
+	fake, slackService := newFakeSlackAPI(t, nil)
+	home := NewHomeHandler(slackService, nil, nil, quietLogger())
+
+	if err := home.handleAppHomeOpened(homeOpenedEvent("messages")); err != nil {
+		t.Fatalf("handleAppHomeOpened() error = %v", err)
+	}
+	if published := fake.find("/views.publish"); len(published) != 0 {
+		t.Fatalf("published %d home views for the messages tab, want none", len(published))
+	}
+}
+
+func TestHomeCreateIncidentOpensForm(t *testing.T) {
+	fake, slackService := newFakeSlackAPI(t, nil)
+	app := newTestApp(store.NewMemoryStore())
+	app.slackService = slackService
+	NewHomeHandler(slackService, nil, nil, quietLogger()).Register(app)
+
+	app.HandleInteraction(slack.InteractionCallback{
+		Type:      slack.InteractionTypeBlockActions,
+		TriggerID: "trigger",
+		Team:      slack.Team{Domain: "acme"},
+		ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{
+			{ActionID: homeCreateIncidentActionID},
+		}},
+	})
+
+	var metadata model.ModalMetadata
+	if err := json.Unmarshal([]byte(fake.openedView(t).PrivateMetadata), &metadata); err != nil {
+		t.Fatalf("failed to decode private metadata: %v", err)
+	}
+	if metadata.Source != model.SourceAppHome || metadata.TeamDomain != "acme" {
+		t.Fatalf("metadata = %+v, want an app home form for acme", metadata)
+	}
+}
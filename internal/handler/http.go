@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+type HTTPHandler struct {
+	app           App
+	signingSecret func() string
+	logger        *logrus.Logger
+}
+
+func NewHTTPHandler(app App, signingSecret func() string, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{
+		app:           app,
+		signingSecret: signingSecret,
+		logger:        logger,
+	}
+}
+
+func (h *HTTPHandler) HandleSlashCommand(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readVerified(w, r)
+	if !ok {
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	cmd, err := slack.SlashCommandParse(r)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to parse slash command")
+		http.Error(w, "Invalid slash command", http.StatusBadRequest)
+		return
+	}
+
+	h.respond(w, h.app.HandleCommand(cmd))
+}
+
+func (h *HTTPHandler) HandleInteractivity(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readVerified(w, r)
+	if !ok {
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	var payload slack.InteractionCallback
+	if err := json.Unmarshal([]byte(r.FormValue("payload")), &payload); err != nil {
+		h.logger.WithError(err).Error("Failed to parse interaction payload")
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	h.respond(w, h.app.HandleInteraction(payload))
+}
+
+func (h *HTTPHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readVerified(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to parse event")
+		http.Error(w, "Invalid event", http.StatusBadRequest)
+		return
+	}
+
+	switch event.Type {
+	case slackevents.URLVerification:
+		var challenge slackevents.ChallengeResponse
+		if err := json.Unmarshal(body, &challenge); err != nil {
+			h.logger.WithError(err).Error("Failed to parse url verification challenge")
+			http.Error(w, "Invalid challenge", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(challenge.Challenge))
+	case slackevents.CallbackEvent:
+		if err := h.app.HandleCallbackEvent(event, r.Header.Get("X-Slack-Retry-Num"), r.Header.Get("X-Slack-Retry-Reason")); err != nil {
+			h.logger.WithError(err).Error("Failed to handle event")
+			http.Error(w, "Failed to handle event", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		h.logger.WithField("type", event.Type).Debug("Ignoring unsupported event type")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *HTTPHandler) readVerified(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read request body")
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return nil, false
+	}
+
+	if err := h.verifyRequest(r.Header, body); err != nil {
+		h.logger.WithError(err).Error("Failed to verify request")
+		http.Error(w, "Verification failed", http.StatusUnauthorized)
+		return nil, false
+	}
+	return body, true
+}
+
+func (h *HTTPHandler) verifyRequest(header http.Header, body []byte) error {
+	verifier, err := slack.NewSecretsVerifier(header, h.signingSecret())
+	if err != nil {
+		return fmt.Errorf("failed to create verifier: %w", err)
+	}
+
+	verifier.Write(body)
+	return verifier.Ensure()
+}
+
+func (h *HTTPHandler) respond(w http.ResponseWriter, resp *Response) {
+	if resp == nil {
+		resp = &Response{}
+	}
+
+	if resp.Body != nil {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp.Body); err != nil {
+			h.logger.WithError(err).Error("Failed to encode response")
+		}
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+)
+
+func signedSlackRequest(secret, body string) *http.Request {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestHTTPHandlerFollowsSigningSecretRotation(t *testing.T) {
+	secret := "old-secret"
+	h := NewHTTPHandler(newTestApp(store.NewMemoryStore()), func() string { return secret }, quietLogger())
+	body := `{"type":"url_verification","challenge":"ping"}`
+
+	recorder := httptest.NewRecorder()
+	h.HandleEvents(recorder, signedSlackRequest("old-secret", body))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ping" {
+		t.Fatalf("HandleEvents() = %d %q, want 200 ping", recorder.Code, recorder.Body.String())
+	}
+
+	secret = "new-secret"
+	recorder = httptest.NewRecorder()
+	h.HandleEvents(recorder, signedSlackRequest("old-secret", body))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("HandleEvents(old secret) = %d, want 401 after rotation", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.HandleEvents(recorder, signedSlackRequest("new-secret", body))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("HandleEvents(new secret) = %d, want 200", recorder.Code)
+	}
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	createIncidentJobType = "incident.create"
+	jobKeyPrefix          = "jobs:"
+	jobProgressTTL        = 24 * time.Hour
+)
+
+type createIncidentJob struct {
+	Alert       model.Alert `json:"alert"`
+	ChannelID   string      `json:"channelId"`
+	Source      string      `json:"source"`
+	UserID      string      `json:"userId"`
+	ResponseURL string      `json:"responseUrl,omitempty"`
+}
+
+func (a *IncidentApp) RegisterJobHandler(jobType string, fn queue.HandlerFunc) {
+	a.jobHandlers[jobType] = fn
+}
+
+func (a *IncidentApp) Enqueue(jobType string, payload interface{}) error {
+	return a.jobs.Enqueue(a.config.SlackTeamID, jobType, payload)
+}
+
+func (a *IncidentApp) RunJob(ctx context.Context, job queue.Job) error {
+	fn, ok := a.jobHandlers[job.Type]
+	if !ok {
+		return fmt.Errorf("%w %q", queue.ErrUnknownJobType, job.Type)
+	}
+
+	doneKey := jobKeyPrefix + job.ID + ":done"
+	if job.ID != "" {
+		if _, done, err := a.store.Get(doneKey); err != nil {
+			a.logger.WithError(err).WithField("job_id", job.ID).Warn("Failed to check job progress")
+		} else if done {
+			a.logger.WithFields(logrus.Fields{
+				"job_id":   job.ID,
+				"job_type": job.Type,
+			}).Info("Skipping job that already finished")
+			return nil
+		}
+	}
+
+	if err := fn(ctx, job.Payload); err != nil {
+		return err
+	}
+
+	if job.ID != "" {
+		if err := a.store.Set(doneKey, "1", jobProgressTTL); err != nil {
+			a.logger.WithError(err).WithField("job_id", job.ID).Warn("Failed to record finished job")
+		}
+	}
+	return nil
+}
+
+func createIncidentOnce(
+	ctx context.Context,
+	st store.Store,
+	incidents *service.IncidentService,
+	logger *logrus.Logger,
+	alert model.Alert,
+	source string,
+) (*model.AlertCreationResult, error) {
+	job, ok := queue.FromContext(ctx)
+	if !ok || job.ID == "" {
+		return incidents.Create(alert, source)
+	}
+
+	key := jobKeyPrefix + job.ID + ":incident"
+	if data, found, err := st.Get(key); err != nil {
+		logger.WithError(err).WithField("job_id", job.ID).Warn("Failed to check for an incident created by this job")
+	} else if found {
+		var result model.AlertCreationResult
+		if err := json.Unmarshal([]byte(data), &result); err == nil {
+			logger.WithField("job_id", job.ID).Info("Reusing incident created by an earlier run of this job")
+			return &result, nil
+		}
+	}
+
+	alert.Alias = "slack-job-" + job.ID
+	started, err := st.SetIfAbsent(jobKeyPrefix+job.ID+":creating", "1", jobProgressTTL)
+	if err != nil {
+		logger.WithError(err).WithField("job_id", job.ID).Warn("Failed to record incident creation start")
+	}
+
+	var result *model.AlertCreationResult
+	if err == nil && !started {
+		existing, found, err := incidents.Find(alert, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up incident from an earlier run of job %s: %w", job.ID, err)
+		}
+		if found {
+			logger.WithField("job_id", job.ID).Info("Found incident created by an interrupted run of this job")
+			result = existing
+		}
+	}
+	if result == nil {
+		result, err = incidents.Create(alert, source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if data, err := json.Marshal(result); err != nil {
+		logger.WithError(err).Warn("Failed to encode created incident")
+	} else if err := st.Set(key, string(data), jobProgressTTL); err != nil {
+		logger.WithError(err).WithField("job_id", job.ID).Warn("Failed to record created incident")
+	}
+	return result, nil
+}
+
+func (a *IncidentApp) registerJobHandlers() {
+	a.RegisterJobHandler(createIncidentJobType, a.runCreateIncident)
+	a.RegisterJobHandler(eventJobType, a.runEvent)
+	a.RegisterJobHandler(auditJobType, a.runAuditRecord)
+}
+
+func (a *IncidentApp) runCreateIncident(ctx context.Context, data json.RawMessage) error {
+	var job createIncidentJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("failed to decode incident job: %w", err)
+	}
+
+	alert := job.Alert
+	alert.ChannelID = job.ChannelID
+
+	result, err := createIncidentOnce(ctx, a.store, a.incidents, a.logger, alert, job.Source)
+	if err != nil {
+		a.logger.WithError(err).WithFields(logrus.Fields{
+			"user_id":  job.UserID,
+			"priority": alert.Priority,
+		}).Error("Failed to create alert")
+		a.notify(job.UserID, job.ResponseURL, "Failed to create incident. Please try again.", errorBlocks("Failed to create incident. Please try again."))
+		return nil
+	}
+
+	if err := a.history.RecordReported(job.UserID, result); err != nil {
+		a.logger.WithError(err).Error("Failed to record reported incident")
+	}
+
+	text := "Incident created successfully!"
+	if result.Pending {
+		text = "Incident is being processed."
+	}
+	channel := createIncidentChannel(a.channels, a.logger, alert, result)
+	a.notifyCreated(job.UserID, job.ResponseURL, text, result, channel)
+	return nil
+}
+
+func (a *IncidentApp) notifyCreated(userID, responseURL, text string, result *model.AlertCreationResult, channel *model.IncidentChannel) {
+	if result.Pending || a.announcements == nil {
+		a.notify(userID, responseURL, text, successBlocks(result, channel))
+		return
+	}
+
+	if responseURL != "" {
+		blocks := successBlocks(result, channel)
+		err := a.slackService.SendResponse(responseURL, text, blocks)
+		if err == nil {
+			if err := a.announcements.RememberEphemeral(result.ID, userID, text, blocks); err != nil {
+				a.logger.WithError(err).WithField("alert_id", result.ID).Warn("Failed to remember the incident confirmation")
+			}
+			return
+		}
+		a.logger.WithError(err).Warn("Failed to post to response_url, sending a direct message instead")
+	}
+
+	if _, err := a.announcements.Post(result.Ref(), userID, "", text, resultBlocks(result, channel)); err != nil {
+		a.logger.WithError(err).WithField("user_id", userID).Error("Failed to notify user")
+	}
+}
+
+func (a *IncidentApp) notify(userID, responseURL, text string, blocks []slack.Block) {
+	if responseURL != "" {
+		err := a.slackService.SendResponse(responseURL, text, blocks)
+		if err == nil {
+			return
+		}
+		a.logger.WithError(err).Warn("Failed to post to response_url, sending a direct message instead")
+	}
+
+	if err := a.slackService.SendMessage(userID, text, blocks); err != nil {
+		a.logger.WithError(err).WithField("user_id", userID).Error("Failed to notify user")
+	}
+}
@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/audit"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+func TestIncidentAppRunJob(t *testing.T) {
+	app := newTestApp(store.NewMemoryStore())
+
+	var got string
+	app.RegisterJobHandler("test.echo", func(ctx context.Context, payload json.RawMessage) error {
+		return json.Unmarshal(payload, &got)
+	})
+
+	if err := app.RunJob(context.Background(), queue.Job{Type: "test.echo", Payload: json.RawMessage(`"hello"`)}); err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+	if got != "hello" {
+		t.Fatalf("handler got %q, want hello", got)
+	}
+
+	err := app.RunJob(context.Background(), queue.Job{Type: "test.missing"})
+	if !errors.Is(err, queue.ErrUnknownJobType) {
+		t.Fatalf("RunJob(unknown) error = %v, want ErrUnknownJobType", err)
+	}
+}
+
+func TestWorkspaceRouterRunsJobsForWorkspacesNotBuiltYet(t *testing.T) {
+	workspaces := service.NewWorkspaceService(nil, store.NewMemoryStore(), quietLogger())
+	for _, workspace := range []model.Workspace{
+		{TeamID: "T1", BotToken: "xoxb-1", OpsGenieAPIKey: "key-1", OpsGenieTeamID: "team-1"},
+		{TeamID: "T2", BotToken: "xoxb-2"},
+	} {
+		if err := workspaces.Save(workspace); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	global := &config.Config{OpsGenieAPIKey: "global-key", OpsGenieTeamID: "global-team"}
+	var ranFor []string
+	router := NewWorkspaceRouter(workspaces, func(workspace *model.Workspace) (*IncidentApp, error) {
+		app := newTestApp(store.NewMemoryStore())
+		app.config = global.ForWorkspace(*workspace)
+		app.RegisterJobHandler("test.job", func(ctx context.Context, payload json.RawMessage) error {
+			ranFor = append(ranFor, app.config.SlackTeamID)
+			return nil
+		})
+		return app, nil
+	}, quietLogger())
+
+	if err := router.RunJob(context.Background(), queue.Job{TeamID: "T1", Type: "test.job"}); err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+	if len(ranFor) != 1 || ranFor[0] != "T1" {
+		t.Fatalf("job ran for %v, want [T1]", ranFor)
+	}
+
+	if err := router.RunJob(context.Background(), queue.Job{TeamID: "T_GONE", Type: "test.job"}); err != nil {
+		t.Fatalf("RunJob(uninstalled) error = %v, want the job dropped", err)
+	}
+	if err := router.RunJob(context.Background(), queue.Job{TeamID: "T2", Type: "test.job"}); err != nil {
+		t.Fatalf("RunJob(not connected) error = %v, want the job dropped", err)
+	}
+	if len(ranFor) != 1 {
+		t.Fatalf("job ran for %v, want only T1", ranFor)
+	}
+}
+
+func TestWorkspaceRouterRefusesCommandsUntilOpsGenieIsConnected(t *testing.T) {
+	workspaces := service.NewWorkspaceService(nil, store.NewMemoryStore(), quietLogger())
+	if err := workspaces.Save(model.Workspace{TeamID: "T2", BotToken: "xoxb-2"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	global := &config.Config{OpsGenieAPIKey: "global-key", OpsGenieTeamID: "global-team"}
+	router := NewWorkspaceRouter(workspaces, func(workspace *model.Workspace) (*IncidentApp, error) {
+		app := newTestApp(store.NewMemoryStore())
+		app.config = global.ForWorkspace(*workspace)
+		if app.config.OpsGenieAPIKey != "" {
+			t.Fatalf("workspace %s got the global OpsGenie key", workspace.TeamID)
+		}
+		app.RegisterCommandHandler("list", func(cmd slack.SlashCommand, args []string) *Response {
+			t.Fatal("command ran for a workspace without an OpsGenie connection")
+			return nil
+		})
+		return app, nil
+	}, quietLogger())
+
+	resp := router.HandleCommand(slack.SlashCommand{TeamID: "T2", UserID: "U1", Command: opsgenieCommand, Text: "list"})
+	msg, ok := resp.Body.(slack.Msg)
+	if !ok || msg.Text != notConnectedMessage {
+		t.Fatalf("HandleCommand() body = %#v, want the connect prompt", resp.Body)
+	}
+}
+
+func TestAssignCommandQueuesJob(t *testing.T) {
+	logger := quietLogger()
+	backend := queue.NewMemoryBackend()
+	app := NewIncidentApp(nil, nil, nil, nil, nil, nil, nil, nil, nil, store.NewMemoryStore(),
+		queue.NewWithBackend(backend, 1, 1, logger), &config.Config{SlackTeamID: "T1"}, logger)
+	NewOwnershipHandler(nil, nil, nil, nil, nil, logger).Register(app)
+
+	resp := app.HandleCommand(slack.SlashCommand{
+		TeamID:      "T1",
+		UserID:      "U1",
+		Command:     opsgenieCommand,
+		Text:        "assign #42 me",
+		ResponseURL: "https://hooks.slack.com/commands/1",
+	})
+	msg, ok := resp.Body.(slack.Msg)
+	if !ok || !strings.HasPrefix(msg.Text, "⏳") {
+		t.Fatalf("HandleCommand() body = %#v, want an acknowledgement", resp.Body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	job, err := backend.Pop(ctx)
+	if err != nil {
+		t.Fatalf("Pop() error = %v, want a queued job", err)
+	}
+	if job.Type != assignJobType || job.TeamID != "T1" {
+		t.Fatalf("queued %s for %s, want %s for T1", job.Type, job.TeamID, assignJobType)
+	}
+
+	var payload assignJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if payload.TinyID != "42" || payload.Request.OwnerID != "U1" || payload.Request.ResponseURL == "" {
+		t.Fatalf("queued payload = %+v", payload)
+	}
+}
+
+func TestIncidentAppRunJobSkipsRedeliveredJobs(t *testing.T) {
+	app := newTestApp(store.NewMemoryStore())
+
+	runs := 0
+	fail := true
+	app.RegisterJobHandler("test.once", func(ctx context.Context, payload json.RawMessage) error {
+		runs++
+		if fail {
+			return errors.New("opsgenie unavailable")
+		}
+		return nil
+	})
+
+	job := queue.Job{ID: "job-1", Type: "test.once"}
+	if err := app.RunJob(context.Background(), job); err == nil {
+		t.Fatalf("RunJob() error = nil, want the handler error")
+	}
+
+	fail = false
+	for i := 0; i < 2; i++ {
+		if err := app.RunJob(context.Background(), job); err != nil {
+			t.Fatalf("RunJob() error = %v", err)
+		}
+	}
+	if runs != 2 {
+		t.Fatalf("handler ran %d times, want 2: a failed run is retried, a finished one is not", runs)
+	}
+}
+
+func TestAuditEventsAreWrittenByAJob(t *testing.T) {
+	logger := quietLogger()
+	backend := queue.NewMemoryBackend()
+	sink := audit.NewStoreSink(store.NewMemoryStore())
+	auditService := service.NewAuditService(sink, "T1", logger)
+	app := NewIncidentApp(nil, nil, nil, nil, nil, nil, nil, nil, auditService, store.NewMemoryStore(),
+		queue.NewWithBackend(backend, 1, 1, logger), &config.Config{SlackTeamID: "T1"}, logger)
+
+	auditService.Record(model.AuditEvent{Action: model.ActionAudit, Outcome: model.AuditOutcomeDenied, UserID: "U1"})
+
+	if events, _ := sink.Recent("", 10); len(events) != 0 {
+		t.Fatalf("audit event written before its job ran: %+v", events)
+	}
+	if ran := runQueued(t, app, backend); ran != 1 {
+		t.Fatalf("ran %d jobs, want 1 audit job", ran)
+	}
+
+	events, err := sink.Recent("T1", 10)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(events) != 1 || events[0].UserID != "U1" || events[0].TeamID != "T1" {
+		t.Fatalf("audit events = %+v, want the denied request for T1", events)
+	}
+}
+
+func TestCreateIncidentOnceFindsIncidentFromInterruptedRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		interrupted bool
+		existing    bool
+		wantCreates int
+		wantID      string
+	}{
+		{"first run", false, false, 1, "incident-new"},
+		{"interrupted run created the incident", true, true, 0, "incident-old"},
+		{"interrupted run never reached OpsGenie", true, false, 1, "incident-new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var creates int
+			var createdTags []string
+			var searches []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/v1/incidents":
+					searches = append(searches, r.URL.Query().Get("query"))
+					data := []map[string]string{}
+					if tt.existing {
+						data = append(data, map[string]string{"id": "incident-old", "tinyId": "3", "message": "Database down", "priority": "P1"})
+					}
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+				case r.Method == http.MethodPost && r.URL.Path == "/v1/incidents/create":
+					creates++
+					var incident model.Incident
+					json.NewDecoder(r.Body).Decode(&incident)
+					createdTags = incident.Tags
+					w.WriteHeader(http.StatusAccepted)
+					json.NewEncoder(w).Encode(map[string]string{"requestId": "req-1"})
+				case r.URL.Path == "/v1/incidents/requests/req-1":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"success": true, "incidentId": "incident-new"}})
+				case r.URL.Path == "/v1/incidents/incident-new":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"id": "incident-new", "tinyId": "4"}})
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			logger := quietLogger()
+			st := store.NewMemoryStore()
+			alertService := service.NewAlertServiceWithEndpoint("key", "team", "", server.URL, logger)
+			incidents := service.NewIncidentService(config.IncidentConfig{Priorities: []string{"P1"}}, alertService, nil,
+				service.NewAuditService(audit.NewStoreSink(st), "", logger), logger)
+
+			if tt.interrupted {
+				if err := st.Set(jobKeyPrefix+"job-1:creating", "1", time.Hour); err != nil {
+					t.Fatalf("Set() error = %v", err)
+				}
+			}
+
+			var result *model.AlertCreationResult
+			jobs := queue.NewWithBackend(queue.NewMemoryBackend(), 1, 1, logger)
+			jobs.SetRunner(func(ctx context.Context, job queue.Job) error {
+				var err error
+				result, err = createIncidentOnce(ctx, st, incidents, logger,
+					model.Alert{Title: "Database down", Priority: model.PriorityP1, Tags: []string{"db"}}, model.SourceSlashCommand)
+				return err
+			})
+			if err := jobs.Process(context.Background(), queue.Job{ID: "job-1", Type: createIncidentJobType}); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if result == nil || result.ID != tt.wantID {
+				t.Fatalf("result = %+v, want %s", result, tt.wantID)
+			}
+			if creates != tt.wantCreates {
+				t.Fatalf("created %d incidents, want %d", creates, tt.wantCreates)
+			}
+			if !tt.interrupted && len(searches) != 0 {
+				t.Fatalf("searches = %q, want none on the first run", searches)
+			}
+			if tt.interrupted && (len(searches) != 1 || searches[0] != `tag:"slack-job-job-1"`) {
+				t.Fatalf("searches = %q, want one search for the job tag", searches)
+			}
+			if creates > 0 && strings.Join(createdTags, ",") != "db,slack-job-job-1" {
+				t.Fatalf("created incident tags = %q, want the job tag added", createdTags)
+			}
+
+			if _, found, _ := st.Get(jobKeyPrefix + "job-1:incident"); !found {
+				t.Fatal("result of the job was not recorded")
+			}
+		})
+	}
+}
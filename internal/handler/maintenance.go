@@ -0,0 +1,635 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	maintenanceCommand      = "maintenance"
+	maintenanceOptionLimit  = 100
+	maintenanceEntityLimit  = 1000
+	maintenanceStartJobType = "maintenance.start"
+	maintenanceStopJobType  = "maintenance.stop"
+	maintenanceListJobType  = "maintenance.list"
+	maintenanceUsage        = "Usage: `/opsgenie maintenance start <duration> [--team <team>] [--rule <policy|integration>]...`, `/opsgenie maintenance stop [id]` or `/opsgenie maintenance list`"
+)
+
+type MaintenanceHandler struct {
+	slackService *service.SlackService
+	maintenance  *service.MaintenanceService
+	catalog      *service.ServiceCatalog
+	permissions  *service.PermissionService
+	audit        *service.AuditService
+	notify       func(userID, responseURL, text string, blocks []slack.Block)
+	enqueue      func(jobType string, payload interface{}) error
+	logger       *logrus.Logger
+}
+
+type maintenanceRequest struct {
+	Maintenance model.Maintenance `json:"maintenance"`
+	UserID      string            `json:"userId"`
+	UserName    string            `json:"userName"`
+	ChannelID   string            `json:"channelId"`
+	AnnounceIn  string            `json:"announceIn,omitempty"`
+	ResponseURL string            `json:"responseUrl,omitempty"`
+	Source      string            `json:"source"`
+}
+
+type maintenanceStartJob struct {
+	Request  maintenanceRequest `json:"request"`
+	Duration time.Duration      `json:"duration"`
+	Team     []string           `json:"team,omitempty"`
+	Rules    []string           `json:"rules,omitempty"`
+}
+
+type maintenanceStopJob struct {
+	Request maintenanceRequest `json:"request"`
+	ID      string             `json:"id,omitempty"`
+}
+
+func NewMaintenanceHandler(
+	slackService *service.SlackService,
+	maintenance *service.MaintenanceService,
+	catalog *service.ServiceCatalog,
+	permissions *service.PermissionService,
+	audit *service.AuditService,
+	logger *logrus.Logger,
+) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		slackService: slackService,
+		maintenance:  maintenance,
+		catalog:      catalog,
+		permissions:  permissions,
+		audit:        audit,
+		logger:       logger,
+	}
+}
+
+func (h *MaintenanceHandler) Register(app *IncidentApp) {
+	app.RegisterCommandHandler(maintenanceCommand, h.handleMaintenanceCommand)
+	app.RegisterOptionsHandler(service.MaintenanceRulesActionID, h.handleRuleOptions)
+	app.RegisterViewHandler(service.MaintenanceModalCallbackID, h.handleMaintenanceSubmission)
+	app.RegisterActionHandler(service.StopMaintenanceActionID, h.handleStopButton)
+	app.RegisterJobHandler(maintenanceStartJobType, h.runStart)
+	app.RegisterJobHandler(maintenanceStopJobType, h.runStop)
+	app.RegisterJobHandler(maintenanceListJobType, h.runList)
+	h.notify = app.notify
+	h.enqueue = app.Enqueue
+}
+
+func (h *MaintenanceHandler) handleMaintenanceCommand(cmd slack.SlashCommand, args []string) *Response {
+	if len(args) == 0 {
+		return ephemeralResponse(maintenanceUsage, nil)
+	}
+
+	switch args[0] {
+	case "start":
+		return h.handleStartCommand(cmd, args[1:])
+	case "stop":
+		return h.handleStopCommand(cmd, args[1:])
+	case "list":
+		return h.queueCommand(maintenanceListJobType, maintenanceRequest{
+			UserID:      cmd.UserID,
+			UserName:    cmd.UserName,
+			ChannelID:   cmd.ChannelID,
+			ResponseURL: cmd.ResponseURL,
+			Source:      model.SourceSlashCommand,
+		}, nil)
+	default:
+		return ephemeralResponse(maintenanceUsage, nil)
+	}
+}
+
+func (h *MaintenanceHandler) handleStartCommand(cmd slack.SlashCommand, args []string) *Response {
+	if len(args) == 0 {
+		return ephemeralResponse(maintenanceUsage, nil)
+	}
+	duration, err := parseMaintenanceDuration(args[0])
+	if err != nil {
+		return ephemeralResponse(fmt.Sprintf("%q is not a valid duration. Use something like `30m`, `2h` or `1d`.", args[0]), nil)
+	}
+	flags, err := parseMaintenanceFlags(args[1:])
+	if err != nil {
+		return ephemeralResponse(fmt.Sprintf("Invalid options: %s.\n%s", err, maintenanceUsage), nil)
+	}
+
+	access := model.AccessRequest{UserID: cmd.UserID, ChannelID: cmd.ChannelID, Action: model.ActionMaintenance}
+	if decision := h.permissions.Authorize(access); !decision.Allowed {
+		return ephemeralResponse("⛔ "+decision.Message, nil)
+	}
+
+	request := maintenanceRequest{
+		Maintenance: model.Maintenance{Description: fmt.Sprintf("Started from Slack by %s", cmd.UserName)},
+		UserID:      cmd.UserID,
+		UserName:    cmd.UserName,
+		ChannelID:   cmd.ChannelID,
+		AnnounceIn:  cmd.ChannelID,
+		ResponseURL: cmd.ResponseURL,
+		Source:      model.SourceSlashCommand,
+	}
+	if len(flags["rule"]) > 0 {
+		job := maintenanceStartJob{Request: request, Duration: duration, Team: flags["team"], Rules: flags["rule"]}
+		return h.queueCommand(maintenanceStartJobType, job, ephemeralResponse("⏳ Starting maintenance…", nil))
+	}
+
+	teamID, found, err := h.findTeam(flags["team"])
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list teams")
+		return ephemeralResponse("❌ Failed to load OpsGenie teams. Please try again.", nil)
+	}
+	if !found {
+		return ephemeralResponse(fmt.Sprintf("❌ No OpsGenie team named %q.", strings.Join(flags["team"], " ")), nil)
+	}
+
+	metadata := model.MaintenanceMetadata{TeamID: teamID, ChannelID: cmd.ChannelID, ResponseURL: cmd.ResponseURL}
+	if err := h.slackService.OpenView(cmd.TriggerID, h.slackService.MaintenanceModal(metadata, args[0])); err != nil {
+		h.logger.WithError(err).Error("Failed to open maintenance modal")
+		return ephemeralResponse("❌ Sorry, something went wrong while opening the maintenance form. Please try again.", nil)
+	}
+	return &Response{}
+}
+
+func (h *MaintenanceHandler) handleStopCommand(cmd slack.SlashCommand, args []string) *Response {
+	access := model.AccessRequest{UserID: cmd.UserID, ChannelID: cmd.ChannelID, Action: model.ActionMaintenance}
+	if decision := h.permissions.Authorize(access); !decision.Allowed {
+		return ephemeralResponse("⛔ "+decision.Message, nil)
+	}
+
+	job := maintenanceStopJob{
+		Request: maintenanceRequest{
+			UserID:      cmd.UserID,
+			UserName:    cmd.UserName,
+			ChannelID:   cmd.ChannelID,
+			ResponseURL: cmd.ResponseURL,
+			Source:      model.SourceSlashCommand,
+		},
+	}
+	if len(args) > 0 {
+		job.ID = args[0]
+	}
+	return h.queueCommand(maintenanceStopJobType, job, ephemeralResponse("⏳ Ending maintenance…", nil))
+}
+
+func (h *MaintenanceHandler) queueCommand(jobType string, payload interface{}, resp *Response) *Response {
+	if err := h.enqueue(jobType, payload); err != nil {
+		h.logger.WithError(err).WithField("job_type", jobType).Error("Failed to queue maintenance job")
+		return ephemeralResponse("❌ Something went wrong. Please try again.", nil)
+	}
+	if resp == nil {
+		return &Response{}
+	}
+	return resp
+}
+
+func (h *MaintenanceHandler) runList(ctx context.Context, data json.RawMessage) error {
+	var request maintenanceRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return fmt.Errorf("failed to decode maintenance list job: %w", err)
+	}
+
+	windows, err := h.maintenance.List()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list maintenance windows")
+		message := "Failed to load maintenance windows from OpsGenie."
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return nil
+	}
+	if len(windows) == 0 {
+		h.notify(request.UserID, request.ResponseURL, "There are no active or planned maintenance windows.", nil)
+		return nil
+	}
+
+	message := fmt.Sprintf("%d maintenance windows", len(windows))
+	h.notify(request.UserID, request.ResponseURL, message, maintenanceList(windows))
+	return nil
+}
+
+func (h *MaintenanceHandler) runStart(ctx context.Context, data json.RawMessage) error {
+	var job maintenanceStartJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("failed to decode maintenance start job: %w", err)
+	}
+
+	request := job.Request
+	if len(job.Rules) > 0 {
+		teamID, found, err := h.findTeam(job.Team)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to list teams")
+			message := "Failed to load OpsGenie teams. Please try again."
+			h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+			return nil
+		}
+		if !found {
+			message := fmt.Sprintf("No OpsGenie team named %q.", strings.Join(job.Team, " "))
+			h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+			return nil
+		}
+
+		rules, missing, err := h.findRules(teamID, job.Rules)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to list policies and integrations")
+			message := "Failed to load OpsGenie policies and integrations. Please try again."
+			h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+			return nil
+		}
+		if missing != "" {
+			message := fmt.Sprintf("No OpsGenie policy or integration named %q.", missing)
+			h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+			return nil
+		}
+		request.Maintenance.Rules = rules
+	}
+
+	now := time.Now()
+	request.Maintenance.StartDate = now
+	request.Maintenance.EndDate = now.Add(job.Duration)
+	h.start(request)
+	return nil
+}
+
+func (h *MaintenanceHandler) runStop(ctx context.Context, data json.RawMessage) error {
+	var job maintenanceStopJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("failed to decode maintenance stop job: %w", err)
+	}
+
+	request := job.Request
+	id := job.ID
+	if id == "" {
+		windows, err := h.activeWindows()
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to list maintenance windows")
+			message := "Failed to load maintenance windows from OpsGenie."
+			h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+			return nil
+		}
+		switch len(windows) {
+		case 0:
+			h.notify(request.UserID, request.ResponseURL, "No maintenance window is active.", nil)
+			return nil
+		case 1:
+			id = windows[0].ID
+		default:
+			message := "Several maintenance windows are active. Pass the ID of the one to end:"
+			h.notify(request.UserID, request.ResponseURL, message, append([]slack.Block{markdownSection(message)}, maintenanceList(windows)...))
+			return nil
+		}
+	}
+
+	h.stop(id, request)
+	return nil
+}
+
+func (h *MaintenanceHandler) handleRuleOptions(payload slack.InteractionCallback) (*slack.OptionsResponse, error) {
+	var metadata model.MaintenanceMetadata
+	if payload.View.PrivateMetadata != "" {
+		if err := json.Unmarshal([]byte(payload.View.PrivateMetadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse maintenance metadata: %w", err)
+		}
+	}
+
+	entities, err := h.catalog.SearchMaintenanceEntities(metadata.TeamID, payload.Value, maintenanceOptionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(entities))
+	for _, entity := range entities {
+		options = append(options, slack.NewOptionBlockObject(
+			service.MaintenanceEntityValue(entity),
+			slack.NewTextBlockObject(slack.PlainTextType, service.MaintenanceEntityIcon(entity.Type)+" "+truncate(entity.Name, 72), false, false),
+			nil,
+		))
+	}
+	return &slack.OptionsResponse{Options: options}, nil
+}
+
+func (h *MaintenanceHandler) handleMaintenanceSubmission(payload slack.InteractionCallback) *Response {
+	var metadata model.MaintenanceMetadata
+	if err := json.Unmarshal([]byte(payload.View.PrivateMetadata), &metadata); err != nil {
+		h.logger.WithError(err).Warn("Failed to parse maintenance metadata")
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.MaintenanceDurationBlockID: "This maintenance form is no longer valid. Open it again.",
+		})}
+	}
+
+	values := payload.View.State.Values
+	duration, err := parseMaintenanceDuration(values[service.MaintenanceDurationBlockID][service.MaintenanceDurationActionID].Value)
+	if err != nil {
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.MaintenanceDurationBlockID: "Use a duration like 30m, 2h or 1d",
+		})}
+	}
+
+	policyState := values[service.MaintenancePolicyStateBlockID][service.MaintenancePolicyStateActionID].SelectedOption.Value
+	if policyState == "" {
+		policyState = model.MaintenanceStateEnabled
+	}
+
+	var rules []model.MaintenanceRule
+	for _, option := range values[service.MaintenanceRulesBlockID][service.MaintenanceRulesActionID].SelectedOptions {
+		entityType, entityID, ok := strings.Cut(option.Value, ":")
+		if !ok || entityID == "" {
+			continue
+		}
+		entity := model.MaintenanceEntity{Type: entityType, ID: entityID, Name: entityID}
+		if option.Text != nil && option.Text.Text != "" {
+			_, name, _ := strings.Cut(option.Text.Text, " ")
+			entity.Name = name
+		}
+		rules = append(rules, maintenanceRule(entity, policyState))
+	}
+	if len(rules) == 0 {
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.MaintenanceRulesBlockID: "Pick at least one policy or integration",
+		})}
+	}
+
+	if decision := h.permissions.Authorize(model.AccessRequest{
+		UserID:    payload.User.ID,
+		ChannelID: metadata.ChannelID,
+		Action:    model.ActionMaintenance,
+	}); !decision.Allowed {
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.MaintenanceRulesBlockID: decision.Message,
+		})}
+	}
+
+	description := strings.TrimSpace(values[service.MaintenanceDescriptionBlockID][service.MaintenanceDescriptionActionID].Value)
+	if description == "" {
+		description = fmt.Sprintf("Started from Slack by %s", payload.User.Name)
+	}
+
+	request := maintenanceRequest{
+		Maintenance: model.Maintenance{
+			Description: description,
+			Rules:       rules,
+		},
+		UserID:      payload.User.ID,
+		UserName:    payload.User.Name,
+		ChannelID:   metadata.ChannelID,
+		AnnounceIn:  values[service.MaintenanceChannelBlockID][service.MaintenanceChannelActionID].SelectedConversation,
+		ResponseURL: metadata.ResponseURL,
+		Source:      model.SourceSlashCommand,
+	}
+	if err := h.enqueue(maintenanceStartJobType, maintenanceStartJob{Request: request, Duration: duration}); err != nil {
+		h.logger.WithError(err).Error("Failed to queue maintenance start")
+		return &Response{Body: slack.NewErrorsViewSubmissionResponse(map[string]string{
+			service.MaintenanceRulesBlockID: "Failed to start maintenance. Please try again.",
+		})}
+	}
+	return &Response{Body: map[string]string{"response_action": "clear"}}
+}
+
+func (h *MaintenanceHandler) handleStopButton(payload slack.InteractionCallback, action *slack.BlockAction) error {
+	if decision := h.permissions.Authorize(model.AccessRequest{
+		UserID:    payload.User.ID,
+		ChannelID: payload.Channel.ID,
+		Action:    model.ActionMaintenance,
+	}); !decision.Allowed {
+		h.notify(payload.User.ID, payload.ResponseURL, "⛔ "+decision.Message, nil)
+		return nil
+	}
+
+	job := maintenanceStopJob{
+		Request: maintenanceRequest{
+			UserID:      payload.User.ID,
+			UserName:    payload.User.Name,
+			ChannelID:   payload.Channel.ID,
+			ResponseURL: payload.ResponseURL,
+			Source:      model.SourceButton,
+		},
+		ID: action.Value,
+	}
+	if err := h.enqueue(maintenanceStopJobType, job); err != nil {
+		h.logger.WithError(err).WithField("maintenance_id", action.Value).Error("Failed to queue maintenance stop")
+		message := fmt.Sprintf("Failed to end maintenance window `%s`.", action.Value)
+		h.notify(payload.User.ID, payload.ResponseURL, message, errorBlocks(message))
+	}
+	return nil
+}
+
+func (h *MaintenanceHandler) start(request maintenanceRequest) {
+	logger := h.logger.WithFields(logrus.Fields{
+		"user_id":    request.UserID,
+		"channel_id": request.ChannelID,
+		"rules":      len(request.Maintenance.Rules),
+	})
+
+	event := model.AuditEvent{
+		Action:    model.AuditMaintenanceStart,
+		Outcome:   model.AuditOutcomeSuccess,
+		UserID:    request.UserID,
+		UserName:  request.UserName,
+		ChannelID: request.ChannelID,
+		Source:    request.Source,
+	}
+
+	created, err := h.maintenance.Start(request.Maintenance)
+	if err != nil {
+		logger.WithError(err).Error("Failed to start maintenance")
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+		h.audit.Record(event)
+
+		message := "Failed to start the maintenance window in OpsGenie."
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return
+	}
+	event.OpsGenieID = created.ID
+	h.audit.Record(event)
+	logger.WithField("maintenance_id", created.ID).Info("Started maintenance")
+
+	message := fmt.Sprintf("🛠️ Maintenance window `%s` is active until %s. End it early with `/opsgenie maintenance stop %s`.",
+		created.ID, slackDate(created.EndDate), created.ID)
+	if request.AnnounceIn != "" {
+		if err := h.maintenance.Announce(created, request.AnnounceIn, request.UserID); err != nil {
+			logger.WithError(err).Warn("Failed to announce maintenance")
+			message += fmt.Sprintf("\nIt could not be announced in <#%s>; invite the bot to the channel to announce there.", request.AnnounceIn)
+		}
+	}
+	h.notify(request.UserID, request.ResponseURL, message, []slack.Block{markdownSection(message)})
+}
+
+func (h *MaintenanceHandler) stop(id string, request maintenanceRequest) {
+	logger := h.logger.WithFields(logrus.Fields{
+		"maintenance_id": id,
+		"user_id":        request.UserID,
+	})
+
+	event := model.AuditEvent{
+		Action:     model.AuditMaintenanceStop,
+		Outcome:    model.AuditOutcomeSuccess,
+		UserID:     request.UserID,
+		UserName:   request.UserName,
+		ChannelID:  request.ChannelID,
+		Source:     request.Source,
+		OpsGenieID: id,
+	}
+
+	if err := h.maintenance.Stop(id, request.UserID); err != nil {
+		logger.WithError(err).Error("Failed to stop maintenance")
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+		h.audit.Record(event)
+
+		message := fmt.Sprintf("Failed to end maintenance window `%s`.", id)
+		if service.IsNotFound(err) {
+			message = fmt.Sprintf("No maintenance window with ID `%s`.", id)
+		}
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return
+	}
+	h.audit.Record(event)
+	logger.Info("Stopped maintenance")
+
+	if request.Source == model.SourceSlashCommand {
+		message := fmt.Sprintf("✅ Ended maintenance window `%s`.", id)
+		h.notify(request.UserID, request.ResponseURL, message, []slack.Block{markdownSection(message)})
+	}
+}
+
+func (h *MaintenanceHandler) activeWindows() ([]model.Maintenance, error) {
+	windows, err := h.maintenance.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var active []model.Maintenance
+	for _, window := range windows {
+		if window.Status == model.MaintenanceStatusActive {
+			active = append(active, window)
+		}
+	}
+	return active, nil
+}
+
+func (h *MaintenanceHandler) findTeam(words []string) (string, bool, error) {
+	if len(words) == 0 {
+		return "", true, nil
+	}
+
+	name := strings.Join(words, " ")
+	teams, err := h.catalog.SearchTeams("", maintenanceEntityLimit)
+	if err != nil {
+		return "", false, err
+	}
+	for _, team := range teams {
+		if team.ID == name || strings.EqualFold(team.Name, name) {
+			return team.ID, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (h *MaintenanceHandler) findRules(teamID string, names []string) ([]model.MaintenanceRule, string, error) {
+	entities, err := h.catalog.SearchMaintenanceEntities(teamID, "", maintenanceEntityLimit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rules := make([]model.MaintenanceRule, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, entity := range entities {
+			if entity.ID == name || strings.EqualFold(entity.Name, name) {
+				rules = append(rules, maintenanceRule(entity, model.MaintenanceStateEnabled))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, name, nil
+		}
+	}
+	return rules, "", nil
+}
+
+func maintenanceRule(entity model.MaintenanceEntity, policyState string) model.MaintenanceRule {
+	state := model.MaintenanceStateDisabled
+	if entity.Type == model.MaintenancePolicy {
+		state = policyState
+	}
+	return model.MaintenanceRule{State: state, Entity: entity}
+}
+
+func maintenanceList(windows []model.Maintenance) []slack.Block {
+	lines := make([]string, 0, len(windows))
+	for _, window := range windows {
+		line := fmt.Sprintf("• `%s` *%s* until %s", window.ID, window.Status, slackDate(window.EndDate))
+		if window.Description != "" {
+			line += " · " + truncate(window.Description, 100)
+		}
+		lines = append(lines, line)
+	}
+	return []slack.Block{markdownSection(truncate(strings.Join(lines, "\n"), 3000))}
+}
+
+func parseMaintenanceFlags(words []string) (map[string][]string, error) {
+	flags := make(map[string][]string)
+	var name string
+	var value []string
+	flush := func() {
+		if name != "" && len(value) > 0 {
+			flags[name] = append(flags[name], strings.Join(value, " "))
+		}
+	}
+
+	for _, word := range words {
+		if strings.HasPrefix(word, "--") {
+			flush()
+			name, value = strings.TrimPrefix(word, "--"), nil
+			if name != "team" && name != "rule" {
+				return nil, fmt.Errorf("unknown option %s", word)
+			}
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("unexpected %q before an option", word)
+		}
+		value = append(value, word)
+	}
+	flush()
+
+	if len(flags["team"]) > 1 {
+		return nil, fmt.Errorf("only one --team can be given")
+	}
+	return flags, nil
+}
+
+func parseMaintenanceDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var duration time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, err
+		}
+		duration = parsed
+	}
+
+	if duration < time.Minute {
+		return 0, fmt.Errorf("duration %s is shorter than a minute", value)
+	}
+	return duration, nil
+}
+
+func slackDate(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} at {time}|%s>", t.Unix(), t.UTC().Format(time.RFC1123))
+}
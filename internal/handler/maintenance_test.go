@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{"no flags", "", map[string][]string{}, false},
+		{"team", "--team Platform", map[string][]string{"team": {"Platform"}}, false},
+		{"multi-word values", "--team Site Reliability --rule Datadog prod --rule Nightly backups",
+			map[string][]string{"team": {"Site Reliability"}, "rule": {"Datadog prod", "Nightly backups"}}, false},
+		{"flag without value is ignored", "--rule --team Platform", map[string][]string{"team": {"Platform"}}, false},
+		{"unknown flag", "--channel ops", nil, true},
+		{"value before a flag", "Platform --team Ops", nil, true},
+		{"two teams", "--team Ops --team Platform", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMaintenanceFlags(strings.Fields(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMaintenanceFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseMaintenanceFlags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMaintenanceDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"2h", 2 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"1h30m", 90 * time.Minute, false},
+		{" 45m ", 45 * time.Minute, false},
+		{"1d", 24 * time.Hour, false},
+		{"3d", 72 * time.Hour, false},
+		{"1m", time.Minute, false},
+		{"30s", 0, true},
+		{"0d", 0, true},
+		{"-2h", 0, true},
+		{"xd", 0, true},
+		{"tomorrow", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseMaintenanceDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMaintenanceDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("parseMaintenanceDuration(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+func (a *IncidentApp) registerModalActionHandlers() {
+	a.RegisterActionHandler(service.UrgencyActionID, a.handleModalChange)
+	a.RegisterActionHandler(service.TemplatePickerActionID, a.handleModalChange)
+	a.RegisterActionHandler(service.TeamActionID, a.handleModalChange)
+}
+
+func (a *IncidentApp) handleModalChange(payload slack.InteractionCallback, action *slack.BlockAction) error {
+	if payload.View.CallbackID != service.IncidentModalCallbackID {
+		return nil
+	}
+
+	a.logger.WithFields(logrus.Fields{
+		"action_id": action.ActionID,
+		"view_id":   payload.View.ID,
+	}).Debug("Updating incident modal after selection")
+
+	form := a.incidentFormFromView(payload.View)
+	if err := a.slackService.UpdateView(payload.View.ID, payload.View.Hash, a.slackService.IncidentModal(form)); err != nil {
+		return fmt.Errorf("failed to update incident modal: %w", err)
+	}
+	return nil
+}
+
+func (a *IncidentApp) incidentFormFromView(view slack.View) service.IncidentForm {
+	values := view.State.Values
+	metadata := a.parseModalMetadata(view.PrivateMetadata)
+
+	form := service.IncidentForm{
+		Metadata:  metadata,
+		Templates: a.config.Templates,
+		Urgency:   values[service.UrgencyBlockID][service.UrgencyActionID].SelectedOption.Value,
+		Urgencies: a.config.Urgencies,
+	}
+
+	templateID := metadata.TemplateID
+	if selected, ok := values[service.TemplatePickerBlockID][service.TemplatePickerActionID]; ok && selected.SelectedOption.Value != "" {
+		templateID = selected.SelectedOption.Value
+	}
+	if template, ok := a.config.Template(templateID); ok {
+		form.Template = template
+	}
+
+	if team := selectedTeam(values); team != nil {
+		form.Team = team
+		form.OnCall = a.onCallMentions(team.ID)
+	}
+
+	return form
+}
+
+func (a *IncidentApp) onCallMentions(teamID string) []string {
+	recipients, err := a.alertService.GetOnCallRecipients(teamID)
+	if err != nil {
+		a.logger.WithError(err).WithField("team_id", teamID).Warn("Failed to get on-call for team")
+		return nil
+	}
+
+	mentions := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		if userID, err := a.slackService.LookupUserIDByEmail(recipient); err == nil {
+			mentions = append(mentions, fmt.Sprintf("<@%s>", userID))
+			continue
+		}
+		mentions = append(mentions, recipient)
+	}
+	return mentions
+}
+
+func (a *IncidentApp) applyFormSelections(alert *model.Alert, values map[string]map[string]slack.BlockAction) {
+	if team := selectedTeam(values); team != nil {
+		alert.ResponderTeamID = team.ID
+	}
+
+	if impact := values[service.ImpactBlockID][service.ImpactActionID].Value; impact != "" {
+		details := make(map[string]string, len(alert.Details)+1)
+		for key, value := range alert.Details {
+			details[key] = value
+		}
+		details["customerImpact"] = impact
+		alert.Details = details
+	}
+}
+
+func selectedTeam(values map[string]map[string]slack.BlockAction) *model.OpsGenieTeam {
+	option := values[service.TeamBlockID][service.TeamActionID].SelectedOption
+	if option.Value == "" {
+		return nil
+	}
+
+	team := &model.OpsGenieTeam{ID: option.Value, Name: option.Value}
+	if option.Text != nil && option.Text.Text != "" {
+		team.Name = option.Text.Text
+	}
+	return team
+}
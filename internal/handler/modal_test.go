@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+func teamSelection(id, name string) map[string]slack.BlockAction {
+	option := slack.OptionBlockObject{Value: id}
+	if name != "" {
+		option.Text = &slack.TextBlockObject{Type: slack.PlainTextType, Text: name}
+	}
+	return map[string]slack.BlockAction{service.TeamActionID: {SelectedOption: option}}
+}
+
+func TestHandleModalChange(t *testing.T) {
+	tests := []struct {
+		name        string
+		callbackID  string
+		actionID    string
+		values      map[string]map[string]slack.BlockAction
+		wantUpdate  bool
+		wantBlocks  []string
+		wantMissing []string
+		wantText    string
+	}{
+		{
+			name:        "critical urgency asks for impact",
+			callbackID:  service.IncidentModalCallbackID,
+			actionID:    service.UrgencyActionID,
+			values:      map[string]map[string]slack.BlockAction{service.UrgencyBlockID: {service.UrgencyActionID: {SelectedOption: slack.OptionBlockObject{Value: "critical"}}}},
+			wantUpdate:  true,
+			wantBlocks:  []string{service.ImpactBlockID, service.ImpactConfirmBlockID},
+			wantMissing: []string{"oncall_block"},
+		},
+		{
+			name:        "lower urgency drops the impact questions",
+			callbackID:  service.IncidentModalCallbackID,
+			actionID:    service.UrgencyActionID,
+			values:      map[string]map[string]slack.BlockAction{service.UrgencyBlockID: {service.UrgencyActionID: {SelectedOption: slack.OptionBlockObject{Value: "high"}}}},
+			wantUpdate:  true,
+			wantMissing: []string{service.ImpactBlockID, service.ImpactConfirmBlockID},
+		},
+		{
+			name:       "team shows who is on call",
+			callbackID: service.IncidentModalCallbackID,
+			actionID:   service.TeamActionID,
+			values:     map[string]map[string]slack.BlockAction{service.TeamBlockID: teamSelection("team-dba", "DBA")},
+			wantUpdate: true,
+			wantBlocks: []string{"oncall_block"},
+			wantText:   `On call for *DBA*: \u003c@U9\u003e, carol@acme.io`,
+		},
+		{
+			name:       "other modals are left alone",
+			callbackID: "maintenance_modal",
+			actionID:   service.TeamActionID,
+			values:     map[string]map[string]slack.BlockAction{service.TeamBlockID: teamSelection("team-dba", "DBA")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opsgenie := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v2/schedules":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]interface{}{
+						{"id": "s-1", "name": "DBA primary", "enabled": true, "ownerTeam": map[string]string{"id": "team-dba"}},
+					}})
+				case "/v2/schedules/s-1/on-calls":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"onCallRecipients": []string{"bob@acme.io", "carol@acme.io"}}})
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer opsgenie.Close()
+
+			fake, slackService := newFakeSlackAPI(t, map[string]interface{}{
+				"/users.lookupByEmail": func(form url.Values) interface{} {
+					if form.Get("email") == "bob@acme.io" {
+						return map[string]interface{}{"ok": true, "user": map[string]string{"id": "U9"}}
+					}
+					return map[string]interface{}{"ok": false, "error": "users_not_found"}
+				},
+			})
+			app := newTestApp(store.NewMemoryStore())
+			app.slackService = slackService
+			app.alertService = service.NewAlertServiceWithEndpoint("key", "team", "", opsgenie.URL, quietLogger())
+
+			app.HandleInteraction(slack.InteractionCallback{
+				Type: slack.InteractionTypeBlockActions,
+				View: slack.View{
+					ID:         "V1",
+					Hash:       "h1",
+					CallbackID: tt.callbackID,
+					State:      &slack.ViewState{Values: tt.values},
+				},
+				ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{{ActionID: tt.actionID}}},
+			})
+
+			updates := fake.find("/views.update")
+			if !tt.wantUpdate {
+				if len(updates) != 0 {
+					t.Fatalf("updated %d views, want none", len(updates))
+				}
+				return
+			}
+			if len(updates) != 1 {
+				t.Fatalf("updated %d views, want 1", len(updates))
+			}
+			body := updates[0].Body
+			if !strings.Contains(body, `"view_id":"V1"`) || !strings.Contains(body, `"hash":"h1"`) {
+				t.Fatalf("update does not target the open view: %s", body)
+			}
+			for _, blockID := range tt.wantBlocks {
+				if !strings.Contains(body, `"block_id":"`+blockID+`"`) {
+					t.Errorf("update is missing %s: %s", blockID, body)
+				}
+			}
+			for _, blockID := range tt.wantMissing {
+				if strings.Contains(body, `"block_id":"`+blockID+`"`) {
+					t.Errorf("update has %s: %s", blockID, body)
+				}
+			}
+			if tt.wantText != "" && !strings.Contains(body, tt.wantText) {
+				t.Errorf("update is missing %q: %s", tt.wantText, body)
+			}
+		})
+	}
+}
+
+func TestApplyFormSelections(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]map[string]slack.BlockAction
+		want   model.Alert
+	}{
+		{
+			name: "nothing selected",
+			want: model.Alert{Details: map[string]string{"slackChannelId": "C1"}},
+		},
+		{
+			name:   "team",
+			values: map[string]map[string]slack.BlockAction{service.TeamBlockID: teamSelection("team-dba", "DBA")},
+			want:   model.Alert{ResponderTeamID: "team-dba", Details: map[string]string{"slackChannelId": "C1"}},
+		},
+		{
+			name: "customer impact",
+			values: map[string]map[string]slack.BlockAction{
+				service.ImpactBlockID: {service.ImpactActionID: {Value: "EU checkout is down"}},
+			},
+			want: model.Alert{Details: map[string]string{"slackChannelId": "C1", "customerImpact": "EU checkout is down"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := &model.Alert{Details: map[string]string{"slackChannelId": "C1"}}
+			newTestApp(store.NewMemoryStore()).applyFormSelections(alert, tt.values)
+			if !reflect.DeepEqual(*alert, tt.want) {
+				t.Fatalf("alert = %+v, want %+v", *alert, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectedTeam(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]map[string]slack.BlockAction
+		want   *model.OpsGenieTeam
+	}{
+		{"no selection", nil, nil},
+		{"selection with a label", map[string]map[string]slack.BlockAction{service.TeamBlockID: teamSelection("team-dba", "DBA")}, &model.OpsGenieTeam{ID: "team-dba", Name: "DBA"}},
+		{"selection without a label", map[string]map[string]slack.BlockAction{service.TeamBlockID: teamSelection("team-dba", "")}, &model.OpsGenieTeam{ID: "team-dba", Name: "team-dba"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectedTeam(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("selectedTeam() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
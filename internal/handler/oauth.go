@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	slackAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+	oauthStateCookie  = "slack_oauth_state"
+	oauthStateTTL     = 10 * time.Minute
+)
+
+type OAuthHandler struct {
+	config     func() config.OAuthConfig
+	workspaces *service.WorkspaceService
+	logger     *logrus.Logger
+}
+
+func NewOAuthHandler(oauthConfig func() config.OAuthConfig, workspaces *service.WorkspaceService, logger *logrus.Logger) *OAuthHandler {
+	return &OAuthHandler{
+		config:     oauthConfig,
+		workspaces: workspaces,
+		logger:     logger,
+	}
+}
+
+func (h *OAuthHandler) HandleInstall(w http.ResponseWriter, r *http.Request) {
+	state, err := h.newState()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create oauth state")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(oauthStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	oauthConfig := h.config()
+	query := url.Values{
+		"client_id":    {oauthConfig.ClientID},
+		"scope":        {strings.Join(oauthConfig.Scopes, ",")},
+		"redirect_uri": {oauthConfig.RedirectURL},
+		"state":        {state},
+	}
+	http.Redirect(w, r, slackAuthorizeURL+"?"+query.Encode(), http.StatusFound)
+}
+
+func (h *OAuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		h.logger.WithField("error", reason).Warn("Slack install was cancelled")
+		h.writePage(w, http.StatusOK, "Installation cancelled", "The bot was not installed. You can close this window.")
+		return
+	}
+
+	state := query.Get("state")
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil || cookie.Value != state || !h.validState(state) {
+		h.logger.Warn("Rejected oauth callback with invalid state")
+		h.writePage(w, http.StatusBadRequest, "Installation failed", "The install link expired or was opened in another browser. Start the installation again.")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Value: "", Path: "/", MaxAge: -1})
+
+	workspace, err := h.workspaces.Install(query.Get("code"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to complete Slack install")
+		h.writePage(w, http.StatusBadGateway, "Installation failed", "Slack did not accept the installation. Please try again.")
+		return
+	}
+
+	if successURL := h.config().SuccessURL; successURL != "" {
+		http.Redirect(w, r, successURL, http.StatusFound)
+		return
+	}
+	h.writePage(w, http.StatusOK, "Installed",
+		fmt.Sprintf("The OpsGenie bot is installed in %s. Run /opsgenie connect in Slack to use your own OpsGenie account.", workspace.TeamName))
+}
+
+func (h *OAuthHandler) newState() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	payload := hex.EncodeToString(nonce) + "." + strconv.FormatInt(time.Now().Add(oauthStateTTL).Unix(), 10)
+	return payload + "." + h.sign(payload), nil
+}
+
+func (h *OAuthHandler) validState(state string) bool {
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(h.sign(payload))) {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	return err == nil && time.Now().Unix() <= expiresAt
+}
+
+func (h *OAuthHandler) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(h.config().ClientSecret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *OAuthHandler) writePage(w http.ResponseWriter, status int, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<!doctype html><html><head><title>%s</title></head><body><h1>%s</h1><p>%s</p></body></html>",
+		html.EscapeString(title), html.EscapeString(title), html.EscapeString(message))
+}
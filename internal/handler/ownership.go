@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	assignCommand = "assign"
+	assignJobType = "ownership.assign"
+)
+
+var userMentionPattern = regexp.MustCompile(`^<@([UW][A-Z0-9]+)(\|[^>]*)?>$`)
+
+type OwnershipHandler struct {
+	alertService  *service.AlertService
+	users         *service.UserDirectory
+	permissions   *service.PermissionService
+	announcements *service.AnnouncementService
+	audit         *service.AuditService
+	notify        func(userID, responseURL, text string, blocks []slack.Block)
+	enqueue       func(jobType string, payload interface{}) error
+	logger        *logrus.Logger
+}
+
+type assignRequest struct {
+	Alert       model.AlertRef `json:"alert"`
+	OwnerID     string         `json:"ownerId"`
+	UserID      string         `json:"userId"`
+	UserName    string         `json:"userName"`
+	ChannelID   string         `json:"channelId"`
+	ResponseURL string         `json:"responseUrl,omitempty"`
+	Source      string         `json:"source"`
+}
+
+type assignJob struct {
+	Request assignRequest `json:"request"`
+	TinyID  string        `json:"tinyId,omitempty"`
+}
+
+func NewOwnershipHandler(
+	alertService *service.AlertService,
+	users *service.UserDirectory,
+	permissions *service.PermissionService,
+	announcements *service.AnnouncementService,
+	audit *service.AuditService,
+	logger *logrus.Logger,
+) *OwnershipHandler {
+	return &OwnershipHandler{
+		alertService:  alertService,
+		users:         users,
+		permissions:   permissions,
+		announcements: announcements,
+		audit:         audit,
+		logger:        logger,
+	}
+}
+
+func (h *OwnershipHandler) Register(app *IncidentApp) {
+	app.RegisterActionHandler(service.TakeOwnershipActionID, h.handleTakeOwnership)
+	app.RegisterCommandHandler(assignCommand, h.handleAssignCommand)
+	app.RegisterJobHandler(assignJobType, h.runAssign)
+	h.notify = app.notify
+	h.enqueue = app.Enqueue
+}
+
+func (h *OwnershipHandler) handleTakeOwnership(payload slack.InteractionCallback, action *slack.BlockAction) error {
+	var ref model.AlertRef
+	if err := json.Unmarshal([]byte(action.Value), &ref); err != nil {
+		return fmt.Errorf("failed to decode alert reference: %w", err)
+	}
+
+	request := assignRequest{
+		Alert:       ref,
+		OwnerID:     payload.User.ID,
+		UserID:      payload.User.ID,
+		UserName:    payload.User.Name,
+		ChannelID:   payload.Channel.ID,
+		ResponseURL: payload.ResponseURL,
+		Source:      model.SourceButton,
+	}
+	if err := h.announcements.Track(ref.ID, payload); err != nil {
+		h.logger.WithError(err).WithField("alert_id", ref.ID).Warn("Failed to track the clicked alert message")
+	}
+	if err := h.enqueue(assignJobType, assignJob{Request: request}); err != nil {
+		h.logger.WithError(err).WithField("alert_id", ref.ID).Error("Failed to queue assignment")
+		message := fmt.Sprintf("Failed to assign %s.", ref.Label())
+		h.notify(payload.User.ID, payload.ResponseURL, message, errorBlocks(message))
+	}
+	return nil
+}
+
+func (h *OwnershipHandler) handleAssignCommand(cmd slack.SlashCommand, args []string) *Response {
+	if len(args) != 2 {
+		return ephemeralResponse("Usage: `/opsgenie assign <tinyId> @user` or `/opsgenie assign <tinyId> me`", nil)
+	}
+
+	ownerID := cmd.UserID
+	if args[1] != "me" {
+		match := userMentionPattern.FindStringSubmatch(args[1])
+		if match == nil {
+			return ephemeralResponse("Mention the new owner, for example `/opsgenie assign 123 @jane`.", nil)
+		}
+		ownerID = match[1]
+	}
+
+	tinyID := strings.TrimPrefix(args[0], "#")
+	job := assignJob{
+		Request: assignRequest{
+			OwnerID:     ownerID,
+			UserID:      cmd.UserID,
+			UserName:    cmd.UserName,
+			ChannelID:   cmd.ChannelID,
+			ResponseURL: cmd.ResponseURL,
+			Source:      model.SourceSlashCommand,
+		},
+		TinyID: tinyID,
+	}
+	if err := h.enqueue(assignJobType, job); err != nil {
+		h.logger.WithError(err).WithField("tiny_id", tinyID).Error("Failed to queue assignment")
+		return ephemeralResponse(fmt.Sprintf("❌ Failed to assign #%s. Please try again.", tinyID), nil)
+	}
+	return ephemeralResponse(fmt.Sprintf("⏳ Assigning #%s…", tinyID), nil)
+}
+
+func (h *OwnershipHandler) runAssign(ctx context.Context, data json.RawMessage) error {
+	var job assignJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("failed to decode assign job: %w", err)
+	}
+
+	request := job.Request
+	if job.TinyID != "" {
+		result, err := h.alertService.FindByTinyID(job.TinyID)
+		if err != nil {
+			h.logger.WithError(err).WithField("tiny_id", job.TinyID).Warn("Failed to find alert to assign")
+			message := fmt.Sprintf("Could not find alert #%s.", job.TinyID)
+			h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+			return nil
+		}
+		request.Alert = result.Ref()
+	}
+
+	h.assign(request)
+	return nil
+}
+
+func (h *OwnershipHandler) assign(request assignRequest) {
+	logger := h.logger.WithFields(logrus.Fields{
+		"alert_id": request.Alert.ID,
+		"user_id":  request.UserID,
+		"owner_id": request.OwnerID,
+	})
+
+	event := model.AuditEvent{
+		Action:     model.ActionAssign,
+		Outcome:    model.AuditOutcomeSuccess,
+		UserID:     request.UserID,
+		UserName:   request.UserName,
+		ChannelID:  request.ChannelID,
+		Source:     request.Source,
+		Priority:   request.Alert.Priority,
+		OpsGenieID: request.Alert.ID,
+		TinyID:     request.Alert.TinyID,
+		Kind:       request.Alert.Kind,
+	}
+
+	if decision := h.permissions.Authorize(model.AccessRequest{
+		UserID:    request.UserID,
+		ChannelID: request.ChannelID,
+		Action:    model.ActionAssign,
+		Priority:  request.Alert.Priority,
+	}); !decision.Allowed {
+		h.notify(request.UserID, request.ResponseURL, "⛔ "+decision.Message, nil)
+		return
+	}
+
+	owner, ok := h.users.OpsGenieUser(request.OwnerID)
+	if !ok {
+		message := fmt.Sprintf("<@%s> has no OpsGenie user with the same email address.", request.OwnerID)
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return
+	}
+	user, _ := h.users.OpsGenieUser(request.UserID)
+
+	if err := h.alertService.Assign(request.Alert, owner, user); err != nil {
+		logger.WithError(err).Error("Failed to assign alert")
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+		h.audit.Record(event)
+
+		message := fmt.Sprintf("Failed to assign %s.", request.Alert.Label())
+		if errors.Is(err, service.ErrAssignNotSupported) {
+			message = fmt.Sprintf("%s is an incident, and OpsGenie incidents cannot be assigned.", request.Alert.Label())
+		}
+		h.notify(request.UserID, request.ResponseURL, message, errorBlocks(message))
+		return
+	}
+	h.audit.Record(event)
+	logger.Info("Assigned alert")
+
+	if err := h.announcements.SetOwner(request.Alert.ID, request.OwnerID); err != nil {
+		logger.WithError(err).Warn("Failed to update alert announcements")
+	}
+
+	if request.Source == model.SourceSlashCommand {
+		message := fmt.Sprintf("🙋 Assigned %s to <@%s>.", request.Alert.Label(), request.OwnerID)
+		h.notify(request.UserID, request.ResponseURL, message, []slack.Block{markdownSection(message)})
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/audit"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+func TestAssignCommandQueuesJobs(t *testing.T) {
+	tests := []struct {
+		text      string
+		wantOwner string
+		wantReply string
+	}{
+		{"assign 42 <@U2>", "U2", "⏳ Assigning #42"},
+		{"assign #42 <@W2|jane>", "W2", "⏳ Assigning #42"},
+		{"assign 42 me", "U1", "⏳ Assigning #42"},
+		{"assign 42 @jane", "", "Mention the new owner"},
+		{"assign 42 <#C1|ops>", "", "Mention the new owner"},
+		{"assign 42", "", "Usage: `/opsgenie assign <tinyId> @user`"},
+		{"assign 42 <@U2> me", "", "Usage: `/opsgenie assign <tinyId> @user`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			logger := quietLogger()
+			backend := queue.NewMemoryBackend()
+			app := NewIncidentApp(nil, nil, nil, nil, nil, nil, nil, nil, nil, store.NewMemoryStore(),
+				queue.NewWithBackend(backend, 1, 1, logger), &config.Config{SlackTeamID: "T1"}, logger)
+			NewOwnershipHandler(nil, nil, nil, nil, nil, logger).Register(app)
+
+			resp := app.HandleCommand(slack.SlashCommand{
+				TeamID:      "T1",
+				UserID:      "U1",
+				Command:     opsgenieCommand,
+				Text:        tt.text,
+				ResponseURL: "https://hooks.slack.com/commands/1",
+			})
+			msg, ok := resp.Body.(slack.Msg)
+			if !ok || !strings.HasPrefix(msg.Text, tt.wantReply) {
+				t.Fatalf("HandleCommand() body = %#v, want %q", resp.Body, tt.wantReply)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			job, err := backend.Pop(ctx)
+			if tt.wantOwner == "" {
+				if err == nil {
+					t.Fatalf("queued %s for a rejected command", job.Type)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Pop() error = %v, want a queued job", err)
+			}
+			var payload assignJob
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if job.Type != assignJobType || payload.TinyID != "42" || payload.Request.OwnerID != tt.wantOwner || payload.Request.UserID != "U1" {
+				t.Fatalf("queued %s %+v, want #42 assigned to %s by U1", job.Type, payload, tt.wantOwner)
+			}
+		})
+	}
+}
+
+func TestAssignChecksPermissions(t *testing.T) {
+	logger := quietLogger()
+	st := store.NewMemoryStore()
+	auditService := service.NewAuditService(audit.NewStoreSink(st), "", logger)
+	permissions := service.NewPermissionService(config.PermissionConfig{
+		Default: config.EffectAllow,
+		Rules: []config.PermissionRule{
+			{Effect: config.EffectDeny, Actions: []string{model.ActionAssign}, Priorities: []string{"P1"}},
+		},
+	}, nil, st, auditService, logger)
+
+	h := NewOwnershipHandler(nil, nil, permissions, nil, auditService, logger)
+	var notified []string
+	h.notify = func(userID, responseURL, text string, blocks []slack.Block) {
+		notified = append(notified, text)
+	}
+
+	h.assign(assignRequest{
+		Alert:   model.AlertRef{ID: "alert-1", TinyID: "7", Priority: model.PriorityP1},
+		OwnerID: "U2",
+		UserID:  "U1",
+		Source:  model.SourceSlashCommand,
+	})
+
+	if len(notified) != 1 || !strings.HasPrefix(notified[0], "⛔ You are not allowed to assign P1") {
+		t.Fatalf("notified %q, want a permission denial", notified)
+	}
+}
@@ -0,0 +1,420 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	reactionConfirmActionID = "reaction_incident_confirm"
+	reactionDismissActionID = "reaction_incident_dismiss"
+
+	reactionIncidentTTL = 7 * 24 * time.Hour
+	reactionPromptTTL   = 10 * time.Minute
+	reactionPendingMark = "pending"
+
+	reactionIncidentJobType = "reaction.create"
+
+	maxAlertMessageLength = 130
+	maxPreviewLength      = 300
+)
+
+type ReactionHandler struct {
+	config        config.ReactionConfig
+	slackService  *service.SlackService
+	incidents     *service.IncidentService
+	history       *service.HistoryService
+	channels      *service.IncidentChannelService
+	permissions   *service.PermissionService
+	rateLimiter   *service.RateLimiter
+	announcements *service.AnnouncementService
+	store         store.Store
+	enqueue       func(jobType string, payload interface{}) error
+	logger        *logrus.Logger
+}
+
+type reactionTarget struct {
+	ChannelID string `json:"c"`
+	MessageTS string `json:"t"`
+}
+
+type reactionIncidentJob struct {
+	Target      reactionTarget `json:"target"`
+	UserID      string         `json:"userId"`
+	UserName    string         `json:"userName"`
+	TeamID      string         `json:"teamId"`
+	TeamDomain  string         `json:"teamDomain"`
+	ResponseURL string         `json:"responseUrl"`
+}
+
+func NewReactionHandler(
+	reactionConfig config.ReactionConfig,
+	slackService *service.SlackService,
+	incidents *service.IncidentService,
+	history *service.HistoryService,
+	channels *service.IncidentChannelService,
+	permissions *service.PermissionService,
+	rateLimiter *service.RateLimiter,
+	announcements *service.AnnouncementService,
+	store store.Store,
+	logger *logrus.Logger,
+) *ReactionHandler {
+	return &ReactionHandler{
+		config:        reactionConfig,
+		slackService:  slackService,
+		incidents:     incidents,
+		history:       history,
+		channels:      channels,
+		permissions:   permissions,
+		rateLimiter:   rateLimiter,
+		announcements: announcements,
+		store:         store,
+		logger:        logger,
+	}
+}
+
+func (r *ReactionHandler) Register(app *IncidentApp) {
+	if !r.config.Enabled() {
+		return
+	}
+	app.RegisterEventHandler(string(slackevents.ReactionAdded), r.handleReactionAdded)
+	app.RegisterActionHandler(reactionConfirmActionID, r.handleConfirm)
+	app.RegisterActionHandler(reactionDismissActionID, r.handleDismiss)
+	app.RegisterJobHandler(reactionIncidentJobType, r.runReactionIncident)
+	r.enqueue = app.Enqueue
+}
+
+func (r *ReactionHandler) handleReactionAdded(event slackevents.EventsAPIEvent) error {
+	reaction, ok := event.InnerEvent.Data.(*slackevents.ReactionAddedEvent)
+	if !ok || reaction.Reaction != r.config.Emoji || reaction.Item.Type != "message" {
+		return nil
+	}
+
+	channel, ok := r.config.Channel(reaction.Item.Channel)
+	if !ok {
+		return nil
+	}
+
+	target := reactionTarget{ChannelID: reaction.Item.Channel, MessageTS: reaction.Item.Timestamp}
+	logger := r.logger.WithFields(logrus.Fields{
+		"user_id":    reaction.User,
+		"channel_id": target.ChannelID,
+		"message_ts": target.MessageTS,
+	})
+
+	if alertURL, exists, err := r.store.Get(target.incidentKey()); err != nil {
+		return fmt.Errorf("failed to check existing incident: %w", err)
+	} else if exists {
+		logger.Info("Ignoring reaction on message that already raised an incident")
+		return r.slackService.SendEphemeralMessage(target.ChannelID, reaction.User,
+			"An incident was already raised from this message.",
+			duplicateIncidentBlocks(alertURL))
+	}
+
+	if decision := r.permissions.Authorize(model.AccessRequest{
+		UserID:    reaction.User,
+		ChannelID: target.ChannelID,
+		Action:    model.ActionCreate,
+		Priority:  channelPriority(channel),
+	}); !decision.Allowed {
+		return r.slackService.SendEphemeralMessage(target.ChannelID, reaction.User, "⛔ "+decision.Message, nil)
+	}
+
+	prompted, err := r.store.SetIfAbsent(target.promptKey(reaction.User), reactionPendingMark, reactionPromptTTL)
+	if err != nil {
+		return fmt.Errorf("failed to record reaction prompt: %w", err)
+	}
+	if !prompted {
+		logger.Debug("Confirmation prompt already sent")
+		return nil
+	}
+
+	message, err := r.slackService.GetMessage(target.ChannelID, target.MessageTS)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(target)
+	if err != nil {
+		return fmt.Errorf("failed to encode reaction target: %w", err)
+	}
+
+	logger.Info("Sending reaction incident confirmation")
+
+	blocks := []slack.Block{
+		&slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{
+				Type: slack.MarkdownType,
+				Text: fmt.Sprintf("🚨 You reacted with :%s:. Raise an OpsGenie incident from this message?\n\n"+
+					"*Priority:* %s\n"+
+					">%s",
+					r.config.Emoji,
+					string(channelPriority(channel)),
+					truncate(strings.ReplaceAll(message.Text, "\n", "\n>"), maxPreviewLength)),
+			},
+		},
+		slack.NewActionBlock("reaction_incident_actions",
+			slack.NewButtonBlockElement(reactionConfirmActionID, string(value),
+				slack.NewTextBlockObject(slack.PlainTextType, "Create incident", true, false)).
+				WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(reactionDismissActionID, string(value),
+				slack.NewTextBlockObject(slack.PlainTextType, "Dismiss", true, false)),
+		),
+	}
+
+	return r.slackService.SendEphemeralMessage(target.ChannelID, reaction.User,
+		"Raise an OpsGenie incident from this message?", blocks)
+}
+
+func (r *ReactionHandler) handleConfirm(payload slack.InteractionCallback, action *slack.BlockAction) error {
+	var target reactionTarget
+	if err := json.Unmarshal([]byte(action.Value), &target); err != nil {
+		return fmt.Errorf("failed to decode reaction target: %w", err)
+	}
+
+	channel, ok := r.config.Channel(target.ChannelID)
+	if !ok {
+		return r.slackService.ReplaceOriginalMessage(payload.ResponseURL,
+			"❌ Reaction incidents are no longer enabled in this channel.", nil)
+	}
+
+	claimed, err := r.store.SetIfAbsent(target.incidentKey(), reactionPendingMark, reactionIncidentTTL)
+	if err != nil {
+		return fmt.Errorf("failed to claim reaction incident: %w", err)
+	}
+	if !claimed {
+		alertURL, _, _ := r.store.Get(target.incidentKey())
+		return r.slackService.ReplaceOriginalMessage(payload.ResponseURL,
+			"An incident was already raised from this message.",
+			duplicateIncidentBlocks(alertURL))
+	}
+
+	if decision := r.rateLimiter.Allow(model.AccessRequest{
+		UserID:    payload.User.ID,
+		ChannelID: target.ChannelID,
+		Action:    model.ActionCreate,
+		Priority:  channelPriority(channel),
+	}); !decision.Allowed {
+		if _, err := r.store.DeleteIfValue(target.incidentKey(), reactionPendingMark); err != nil {
+			r.logger.WithError(err).Error("Failed to release reaction incident claim")
+		}
+		return r.slackService.ReplaceOriginalMessage(payload.ResponseURL, "⏳ "+decision.Message, nil)
+	}
+
+	if err := r.slackService.ReplaceOriginalMessage(payload.ResponseURL, "⏳ Creating incident…", nil); err != nil {
+		r.logger.WithError(err).Warn("Failed to acknowledge reaction confirmation")
+	}
+
+	job := reactionIncidentJob{
+		Target:      target,
+		UserID:      payload.User.ID,
+		UserName:    payload.User.Name,
+		TeamID:      payload.Team.ID,
+		TeamDomain:  payload.Team.Domain,
+		ResponseURL: payload.ResponseURL,
+	}
+	if err := r.enqueue(reactionIncidentJobType, job); err != nil {
+		r.fail(job, err)
+	}
+	return nil
+}
+
+func (r *ReactionHandler) runReactionIncident(ctx context.Context, data json.RawMessage) error {
+	var job reactionIncidentJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("failed to decode reaction job: %w", err)
+	}
+	target := job.Target
+
+	channel, ok := r.config.Channel(target.ChannelID)
+	if !ok {
+		r.fail(job, fmt.Errorf("reaction channel %s is no longer configured", target.ChannelID))
+		return nil
+	}
+
+	alert, result, err := r.createAlert(ctx, job, channel)
+	if err != nil {
+		r.fail(job, err)
+		return nil
+	}
+
+	if err := r.store.Set(target.incidentKey(), result.URL, reactionIncidentTTL); err != nil {
+		r.logger.WithError(err).Error("Failed to record reaction incident")
+	}
+	if err := r.history.RecordReported(job.UserID, result); err != nil {
+		r.logger.WithError(err).Error("Failed to record reported incident")
+	}
+
+	incidentChannel := createIncidentChannel(r.channels, r.logger, alert, result)
+
+	if result.Pending {
+		if err := r.slackService.ReplaceOriginalMessage(job.ResponseURL, "Incident is being processed.", []slack.Block{
+			markdownSection(pendingText(result)),
+		}); err != nil {
+			r.logger.WithError(err).Error("Failed to send pending message")
+		}
+		return nil
+	}
+
+	text := fmt.Sprintf("✅ *Incident created successfully!*\n\n"+
+		"*Title:* %s\n"+
+		"*Priority:* %s\n"+
+		"*ID:* %s",
+		result.Title,
+		string(result.Priority),
+		result.ID)
+	if result.URL != "" {
+		text += fmt.Sprintf("\n🔗 <%s|View in OpsGenie>", result.URL)
+	}
+	if incidentChannel != nil {
+		text += fmt.Sprintf("\n📣 Incident channel: <#%s>", incidentChannel.ID)
+	}
+
+	if err := r.slackService.ReplaceOriginalMessage(job.ResponseURL, "Incident created successfully!", []slack.Block{
+		&slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{Type: slack.MarkdownType, Text: text},
+		},
+	}); err != nil {
+		r.logger.WithError(err).Error("Failed to send success message")
+	}
+
+	announcement := fmt.Sprintf("🚨 <@%s> raised an OpsGenie incident from this message.", job.UserID)
+	if result.URL != "" {
+		announcement += fmt.Sprintf(" <%s|View in OpsGenie>", result.URL)
+	}
+	if incidentChannel != nil {
+		announcement += fmt.Sprintf(" Follow along in <#%s>.", incidentChannel.ID)
+	}
+	if _, err := r.announcements.Post(result.Ref(), target.ChannelID, target.MessageTS, announcement, []slack.Block{
+		&slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{Type: slack.MarkdownType, Text: announcement},
+		},
+	}); err != nil {
+		r.logger.WithError(err).WithField("alert_id", result.ID).Error("Failed to announce reaction incident")
+	}
+	return nil
+}
+
+func (r *ReactionHandler) fail(job reactionIncidentJob, err error) {
+	r.logger.WithError(err).WithFields(logrus.Fields{
+		"user_id":    job.UserID,
+		"channel_id": job.Target.ChannelID,
+		"message_ts": job.Target.MessageTS,
+	}).Error("Failed to create incident from reaction")
+
+	if deleteErr := r.store.Delete(job.Target.incidentKey()); deleteErr != nil {
+		r.logger.WithError(deleteErr).Error("Failed to release reaction incident claim")
+	}
+	if replaceErr := r.slackService.ReplaceOriginalMessage(job.ResponseURL,
+		"❌ Failed to create incident. Please try again.", nil); replaceErr != nil {
+		r.logger.WithError(replaceErr).Error("Failed to send error message")
+	}
+}
+
+func (r *ReactionHandler) handleDismiss(payload slack.InteractionCallback, action *slack.BlockAction) error {
+	return r.slackService.DeleteOriginalMessage(payload.ResponseURL)
+}
+
+func (r *ReactionHandler) createAlert(
+	ctx context.Context,
+	job reactionIncidentJob,
+	channel config.ReactionChannel,
+) (model.Alert, *model.AlertCreationResult, error) {
+	target := job.Target
+	message, err := r.slackService.GetMessage(target.ChannelID, target.MessageTS)
+	if err != nil {
+		return model.Alert{}, nil, err
+	}
+
+	details := buildMessageDetails(r.slackService, r.logger, target.ChannelID, target.MessageTS, message.User)
+	details["reactedWith"] = r.config.Emoji
+
+	alert := model.Alert{
+		Title:       truncate(firstLine(message.Text), maxAlertMessageLength),
+		Description: message.Text,
+		Priority:    channelPriority(channel),
+		Source:      "Slack",
+		Tags:        []string{"slack-incident", "slack-reaction"},
+		Reporter: model.Reporter{
+			ID:       job.UserID,
+			Name:     job.UserName,
+			Username: job.UserName,
+		},
+		Team: model.Team{
+			ID:   job.TeamID,
+			Name: job.TeamDomain,
+		},
+		Details:         details,
+		ResponderTeamID: channel.TeamID,
+		ChannelID:       target.ChannelID,
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"user_id":    job.UserID,
+		"channel_id": target.ChannelID,
+		"message_ts": target.MessageTS,
+		"priority":   alert.Priority,
+	}).Info("Creating incident from reaction")
+
+	result, err := createIncidentOnce(ctx, r.store, r.incidents, r.logger, alert, model.SourceReaction)
+	return alert, result, err
+}
+
+func (t reactionTarget) incidentKey() string {
+	return fmt.Sprintf("reaction:incident:%s:%s", t.ChannelID, t.MessageTS)
+}
+
+func (t reactionTarget) promptKey(userID string) string {
+	return fmt.Sprintf("reaction:prompt:%s:%s:%s", t.ChannelID, t.MessageTS, userID)
+}
+
+func duplicateIncidentBlocks(alertURL string) []slack.Block {
+	text := "ℹ️ An incident was already raised from this message."
+	if alertURL != "" && alertURL != reactionPendingMark {
+		text += fmt.Sprintf(" <%s|View in OpsGenie>", alertURL)
+	}
+	return []slack.Block{
+		&slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{Type: slack.MarkdownType, Text: text},
+		},
+	}
+}
+
+func channelPriority(channel config.ReactionChannel) model.AlertPriority {
+	if channel.Priority == "" {
+		return model.PriorityP3
+	}
+	return model.AlertPriority(channel.Priority)
+}
+
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return "Incident raised from Slack message"
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/audit"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+func TestHandleConfirmClaimsMessageBeforeCountingRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var replies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var msg struct {
+			Text string `json:"text"`
+		}
+		json.Unmarshal(body, &msg)
+		mu.Lock()
+		replies = append(replies, msg.Text)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+	}))
+	defer server.Close()
+
+	logger := quietLogger()
+	st := store.NewMemoryStore()
+	auditService := service.NewAuditService(audit.NewStoreSink(st), "", logger)
+	permissions := service.NewPermissionService(config.PermissionConfig{}, nil, st, auditService, logger)
+	limiter := service.NewRateLimiter(config.RateLimitConfig{
+		Rules: []config.RateLimitRule{{Scope: config.RateLimitScopeUser, Max: 2, Window: time.Hour}},
+	}, permissions, st, auditService, logger)
+	slackService := service.NewSlackServiceWithClient(slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/")), logger)
+
+	h := NewReactionHandler(config.ReactionConfig{
+		Emoji:    "rotating_light",
+		Channels: []config.ReactionChannel{{ID: "C1"}},
+	}, slackService, nil, nil, nil, permissions, limiter, nil, st, logger)
+	var queued []string
+	h.enqueue = func(jobType string, payload interface{}) error {
+		queued = append(queued, payload.(reactionIncidentJob).Target.MessageTS)
+		return nil
+	}
+
+	confirm := func(messageTS string) string {
+		t.Helper()
+		target, _ := json.Marshal(reactionTarget{ChannelID: "C1", MessageTS: messageTS})
+		payload := slack.InteractionCallback{
+			User:        slack.User{ID: "U1"},
+			ResponseURL: server.URL + "/respond",
+		}
+		mu.Lock()
+		before := len(replies)
+		mu.Unlock()
+		if err := h.handleConfirm(payload, &slack.BlockAction{Value: string(target)}); err != nil {
+			t.Fatalf("handleConfirm(%s) error = %v", messageTS, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(replies) != before+1 {
+			t.Fatalf("got %d replies, want 1", len(replies)-before)
+		}
+		return replies[len(replies)-1]
+	}
+
+	tests := []struct {
+		messageTS string
+		want      string
+	}{
+		{"1.1", "⏳ Creating incident…"},
+		{"1.1", "An incident was already raised from this message."},
+		{"2.2", "⏳ Creating incident…"},
+		{"3.3", "⏳ You have reached the limit of 2 incidents per hour."},
+	}
+	for _, tt := range tests {
+		if got := confirm(tt.messageTS); !strings.HasPrefix(got, tt.want) {
+			t.Fatalf("confirm(%s) replied %q, want %q", tt.messageTS, got, tt.want)
+		}
+	}
+
+	if strings.Join(queued, ",") != "1.1,2.2" {
+		t.Fatalf("queued %v, want 1.1 and 2.2", queued)
+	}
+	if _, claimed, _ := st.Get(reactionTarget{ChannelID: "C1", MessageTS: "3.3"}.incidentKey()); claimed {
+		t.Fatal("rate limited message kept its claim")
+	}
+}
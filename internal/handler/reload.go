@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+type ReloadableApp struct {
+	mu  sync.RWMutex
+	app App
+}
+
+func NewReloadableApp(app App) *ReloadableApp {
+	return &ReloadableApp{app: app}
+}
+
+func (r *ReloadableApp) Swap(app App) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.app = app
+}
+
+func (r *ReloadableApp) current() App {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.app
+}
+
+func (r *ReloadableApp) HandleCommand(cmd slack.SlashCommand) *Response {
+	return r.current().HandleCommand(cmd)
+}
+
+func (r *ReloadableApp) HandleInteraction(payload slack.InteractionCallback) *Response {
+	return r.current().HandleInteraction(payload)
+}
+
+func (r *ReloadableApp) HandleCallbackEvent(event slackevents.EventsAPIEvent, retryNum, retryReason string) error {
+	return r.current().HandleCallbackEvent(event, retryNum, retryReason)
+}
+
+func (r *ReloadableApp) RunJob(ctx context.Context, job queue.Job) error {
+	return r.current().RunJob(ctx, job)
+}
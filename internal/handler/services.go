@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	servicesActionID    = "services"
+	servicesOptionLimit = 100
+)
+
+type ServiceOptionsHandler struct {
+	catalog *service.ServiceCatalog
+	logger  *logrus.Logger
+}
+
+func NewServiceOptionsHandler(catalog *service.ServiceCatalog, logger *logrus.Logger) *ServiceOptionsHandler {
+	return &ServiceOptionsHandler{
+		catalog: catalog,
+		logger:  logger,
+	}
+}
+
+func (h *ServiceOptionsHandler) Register(app *IncidentApp) {
+	app.RegisterOptionsHandler(servicesActionID, h.handleServiceOptions)
+	app.RegisterOptionsHandler(service.TeamActionID, h.handleTeamOptions)
+}
+
+func (h *ServiceOptionsHandler) handleServiceOptions(payload slack.InteractionCallback) (*slack.OptionsResponse, error) {
+	services, err := h.catalog.Search(payload.Value, servicesOptionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(services))
+	for _, svc := range services {
+		options = append(options, slack.NewOptionBlockObject(
+			svc.ID,
+			slack.NewTextBlockObject(slack.PlainTextType, truncate(svc.Name, 75), false, false),
+			nil,
+		))
+	}
+
+	return &slack.OptionsResponse{Options: options}, nil
+}
+
+func (h *ServiceOptionsHandler) handleTeamOptions(payload slack.InteractionCallback) (*slack.OptionsResponse, error) {
+	teams, err := h.catalog.SearchTeams(payload.Value, servicesOptionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(teams))
+	for _, team := range teams {
+		options = append(options, slack.NewOptionBlockObject(
+			team.ID,
+			slack.NewTextBlockObject(slack.PlainTextType, truncate(team.Name, 75), false, false),
+			nil,
+		))
+	}
+
+	return &slack.OptionsResponse{Options: options}, nil
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+func TestServiceOptions(t *testing.T) {
+	longName := strings.Repeat("x", 80)
+
+	tests := []struct {
+		name     string
+		actionID string
+		query    string
+		status   int
+		want     []string
+	}{
+		{"services matching the query", servicesActionID, "pay", http.StatusOK, []string{"svc-2=Payments API"}},
+		{"service names are truncated", servicesActionID, "xxx", http.StatusOK, []string{"svc-3=" + strings.Repeat("x", 74) + "…"}},
+		{"teams", service.TeamActionID, "", http.StatusOK, []string{"t-1=DBA", "t-2=Web"}},
+		{"lookup fails", servicesActionID, "", http.StatusInternalServerError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+					return
+				}
+				switch r.URL.Path {
+				case "/v1/services":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]string{
+						{"id": "svc-1", "name": "Checkout API"},
+						{"id": "svc-2", "name": "Payments API"},
+						{"id": "svc-3", "name": longName},
+					}})
+				case "/v2/teams":
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]string{
+						{"id": "t-2", "name": "Web"},
+						{"id": "t-1", "name": "DBA"},
+					}})
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			st := store.NewMemoryStore()
+			alertService := service.NewAlertServiceWithEndpoint("key", "team", "", server.URL, quietLogger())
+			app := newTestApp(st)
+			NewServiceOptionsHandler(service.NewServiceCatalog(alertService, st, quietLogger()), quietLogger()).Register(app)
+
+			resp := app.HandleInteraction(slack.InteractionCallback{
+				Type:     slack.InteractionTypeBlockSuggestion,
+				ActionID: tt.actionID,
+				Value:    tt.query,
+			})
+
+			options := resp.Body.(*slack.OptionsResponse).Options
+			var got []string
+			for _, option := range options {
+				got = append(got, option.Value+"="+option.Text.Text)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("options = %v, want %v", got, tt.want)
+			}
+			if options == nil {
+				t.Fatal("options = nil, want an empty list so Slack accepts the response")
+			}
+		})
+	}
+}
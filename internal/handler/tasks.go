@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/sirupsen/logrus"
+)
+
+const maxTaskBodySize = 1 << 20
+
+type TaskHandler struct {
+	jobs   *queue.Queue
+	secret func() string
+	logger *logrus.Logger
+}
+
+func NewTaskHandler(jobs *queue.Queue, secret func() string, logger *logrus.Logger) *TaskHandler {
+	return &TaskHandler{
+		jobs:   jobs,
+		secret: secret,
+		logger: logger,
+	}
+}
+
+func (h *TaskHandler) HandleJob(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTaskBodySize))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read job")
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	if !queue.VerifyJob(h.secret(), body, r.Header.Get(queue.JobSignatureHeader)) {
+		h.logger.Warn("Rejecting job with an invalid signature")
+		http.Error(w, "Verification failed", http.StatusUnauthorized)
+		return
+	}
+
+	var job queue.Job
+	if err := json.Unmarshal(body, &job); err != nil {
+		h.logger.WithError(err).Error("Failed to decode job")
+		http.Error(w, "Invalid job", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.jobs.Process(r.Context(), job); err != nil {
+		h.logger.WithError(err).WithField("job_id", job.ID).Error("Failed to process job")
+		http.Error(w, "Failed to process job", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+)
+
+func TestTaskHandlerRunsSignedJobs(t *testing.T) {
+	jobs := queue.NewWithBackend(queue.NewMemoryBackend(), 0, 3, quietLogger())
+	var ran []string
+	jobs.SetRunner(func(ctx context.Context, job queue.Job) error {
+		ran = append(ran, job.ID)
+		return nil
+	})
+	tasks := NewTaskHandler(jobs, func() string { return "secret" }, quietLogger())
+
+	body, err := json.Marshal(queue.Job{ID: "job-1", Type: "test.job"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		signature string
+		want      int
+	}{
+		{name: "unsigned", signature: "", want: http.StatusUnauthorized},
+		{name: "wrong secret", signature: queue.SignJob("other", body), want: http.StatusUnauthorized},
+		{name: "signed", signature: queue.SignJob("secret", body), want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/jobs", bytes.NewReader(body))
+			req.Header.Set(queue.JobSignatureHeader, tt.signature)
+			recorder := httptest.NewRecorder()
+
+			tasks.HandleJob(recorder, req)
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+
+	if len(ran) != 1 || ran[0] != "job-1" {
+		t.Fatalf("ran jobs %v, want [job-1]", ran)
+	}
+}
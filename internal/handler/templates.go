@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+func (a *IncidentApp) handleTemplatePicked(payload slack.InteractionCallback) *Response {
+	metadata := a.parseModalMetadata(payload.View.PrivateMetadata)
+
+	templateID := payload.View.State.Values[service.TemplatePickerBlockID][service.TemplatePickerActionID].SelectedOption.Value
+	template, _ := a.config.Template(templateID)
+
+	description := ""
+	if metadata.MessageTS != "" && metadata.ChannelID != "" {
+		message, err := a.slackService.GetMessage(metadata.ChannelID, metadata.MessageTS)
+		if err != nil {
+			a.logger.WithError(err).Warn("Failed to fetch original message for template")
+		} else {
+			description = message.Text
+		}
+	}
+
+	a.logger.WithFields(logrus.Fields{
+		"template": templateID,
+		"user":     payload.User.ID,
+	}).Debug("Opening incident form from template")
+
+	view := a.slackService.IncidentModal(service.IncidentForm{
+		Metadata:    metadata,
+		Description: description,
+		Template:    template,
+		Templates:   a.config.Templates,
+		Urgencies:   a.config.Urgencies,
+	})
+
+	return &Response{Body: slack.NewUpdateViewSubmissionResponse(&view)}
+}
+
+func (a *IncidentApp) applyTemplate(alert *model.Alert, template *config.IncidentTemplate, values map[string]map[string]slack.BlockAction) {
+	details := make(map[string]string, len(alert.Details)+len(template.Details))
+	for key, value := range alert.Details {
+		details[key] = value
+	}
+	for key, value := range template.Details {
+		details[key] = value
+	}
+	details["template"] = template.ID
+
+	alert.Tags = append(alert.Tags, template.Tags...)
+	alert.Actions = append(alert.Actions, template.Actions...)
+	if template.TeamID != "" {
+		alert.ResponderTeamID = template.TeamID
+	}
+	if template.Entity != "" {
+		alert.Entity = template.Entity
+	}
+
+	for _, field := range template.Fields {
+		action, ok := values[service.TemplateFieldBlockID(field.ID)][service.TemplateFieldActionID(field.ID)]
+		if !ok {
+			continue
+		}
+
+		fieldValues := a.templateFieldValues(field, action)
+		if len(fieldValues) == 0 {
+			continue
+		}
+
+		switch field.Target() {
+		case config.MapToTags:
+			alert.Tags = append(alert.Tags, fieldValues...)
+		case config.MapToPriority:
+			alert.Priority = model.AlertPriority(fieldValues[0])
+		case config.MapToEntity:
+			alert.Entity = fieldValues[0]
+		case config.MapToActions:
+			alert.Actions = append(alert.Actions, fieldValues...)
+		default:
+			details[field.DetailKey()] = strings.Join(fieldValues, ", ")
+		}
+	}
+
+	alert.Details = details
+}
+
+func (a *IncidentApp) templateFieldValues(field config.TemplateField, action slack.BlockAction) []string {
+	var values []string
+	switch field.Type {
+	case config.FieldTypeSelect:
+		values = append(values, action.SelectedOption.Value)
+	case config.FieldTypeMultiSelect, config.FieldTypeCheckbox:
+		for _, option := range action.SelectedOptions {
+			values = append(values, option.Value)
+		}
+	case config.FieldTypeDate:
+		values = append(values, action.SelectedDate)
+	case config.FieldTypeUser:
+		if action.SelectedUser != "" {
+			name, err := a.slackService.GetUserName(action.SelectedUser)
+			if err != nil {
+				a.logger.WithError(err).Warn("Failed to resolve template user field")
+				name = action.SelectedUser
+			}
+			values = append(values, name)
+		}
+	default:
+		values = append(values, strings.TrimSpace(action.Value))
+	}
+
+	filtered := values[:0]
+	for _, value := range values {
+		if value != "" {
+			filtered = append(filtered, value)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+func fieldValue(fieldID string, action slack.BlockAction) map[string]map[string]slack.BlockAction {
+	return map[string]map[string]slack.BlockAction{
+		service.TemplateFieldBlockID(fieldID): {service.TemplateFieldActionID(fieldID): action},
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	regions := []config.FieldOption{{Label: "EU", Value: "eu"}, {Label: "US", Value: "us"}}
+	selected := func(values ...string) []slack.OptionBlockObject {
+		options := make([]slack.OptionBlockObject, 0, len(values))
+		for _, value := range values {
+			options = append(options, slack.OptionBlockObject{Value: value})
+		}
+		return options
+	}
+
+	tests := []struct {
+		name   string
+		field  config.TemplateField
+		action slack.BlockAction
+		want   model.Alert
+	}{
+		{
+			name:   "text field maps to details",
+			field:  config.TemplateField{ID: "cluster", Type: config.FieldTypeText},
+			action: slack.BlockAction{Value: "  pg-main  "},
+			want:   model.Alert{Details: map[string]string{"cluster": "pg-main"}},
+		},
+		{
+			name:   "details key override",
+			field:  config.TemplateField{ID: "cluster", Type: config.FieldTypeText, Key: "dbCluster"},
+			action: slack.BlockAction{Value: "pg-main"},
+			want:   model.Alert{Details: map[string]string{"dbCluster": "pg-main"}},
+		},
+		{
+			name:   "empty field is skipped",
+			field:  config.TemplateField{ID: "cluster", Type: config.FieldTypeText},
+			action: slack.BlockAction{Value: "   "},
+			want:   model.Alert{Details: map[string]string{}},
+		},
+		{
+			name:   "multi select maps to tags",
+			field:  config.TemplateField{ID: "region", Type: config.FieldTypeMultiSelect, Options: regions, MapsTo: config.MapToTags},
+			action: slack.BlockAction{SelectedOptions: selected("eu", "us")},
+			want:   model.Alert{Tags: []string{"eu", "us"}, Details: map[string]string{}},
+		},
+		{
+			name:   "checkbox joins details",
+			field:  config.TemplateField{ID: "region", Type: config.FieldTypeCheckbox, Options: regions},
+			action: slack.BlockAction{SelectedOptions: selected("eu", "us")},
+			want:   model.Alert{Details: map[string]string{"region": "eu, us"}},
+		},
+		{
+			name:   "select maps to priority",
+			field:  config.TemplateField{ID: "severity", Type: config.FieldTypeSelect, MapsTo: config.MapToPriority},
+			action: slack.BlockAction{SelectedOption: slack.OptionBlockObject{Value: "P1"}},
+			want:   model.Alert{Priority: model.PriorityP1, Details: map[string]string{}},
+		},
+		{
+			name:   "text maps to entity",
+			field:  config.TemplateField{ID: "host", Type: config.FieldTypeText, MapsTo: config.MapToEntity},
+			action: slack.BlockAction{Value: "db-01"},
+			want:   model.Alert{Entity: "db-01", Details: map[string]string{}},
+		},
+		{
+			name:   "select maps to actions",
+			field:  config.TemplateField{ID: "runbook", Type: config.FieldTypeSelect, MapsTo: config.MapToActions},
+			action: slack.BlockAction{SelectedOption: slack.OptionBlockObject{Value: "Restart"}},
+			want:   model.Alert{Actions: []string{"Restart"}, Details: map[string]string{}},
+		},
+		{
+			name:   "date",
+			field:  config.TemplateField{ID: "since", Type: config.FieldTypeDate},
+			action: slack.BlockAction{SelectedDate: "2026-10-19"},
+			want:   model.Alert{Details: map[string]string{"since": "2026-10-19"}},
+		},
+		{
+			name:   "user is resolved to a name",
+			field:  config.TemplateField{ID: "owner", Type: config.FieldTypeUser},
+			action: slack.BlockAction{SelectedUser: "U2"},
+			want:   model.Alert{Details: map[string]string{"owner": "Jane Doe"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, slackService := newFakeSlackAPI(t, map[string]interface{}{
+				"/users.info": map[string]interface{}{"ok": true, "user": map[string]interface{}{"id": "U2", "real_name": "Jane Doe"}},
+			})
+			app := newTestApp(store.NewMemoryStore())
+			app.slackService = slackService
+
+			alert := &model.Alert{}
+			template := &config.IncidentTemplate{ID: "db-outage", Fields: []config.TemplateField{tt.field}}
+			app.applyTemplate(alert, template, fieldValue(tt.field.ID, tt.action))
+
+			tt.want.Details["template"] = "db-outage"
+			if !reflect.DeepEqual(*alert, tt.want) {
+				t.Fatalf("alert = %+v, want %+v", *alert, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTemplateDefaults(t *testing.T) {
+	app := newTestApp(store.NewMemoryStore())
+	alert := &model.Alert{
+		Tags:    []string{"slack-incident"},
+		Details: map[string]string{"slackChannelId": "C1", "runbook": "old"},
+	}
+	template := &config.IncidentTemplate{
+		ID:      "db-outage",
+		TeamID:  "team-dba",
+		Entity:  "postgres",
+		Tags:    []string{"database"},
+		Details: map[string]string{"runbook": "https://wiki/db"},
+		Actions: []string{"Failover"},
+	}
+
+	app.applyTemplate(alert, template, nil)
+
+	want := model.Alert{
+		Tags:            []string{"slack-incident", "database"},
+		Actions:         []string{"Failover"},
+		ResponderTeamID: "team-dba",
+		Entity:          "postgres",
+		Details:         map[string]string{"slackChannelId": "C1", "runbook": "https://wiki/db", "template": "db-outage"},
+	}
+	if !reflect.DeepEqual(*alert, want) {
+		t.Fatalf("alert = %+v, want %+v", *alert, want)
+	}
+}
+
+func TestTemplatePickedShowsTemplateForm(t *testing.T) {
+	app := newTestApp(store.NewMemoryStore())
+	app.config.Templates = []config.IncidentTemplate{{ID: "db-outage", Name: "Database outage", Title: "Database outage: "}}
+
+	resp := app.HandleInteraction(slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+		View: slack.View{
+			CallbackID: service.TemplatePickerCallbackID,
+			State: &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+				service.TemplatePickerBlockID: {service.TemplatePickerActionID: {SelectedOption: slack.OptionBlockObject{Value: "db-outage"}}},
+			}},
+		},
+	})
+
+	update, ok := resp.Body.(*slack.ViewSubmissionResponse)
+	if !ok || update.ResponseAction != slack.RAUpdate {
+		t.Fatalf("response = %#v, want a view update", resp.Body)
+	}
+	if update.View.CallbackID != service.IncidentModalCallbackID || update.View.Title.Text != "Database outage" {
+		t.Fatalf("view = %s %q, want the incident form titled after the template", update.View.CallbackID, update.View.Title.Text)
+	}
+}
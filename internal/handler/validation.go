@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/slack-go/slack"
+)
+
+const (
+	maxAlertDescriptionLength = 15000
+	maxAlertTags              = 20
+	maxAlertTagLength         = 50
+)
+
+var requiredFieldBlocks = map[string]string{
+	"description": "description_block",
+	"services":    "services_block",
+	"team":        service.TeamBlockID,
+	"impact":      service.ImpactBlockID,
+}
+
+func (a *IncidentApp) validateSubmission(alert *model.Alert, values map[string]map[string]slack.BlockAction) map[string]string {
+	errs := make(map[string]string)
+	rules := a.config.Validation
+
+	title := strings.TrimSpace(alert.Title)
+	switch {
+	case title == "":
+		errs["title_block"] = "Enter a title for the incident"
+	case utf8.RuneCountInString(alert.Title) > maxAlertMessageLength:
+		errs["title_block"] = fmt.Sprintf("Title must be at most %d characters (currently %d)",
+			maxAlertMessageLength, utf8.RuneCountInString(alert.Title))
+	case !rules.MatchTitle(title):
+		errs["title_block"] = "Title does not match the required format"
+		if rules.TitleHint != "" {
+			errs["title_block"] = rules.TitleHint
+		}
+	}
+
+	if length := utf8.RuneCountInString(alert.Description); length > maxAlertDescriptionLength {
+		errs["description_block"] = fmt.Sprintf("Description must be at most %d characters (currently %d)",
+			maxAlertDescriptionLength, length)
+	}
+
+	if tags := len(alert.Tags) + len(alert.ImpactedServices); tags > maxAlertTags {
+		errs["services_block"] = fmt.Sprintf("OpsGenie allows at most %d tags; remove %d impacted services",
+			maxAlertTags, tags-maxAlertTags)
+	}
+	for _, tag := range alert.Tags {
+		if utf8.RuneCountInString(tag) > maxAlertTagLength {
+			errs["title_block"] = fmt.Sprintf("Tag %q is longer than %d characters", tag, maxAlertTagLength)
+			break
+		}
+	}
+
+	for _, field := range rules.Required(string(alert.Priority)) {
+		blockID, ok := requiredFieldBlocks[field]
+		if !ok {
+			blockID = service.TemplateFieldBlockID(field)
+		}
+
+		block, present := values[blockID]
+		if !present {
+			continue
+		}
+		if _, failed := errs[blockID]; !failed && !hasInput(block) {
+			errs[blockID] = fmt.Sprintf("Required for %s incidents", alert.Priority)
+		}
+	}
+
+	return errs
+}
+
+func (a *IncidentApp) errorsResponse(errs map[string]string) *Response {
+	a.logger.WithField("errors", errs).Debug("Rejecting incident form submission")
+	return &Response{Body: slack.NewErrorsViewSubmissionResponse(errs)}
+}
+
+func hasInput(block map[string]slack.BlockAction) bool {
+	for _, action := range block {
+		if strings.TrimSpace(action.Value) != "" ||
+			action.SelectedOption.Value != "" ||
+			len(action.SelectedOptions) > 0 ||
+			action.SelectedDate != "" ||
+			action.SelectedUser != "" ||
+			len(action.SelectedUsers) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+const validationConfig = `
+validation:
+  title_pattern: '^\[[A-Z]+\] '
+  title_hint: Start the title with a component, e.g. [DB] Primary is down
+  required_fields:
+    P1: [description, services, team, impact, cluster]
+`
+
+func loadTestConfig(t *testing.T, yamlConfig string) *config.Config {
+	t.Helper()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(yamlConfig), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("BOT_CONFIG_FILE", configFile)
+	t.Setenv("SLACK_SIGNING_SECRET", "secret")
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("OPSGENIE_API_KEY", "opsgenie-test")
+	t.Setenv("OPSGENIE_TEAM_ID", "team-1")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	return cfg
+}
+
+func TestValidateSubmission(t *testing.T) {
+	cfg := loadTestConfig(t, validationConfig)
+	manyServices := make([]model.OpsGenieService, 20)
+	filled := map[string]map[string]slack.BlockAction{
+		"description_block":                     {"description": {Value: "Primary is not answering"}},
+		"services_block":                        {"services": {SelectedOptions: []slack.OptionBlockObject{{Value: "svc-1"}}}},
+		service.TeamBlockID:                     teamSelection("team-dba", "DBA"),
+		service.ImpactBlockID:                   {service.ImpactActionID: {Value: "Checkout is down"}},
+		service.TemplateFieldBlockID("cluster"): {service.TemplateFieldActionID("cluster"): {SelectedOption: slack.OptionBlockObject{Value: "pg-main"}}},
+	}
+	empty := map[string]map[string]slack.BlockAction{
+		"description_block":                     {"description": {Value: "  "}},
+		"services_block":                        {"services": {}},
+		service.TeamBlockID:                     {service.TeamActionID: {}},
+		service.ImpactBlockID:                   {service.ImpactActionID: {}},
+		service.TemplateFieldBlockID("cluster"): {service.TemplateFieldActionID("cluster"): {}},
+	}
+
+	tests := []struct {
+		name   string
+		alert  model.Alert
+		values map[string]map[string]slack.BlockAction
+		want   map[string]string
+	}{
+		{
+			name:  "valid",
+			alert: model.Alert{Title: "[DB] Primary is down", Priority: model.PriorityP2},
+			want:  map[string]string{},
+		},
+		{
+			name:  "empty title",
+			alert: model.Alert{Title: "   ", Priority: model.PriorityP2},
+			want:  map[string]string{"title_block": "Enter a title for the incident"},
+		},
+		{
+			name:  "title too long",
+			alert: model.Alert{Title: "[DB] " + strings.Repeat("x", 126), Priority: model.PriorityP2},
+			want:  map[string]string{"title_block": "Title must be at most 130 characters (currently 131)"},
+		},
+		{
+			name:  "title does not match the pattern",
+			alert: model.Alert{Title: "Primary is down", Priority: model.PriorityP2},
+			want:  map[string]string{"title_block": "Start the title with a component, e.g. [DB] Primary is down"},
+		},
+		{
+			name:  "description too long",
+			alert: model.Alert{Title: "[DB] Primary is down", Description: strings.Repeat("é", 15001), Priority: model.PriorityP2},
+			want:  map[string]string{"description_block": "Description must be at most 15000 characters (currently 15001)"},
+		},
+		{
+			name:  "too many tags with impacted services",
+			alert: model.Alert{Title: "[DB] Primary is down", Tags: []string{"slack-incident", "database"}, ImpactedServices: manyServices, Priority: model.PriorityP2},
+			want:  map[string]string{"services_block": "OpsGenie allows at most 20 tags; remove 2 impacted services"},
+		},
+		{
+			name:  "tag too long",
+			alert: model.Alert{Title: "[DB] Primary is down", Tags: []string{strings.Repeat("t", 51)}, Priority: model.PriorityP2},
+			want:  map[string]string{"title_block": fmt.Sprintf("Tag %q is longer than 50 characters", strings.Repeat("t", 51))},
+		},
+		{
+			name:   "required fields are filled",
+			alert:  model.Alert{Title: "[DB] Primary is down", Priority: model.PriorityP1},
+			values: filled,
+			want:   map[string]string{},
+		},
+		{
+			name:   "required fields are empty",
+			alert:  model.Alert{Title: "[DB] Primary is down", Priority: model.PriorityP1},
+			values: empty,
+			want: map[string]string{
+				"description_block":                     "Required for P1 incidents",
+				"services_block":                        "Required for P1 incidents",
+				service.TeamBlockID:                     "Required for P1 incidents",
+				service.ImpactBlockID:                   "Required for P1 incidents",
+				service.TemplateFieldBlockID("cluster"): "Required for P1 incidents",
+			},
+		},
+		{
+			name:   "required fields apply only to their priority",
+			alert:  model.Alert{Title: "[DB] Primary is down", Priority: model.PriorityP2},
+			values: empty,
+			want:   map[string]string{},
+		},
+		{
+			name:  "required fields missing from the form are skipped",
+			alert: model.Alert{Title: "[DB] Primary is down", Priority: model.PriorityP1},
+			want:  map[string]string{},
+		},
+		{
+			name:   "length errors are not replaced by required errors",
+			alert:  model.Alert{Title: "[DB] Primary is down", Description: strings.Repeat("x", 15001), Priority: model.PriorityP1},
+			values: map[string]map[string]slack.BlockAction{"description_block": {"description": {Value: strings.Repeat("x", 15001)}}},
+			want:   map[string]string{"description_block": "Description must be at most 15000 characters (currently 15001)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(store.NewMemoryStore())
+			app.config = cfg
+
+			got := app.validateSubmission(&tt.alert, tt.values)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("validateSubmission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		action slack.BlockAction
+		want   bool
+	}{
+		{"empty", slack.BlockAction{}, false},
+		{"whitespace", slack.BlockAction{Value: " \n"}, false},
+		{"text", slack.BlockAction{Value: "x"}, true},
+		{"option", slack.BlockAction{SelectedOption: slack.OptionBlockObject{Value: "x"}}, true},
+		{"options", slack.BlockAction{SelectedOptions: []slack.OptionBlockObject{{Value: "x"}}}, true},
+		{"date", slack.BlockAction{SelectedDate: "2026-10-19"}, true},
+		{"user", slack.BlockAction{SelectedUser: "U1"}, true},
+		{"users", slack.BlockAction{SelectedUsers: []string{"U1"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasInput(map[string]slack.BlockAction{"field": tt.action}); got != tt.want {
+				t.Fatalf("hasInput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/queue"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	connectCommand      = "connect"
+	notConnectedMessage = "This workspace is not connected to OpsGenie yet. Ask the person who installed the bot or a bot admin to run `/opsgenie connect <api-key> <opsgenie-team-id>`."
+)
+
+type WorkspaceAppFactory func(workspace *model.Workspace) (*IncidentApp, error)
+
+type WorkspaceRouter struct {
+	mu         sync.Mutex
+	workspaces *service.WorkspaceService
+	build      WorkspaceAppFactory
+	apps       map[string]workspaceApp
+	logger     *logrus.Logger
+}
+
+type workspaceApp struct {
+	fingerprint string
+	app         *IncidentApp
+}
+
+func NewWorkspaceRouter(workspaces *service.WorkspaceService, build WorkspaceAppFactory, logger *logrus.Logger) *WorkspaceRouter {
+	return &WorkspaceRouter{
+		workspaces: workspaces,
+		build:      build,
+		apps:       make(map[string]workspaceApp),
+		logger:     logger,
+	}
+}
+
+func (r *WorkspaceRouter) HandleCommand(cmd slack.SlashCommand) *Response {
+	app, workspace, err := r.appFor(cmd.TeamID)
+	if err != nil {
+		if errors.Is(err, service.ErrWorkspaceNotInstalled) {
+			return ephemeralResponse("This workspace has not installed the OpsGenie bot yet. Ask an admin to install it from the bot's /slack/install page.", nil)
+		}
+		r.logger.WithError(err).WithField("team_id", cmd.TeamID).Error("Failed to load workspace")
+		return ephemeralResponse("❌ Something went wrong. Please try again.", nil)
+	}
+
+	if isSubcommand(cmd, connectCommand) {
+		return app.handleConnectCommand(cmd, strings.Fields(cmd.Text)[1:], workspace, r.workspaces)
+	}
+	if !app.opsGenieConnected() && !isSubcommand(cmd, auditCommand) {
+		return ephemeralResponse(notConnectedMessage, nil)
+	}
+	return app.HandleCommand(cmd)
+}
+
+func (r *WorkspaceRouter) HandleInteraction(payload slack.InteractionCallback) *Response {
+	app, _, err := r.appFor(payload.Team.ID)
+	if err != nil {
+		r.logger.WithError(err).WithField("team_id", payload.Team.ID).Warn("Dropping interaction for unknown workspace")
+		return &Response{}
+	}
+	if !app.opsGenieConnected() {
+		if payload.Type != slack.InteractionTypeBlockSuggestion {
+			app.sendErrorMessage(payload.User.ID, notConnectedMessage)
+		}
+		return &Response{}
+	}
+	return app.HandleInteraction(payload)
+}
+
+func (r *WorkspaceRouter) HandleCallbackEvent(event slackevents.EventsAPIEvent, retryNum, retryReason string) error {
+	switch data := event.InnerEvent.Data.(type) {
+	case *slackevents.AppUninstalledEvent:
+		r.uninstall(event.TeamID)
+		return nil
+	case *slackevents.TokensRevokedEvent:
+		if len(data.Tokens.Bot) > 0 {
+			r.uninstall(event.TeamID)
+		}
+		return nil
+	}
+
+	app, _, err := r.appFor(event.TeamID)
+	if err != nil {
+		r.logger.WithError(err).WithField("team_id", event.TeamID).Warn("Dropping event for unknown workspace")
+		return nil
+	}
+	if !app.opsGenieConnected() {
+		r.logger.WithField("team_id", event.TeamID).Debug("Ignoring event for workspace without an OpsGenie connection")
+		return nil
+	}
+	return app.HandleCallbackEvent(event, retryNum, retryReason)
+}
+
+func (r *WorkspaceRouter) RunJob(ctx context.Context, job queue.Job) error {
+	app, _, err := r.appFor(job.TeamID)
+	if errors.Is(err, service.ErrWorkspaceNotInstalled) {
+		r.logger.WithFields(logrus.Fields{
+			"team_id":  job.TeamID,
+			"job_type": job.Type,
+		}).Warn("Dropping job for uninstalled workspace")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load workspace %s: %w", job.TeamID, err)
+	}
+	if !app.opsGenieConnected() && job.Type != auditJobType {
+		r.logger.WithFields(logrus.Fields{
+			"team_id":  job.TeamID,
+			"job_type": job.Type,
+		}).Warn("Dropping job for workspace without an OpsGenie connection")
+		return nil
+	}
+	return app.RunJob(ctx, job)
+}
+
+func (r *WorkspaceRouter) appFor(teamID string) (*IncidentApp, *model.Workspace, error) {
+	workspace, err := r.workspaces.Get(teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fingerprint := strings.Join([]string{
+		workspace.BotToken,
+		workspace.OpsGenieAPIKey,
+		workspace.OpsGenieTeamID,
+		workspace.OpsGenieDomain,
+	}, "\x00")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cached, ok := r.apps[teamID]; ok && cached.fingerprint == fingerprint {
+		return cached.app, workspace, nil
+	}
+
+	app, err := r.build(workspace)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.apps[teamID] = workspaceApp{fingerprint: fingerprint, app: app}
+	r.logger.WithField("team_id", teamID).Debug("Built workspace app")
+	return app, workspace, nil
+}
+
+func (r *WorkspaceRouter) uninstall(teamID string) {
+	r.mu.Lock()
+	delete(r.apps, teamID)
+	r.mu.Unlock()
+
+	if err := r.workspaces.Delete(teamID); err != nil {
+		r.logger.WithError(err).WithField("team_id", teamID).Error("Failed to remove uninstalled workspace")
+	}
+}
+
+func (a *IncidentApp) opsGenieConnected() bool {
+	return a.config.OpsGenieAPIKey != ""
+}
+
+func (a *IncidentApp) handleConnectCommand(
+	cmd slack.SlashCommand,
+	args []string,
+	workspace *model.Workspace,
+	workspaces *service.WorkspaceService,
+) *Response {
+	event := model.AuditEvent{
+		Action:    model.ActionConnect,
+		UserID:    cmd.UserID,
+		UserName:  cmd.UserName,
+		ChannelID: cmd.ChannelID,
+		Source:    model.SourceSlashCommand,
+	}
+
+	if cmd.UserID != workspace.InstalledBy &&
+		!a.permissions.IsMember(cmd.UserID, a.config.Audit.AdminUsers, a.config.Audit.AdminGroups) {
+		event.Outcome = model.AuditOutcomeDenied
+		a.audit.Record(event)
+		return ephemeralResponse("⛔ Only the person who installed the bot or a bot admin can connect OpsGenie.", nil)
+	}
+
+	if len(args) < 2 {
+		return ephemeralResponse("Usage: `/opsgenie connect <api-key> <opsgenie-team-id> [domain]`", nil)
+	}
+	apiKey, teamID, domain := args[0], args[1], ""
+	if len(args) > 2 {
+		domain = args[2]
+	}
+
+	if err := verifyOpsGenieTeam(apiKey, teamID, domain, a.logger); err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+		a.audit.Record(event)
+		return ephemeralResponse("❌ OpsGenie rejected the connection: "+err.Error(), nil)
+	}
+
+	if err := workspaces.ConnectOpsGenie(workspace.TeamID, apiKey, teamID, domain); err != nil {
+		a.logger.WithError(err).WithField("team_id", workspace.TeamID).Error("Failed to save OpsGenie connection")
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+		a.audit.Record(event)
+		return ephemeralResponse("❌ Failed to save the OpsGenie connection. Please try again.", nil)
+	}
+
+	event.Outcome = model.AuditOutcomeSuccess
+	a.audit.Record(event)
+	message := fmt.Sprintf("✅ This workspace now raises incidents for OpsGenie team `%s`.", teamID)
+	return ephemeralResponse("OpsGenie connected", []slack.Block{markdownSection(message)})
+}
+
+func verifyOpsGenieTeam(apiKey, teamID, domain string, logger *logrus.Logger) error {
+	teams, err := service.NewAlertServiceWithLogger(apiKey, teamID, domain, logger).ListTeams()
+	if err != nil {
+		logger.WithError(err).Warn("OpsGenie connection check failed")
+		return fmt.Errorf("could not list teams with this API key")
+	}
+	for _, team := range teams {
+		if team.ID == teamID {
+			return nil
+		}
+	}
+	return fmt.Errorf("team %s was not found", teamID)
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type AlertPriority string
 
 const (
@@ -7,22 +9,37 @@ const (
 	PriorityP2 AlertPriority = "P2"
 	PriorityP3 AlertPriority = "P3"
 	PriorityP4 AlertPriority = "P4"
+	PriorityP5 AlertPriority = "P5"
 )
 
 type Alert struct {
-	Title       string        `json:"title"`
-	Description string        `json:"description"`
-	Priority    AlertPriority `json:"priority"`
-	Source      string        `json:"source"`
-	Tags        []string      `json:"tags"`
-	Reporter    Reporter      `json:"reporter"`
-	Team        Team          `json:"team"`
+	Title            string            `json:"title"`
+	Description      string            `json:"description"`
+	Priority         AlertPriority     `json:"priority"`
+	Source           string            `json:"source"`
+	Tags             []string          `json:"tags"`
+	Reporter         Reporter          `json:"reporter"`
+	Team             Team              `json:"team"`
+	Details          map[string]string `json:"details,omitempty"`
+	ResponderTeamID  string            `json:"responderTeamId,omitempty"`
+	ImpactedServices []OpsGenieService `json:"impactedServices,omitempty"`
+	Entity           string            `json:"entity,omitempty"`
+	Actions          []string          `json:"actions,omitempty"`
+	Alias            string            `json:"alias,omitempty"`
+	ChannelID        string            `json:"-"`
 }
 
 type Reporter struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Username     string `json:"username"`
+	OpsGenieUser string `json:"opsgenieUser,omitempty"`
+}
+
+type OpsGenieUser struct {
 	ID       string `json:"id"`
-	Name     string `json:"name"`
 	Username string `json:"username"`
+	FullName string `json:"fullName"`
 }
 
 type Team struct {
@@ -32,11 +49,42 @@ type Team struct {
 
 type AlertCreationResult struct {
 	ID        string        `json:"id"`
+	TinyID    string        `json:"tinyId,omitempty"`
 	Title     string        `json:"title"`
 	Alias     string        `json:"alias"`
 	Priority  AlertPriority `json:"priority"`
 	URL       string        `json:"url"`
 	RequestID string        `json:"requestId"`
+	CreatedAt time.Time     `json:"createdAt,omitempty"`
+	Kind      string        `json:"kind,omitempty"`
+	Pending   bool          `json:"pending,omitempty"`
+}
+
+type AlertSummary struct {
+	ID        string        `json:"id"`
+	TinyID    string        `json:"tinyId"`
+	Message   string        `json:"message"`
+	Status    string        `json:"status"`
+	Priority  AlertPriority `json:"priority"`
+	Owner     string        `json:"owner"`
+	CreatedAt time.Time     `json:"createdAt"`
+	URL       string        `json:"url"`
+}
+
+type OpsGenieTeam struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type OpsGenieService struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type OnCall struct {
+	TeamName     string   `json:"teamName"`
+	ScheduleName string   `json:"scheduleName"`
+	Recipients   []string `json:"recipients"`
 }
 
 type AlertResponse struct {
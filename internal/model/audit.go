@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeDenied  = "denied"
+	AuditOutcomeLimited = "rate_limited"
+)
+
+type AuditEvent struct {
+	Time        time.Time     `json:"time"`
+	TeamID      string        `json:"teamId,omitempty"`
+	Action      string        `json:"action"`
+	Outcome     string        `json:"outcome"`
+	UserID      string        `json:"userId"`
+	UserName    string        `json:"userName,omitempty"`
+	ChannelID   string        `json:"channelId,omitempty"`
+	Source      string        `json:"source,omitempty"`
+	Priority    AlertPriority `json:"priority,omitempty"`
+	PayloadHash string        `json:"payloadHash,omitempty"`
+	OpsGenieID  string        `json:"opsgenieId,omitempty"`
+	TinyID      string        `json:"tinyId,omitempty"`
+	Kind        string        `json:"kind,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
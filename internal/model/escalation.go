@@ -0,0 +1,46 @@
+package model
+
+const (
+	ResponderTeam       = "team"
+	ResponderEscalation = "escalation"
+)
+
+type AlertRef struct {
+	ID       string        `json:"id"`
+	TinyID   string        `json:"tinyId,omitempty"`
+	Kind     string        `json:"kind,omitempty"`
+	Priority AlertPriority `json:"priority,omitempty"`
+}
+
+func (r AlertCreationResult) Ref() AlertRef {
+	return AlertRef{
+		ID:       r.ID,
+		TinyID:   r.TinyID,
+		Kind:     r.Kind,
+		Priority: r.Priority,
+	}
+}
+
+func (r AlertRef) Label() string {
+	if r.TinyID != "" {
+		return "#" + r.TinyID
+	}
+	return r.ID
+}
+
+type EscalationTarget struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type AddedResponder struct {
+	Target EscalationTarget `json:"target"`
+	UserID string           `json:"userId"`
+}
+
+type EscalationMetadata struct {
+	Alert       AlertRef `json:"alert"`
+	ChannelID   string   `json:"channelId,omitempty"`
+	ResponseURL string   `json:"responseUrl,omitempty"`
+}
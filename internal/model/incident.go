@@ -0,0 +1,37 @@
+package model
+
+const (
+	ResultKindAlert    = "alert"
+	ResultKindIncident = "incident"
+)
+
+type Incident struct {
+	Message            string            `json:"message"`
+	Description        string            `json:"description,omitempty"`
+	Priority           AlertPriority     `json:"priority"`
+	Tags               []string          `json:"tags,omitempty"`
+	Details            map[string]string `json:"details,omitempty"`
+	Responders         []Responder       `json:"responders,omitempty"`
+	ImpactedServices   []string          `json:"impactedServices,omitempty"`
+	NotifyStakeholders bool              `json:"notifyStakeholders"`
+	StatusPageEntry    *StatusPageEntry  `json:"statusPageEntry,omitempty"`
+	Note               string            `json:"note,omitempty"`
+}
+
+type Responder struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type StatusPageEntry struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+}
+
+const (
+	SourceSlashCommand    = "command"
+	SourceMessageShortcut = "shortcut"
+	SourceReaction        = "reaction"
+	SourceAppHome         = "home"
+	SourceButton          = "button"
+)
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	MaintenancePolicy      = "policy"
+	MaintenanceIntegration = "integration"
+
+	MaintenanceStateEnabled  = "enabled"
+	MaintenanceStateDisabled = "disabled"
+
+	MaintenanceStatusActive = "active"
+
+	AuditMaintenanceStart = "maintenance_start"
+	AuditMaintenanceStop  = "maintenance_stop"
+)
+
+type MaintenanceEntity struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type MaintenanceRule struct {
+	State  string            `json:"state"`
+	Entity MaintenanceEntity `json:"entity"`
+}
+
+type Maintenance struct {
+	ID          string            `json:"id"`
+	Description string            `json:"description"`
+	Status      string            `json:"status,omitempty"`
+	StartDate   time.Time         `json:"startDate"`
+	EndDate     time.Time         `json:"endDate"`
+	Rules       []MaintenanceRule `json:"rules,omitempty"`
+}
+
+type MaintenanceMetadata struct {
+	TeamID      string `json:"teamId,omitempty"`
+	ChannelID   string `json:"channelId,omitempty"`
+	ResponseURL string `json:"responseUrl,omitempty"`
+}
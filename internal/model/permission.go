@@ -0,0 +1,24 @@
+package model
+
+const (
+	ActionCreate      = "create"
+	ActionAck         = "ack"
+	ActionClose       = "close"
+	ActionEscalate    = "escalate"
+	ActionAssign      = "assign"
+	ActionMaintenance = "maintenance"
+	ActionAudit       = "audit"
+	ActionConnect     = "connect"
+)
+
+type AccessRequest struct {
+	UserID    string
+	ChannelID string
+	Action    string
+	Priority  AlertPriority
+}
+
+type AccessDecision struct {
+	Allowed bool
+	Message string
+}
@@ -14,6 +14,27 @@ type SlackCommand struct {
 	TriggerID   string `form:"trigger_id"`
 }
 
+func (c SlackCommand) ModalMetadata() ModalMetadata {
+	return ModalMetadata{
+		ChannelID:   c.ChannelID,
+		ChannelName: c.ChannelName,
+		TeamDomain:  c.TeamDomain,
+		ResponseURL: c.ResponseURL,
+		Source:      SourceSlashCommand,
+	}
+}
+
+type ModalMetadata struct {
+	ChannelID       string `json:"channelId"`
+	ChannelName     string `json:"channelName"`
+	TeamDomain      string `json:"teamDomain"`
+	MessageTS       string `json:"messageTs,omitempty"`
+	MessageAuthorID string `json:"messageAuthorId,omitempty"`
+	Source          string `json:"source,omitempty"`
+	TemplateID      string `json:"templateId,omitempty"`
+	ResponseURL     string `json:"responseUrl,omitempty"`
+}
+
 type ModalSubmission struct {
 	Type        string `json:"type"`
 	CallbackID  string `json:"callback_id"`
@@ -35,3 +56,9 @@ type ViewState struct {
 		} `json:"selected_option"`
 	} `json:"values"`
 }
+
+type IncidentChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
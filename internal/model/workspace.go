@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type Workspace struct {
+	TeamID         string    `json:"teamId"`
+	TeamName       string    `json:"teamName"`
+	EnterpriseID   string    `json:"enterpriseId,omitempty"`
+	AppID          string    `json:"appId"`
+	BotUserID      string    `json:"botUserId"`
+	BotToken       string    `json:"botToken"`
+	Scope          string    `json:"scope"`
+	InstalledBy    string    `json:"installedBy"`
+	InstalledAt    time.Time `json:"installedAt"`
+	OpsGenieAPIKey string    `json:"opsgenieApiKey,omitempty"`
+	OpsGenieTeamID string    `json:"opsgenieTeamId,omitempty"`
+	OpsGenieDomain string    `json:"opsgenieDomain,omitempty"`
+}
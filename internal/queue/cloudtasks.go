@@ -0,0 +1,177 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+)
+
+const (
+	JobSignatureHeader = "X-Job-Signature"
+
+	cloudTasksAPI       = "https://cloudtasks.googleapis.com/v2"
+	metadataHost        = "metadata.google.internal"
+	metadataTokenPath   = "/computeMetadata/v1/instance/service-accounts/default/token"
+	cloudTasksTimeout   = 5 * time.Second
+	metadataTokenLeeway = time.Minute
+	jobSignatureVersion = "v0="
+)
+
+type CloudTasksBackend struct {
+	mu       sync.Mutex
+	queue    string
+	url      string
+	secret   func() string
+	client   *http.Client
+	apiURL   string
+	tokenURL string
+	token    string
+	expires  time.Time
+}
+
+func NewCloudTasksBackend(tasksConfig config.CloudTasksConfig, secret func() string) *CloudTasksBackend {
+	return &CloudTasksBackend{
+		queue:    tasksConfig.Queue,
+		url:      tasksConfig.URL,
+		secret:   secret,
+		client:   &http.Client{Timeout: cloudTasksTimeout},
+		apiURL:   cloudTasksAPI,
+		tokenURL: metadataTokenURL(),
+	}
+}
+
+func metadataTokenURL() string {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = metadataHost
+	}
+	return "http://" + host + metadataTokenPath
+}
+
+func (b *CloudTasksBackend) Push(job Job) error {
+	return b.PushAfter(job, 0)
+}
+
+func (b *CloudTasksBackend) PushAfter(job Job, delay time.Duration) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	httpRequest := map[string]interface{}{
+		"url":        b.url,
+		"httpMethod": http.MethodPost,
+		"headers": map[string]string{
+			"Content-Type":     "application/json",
+			JobSignatureHeader: SignJob(b.secret(), body),
+		},
+		"body": body,
+	}
+	task := map[string]interface{}{
+		"name":        fmt.Sprintf("%s/tasks/%s-%d", b.queue, job.ID, job.Attempts),
+		"httpRequest": httpRequest,
+	}
+	if delay > 0 {
+		task["scheduleTime"] = time.Now().Add(delay).UTC().Format(time.RFC3339Nano)
+	}
+	payload, err := json.Marshal(map[string]interface{}{"task": task})
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cloudTasksTimeout)
+	defer cancel()
+
+	token, err := b.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.apiURL+"/"+b.queue+"/tasks", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create task request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("cloud tasks returned %d: %s", resp.StatusCode, data)
+	}
+	return nil
+}
+
+func (b *CloudTasksBackend) Pop(ctx context.Context) (Job, error) {
+	return Job{}, ErrPushOnly
+}
+
+func (b *CloudTasksBackend) Done(job Job) error {
+	return nil
+}
+
+func (b *CloudTasksBackend) accessToken(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.token != "" && time.Now().Before(b.expires) {
+		return b.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.tokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode access token: %w", err)
+	}
+
+	b.token = token.AccessToken
+	b.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - metadataTokenLeeway)
+	return b.token, nil
+}
+
+func SignJob(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return jobSignatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifyJob(secret string, body []byte, signature string) bool {
+	return secret != "" && hmac.Equal([]byte(SignJob(secret, body)), []byte(signature))
+}
@@ -0,0 +1,94 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type FileBackend struct {
+	dir    string
+	memory *MemoryBackend
+}
+
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+
+	b := &FileBackend{dir: dir, memory: NewMemoryBackend()}
+	if err := b.recover(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *FileBackend) Push(job Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	tmp := b.path(job.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if err := os.Rename(tmp, b.path(job.ID)); err != nil {
+		return fmt.Errorf("failed to store job: %w", err)
+	}
+
+	if err := b.memory.Push(job); err != nil {
+		os.Remove(b.path(job.ID))
+		return err
+	}
+	return nil
+}
+
+func (b *FileBackend) Pop(ctx context.Context) (Job, error) {
+	return b.memory.Pop(ctx)
+}
+
+func (b *FileBackend) Done(job Job) error {
+	if err := os.Remove(b.path(job.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove job: %w", err)
+	}
+	return nil
+}
+
+func (b *FileBackend) recover() error {
+	files, err := filepath.Glob(filepath.Join(b.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list pending jobs: %w", err)
+	}
+
+	var jobs []Job
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read pending job %s: %w", file, err)
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return fmt.Errorf("failed to decode pending job %s: %w", file, err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].EnqueuedAt.Before(jobs[j].EnqueuedAt)
+	})
+	for _, job := range jobs {
+		if err := b.memory.Push(job); err != nil {
+			return fmt.Errorf("failed to restore pending job %s: %w", job.ID, err)
+		}
+	}
+	return nil
+}
+
+func (b *FileBackend) path(id string) string {
+	return filepath.Join(b.dir, id+".json")
+}
@@ -0,0 +1,70 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+const lambdaInvokeTimeout = 5 * time.Second
+
+type LambdaInvoker interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+type LambdaEvent struct {
+	Job *Job `json:"job"`
+}
+
+type LambdaBackend struct {
+	client   LambdaInvoker
+	function string
+}
+
+func NewLambdaBackend(ctx context.Context, function string) (*LambdaBackend, error) {
+	if function == "" {
+		return nil, fmt.Errorf("lambda queue backend needs a function name")
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return NewLambdaBackendWithClient(lambda.NewFromConfig(cfg), function), nil
+}
+
+func NewLambdaBackendWithClient(client LambdaInvoker, function string) *LambdaBackend {
+	return &LambdaBackend{client: client, function: function}
+}
+
+func (b *LambdaBackend) Push(job Job) error {
+	payload, err := json.Marshal(LambdaEvent{Job: &job})
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lambdaInvokeTimeout)
+	defer cancel()
+
+	if _, err := b.client.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(b.function),
+		InvocationType: types.InvocationTypeEvent,
+		Payload:        payload,
+	}); err != nil {
+		return fmt.Errorf("failed to invoke %s: %w", b.function, err)
+	}
+	return nil
+}
+
+func (b *LambdaBackend) Pop(ctx context.Context) (Job, error) {
+	return Job{}, ErrPushOnly
+}
+
+func (b *LambdaBackend) Done(job Job) error {
+	return nil
+}
@@ -0,0 +1,35 @@
+package queue
+
+import "context"
+
+const memoryQueueSize = 1024
+
+type MemoryBackend struct {
+	jobs chan Job
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{jobs: make(chan Job, memoryQueueSize)}
+}
+
+func (b *MemoryBackend) Push(job Job) error {
+	select {
+	case b.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (b *MemoryBackend) Pop(ctx context.Context) (Job, error) {
+	select {
+	case job := <-b.jobs:
+		return job, nil
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
+
+func (b *MemoryBackend) Done(job Job) error {
+	return nil
+}
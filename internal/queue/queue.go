@@ -0,0 +1,242 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	retryBaseDelay = 2 * time.Second
+	retryMaxDelay  = time.Minute
+
+	ErrQueueFull      = errors.New("job queue is full")
+	ErrNoRunner       = errors.New("no job runner is set")
+	ErrUnknownJobType = errors.New("no handler for job type")
+	ErrPushOnly       = errors.New("backend delivers jobs itself and cannot be polled")
+
+	jobMetrics = expvar.NewMap("jobs")
+)
+
+type Job struct {
+	ID         string          `json:"id"`
+	TeamID     string          `json:"teamId,omitempty"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	EnqueuedAt time.Time       `json:"enqueuedAt"`
+}
+
+type Backend interface {
+	Push(job Job) error
+	Pop(ctx context.Context) (Job, error)
+	Done(job Job) error
+}
+
+type DelayedBackend interface {
+	PushAfter(job Job, delay time.Duration) error
+}
+
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+type RunnerFunc func(ctx context.Context, job Job) error
+
+type jobContextKey struct{}
+
+type Queue struct {
+	mu          sync.RWMutex
+	backend     Backend
+	workers     int
+	maxAttempts int
+	runner      RunnerFunc
+	logger      *logrus.Logger
+}
+
+func New(queueConfig config.QueueConfig, logger *logrus.Logger) (*Queue, error) {
+	var backend Backend
+	switch queueConfig.Backend {
+	case config.QueueBackendFile:
+		fileBackend, err := NewFileBackend(queueConfig.Path)
+		if err != nil {
+			return nil, err
+		}
+		backend = fileBackend
+	case config.QueueBackendMemory, "":
+		backend = NewMemoryBackend()
+	case config.QueueBackendCloudTasks:
+		return nil, fmt.Errorf("queue backend %q is only supported on Cloud Functions", queueConfig.Backend)
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", queueConfig.Backend)
+	}
+	return NewWithBackend(backend, queueConfig.Workers, queueConfig.MaxAttempts, logger), nil
+}
+
+func NewWithBackend(backend Backend, workers, maxAttempts int, logger *logrus.Logger) *Queue {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &Queue{
+		backend:     backend,
+		workers:     workers,
+		maxAttempts: maxAttempts,
+		logger:      logger,
+	}
+}
+
+func (q *Queue) SetRunner(fn RunnerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.runner = fn
+}
+
+func (q *Queue) Enqueue(teamID, jobType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s job: %w", jobType, err)
+	}
+
+	job := Job{
+		ID:         newJobID(),
+		TeamID:     teamID,
+		Type:       jobType,
+		Payload:    data,
+		EnqueuedAt: time.Now(),
+	}
+	jobMetrics.Add("enqueued", 1)
+
+	if err := q.backend.Push(job); err != nil {
+		jobMetrics.Add("rejected", 1)
+		return fmt.Errorf("failed to enqueue %s job: %w", jobType, err)
+	}
+	return nil
+}
+
+func (q *Queue) Start(ctx context.Context) {
+	if q.workers == 0 {
+		return
+	}
+
+	q.logger.WithField("workers", q.workers).Info("Starting job workers")
+	for i := 0; i < q.workers; i++ {
+		go q.work(ctx)
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		job, err := q.backend.Pop(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			q.logger.WithError(err).Error("Failed to take job from queue")
+			time.Sleep(time.Second)
+			continue
+		}
+
+		if err := q.run(ctx, &job); err == nil || job.Attempts >= q.maxAttempts {
+			if err := q.backend.Done(job); err != nil {
+				q.logger.WithError(err).WithField("job_id", job.ID).Error("Failed to mark job as done")
+			}
+			continue
+		}
+
+		delay := retryDelay(job.Attempts)
+		time.AfterFunc(delay, func() {
+			if err := q.backend.Push(job); err != nil {
+				q.logger.WithError(err).WithField("job_id", job.ID).Error("Failed to requeue job")
+			}
+		})
+	}
+}
+
+func (q *Queue) Process(ctx context.Context, job Job) error {
+	if err := q.run(ctx, &job); err == nil || job.Attempts >= q.maxAttempts {
+		return nil
+	}
+
+	delay := retryDelay(job.Attempts)
+	if delayed, ok := q.backend.(DelayedBackend); ok {
+		if err := delayed.PushAfter(job, delay); err != nil {
+			return fmt.Errorf("failed to requeue job %s: %w", job.ID, err)
+		}
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+	}
+	if err := q.backend.Push(job); err != nil {
+		return fmt.Errorf("failed to requeue job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) error {
+	q.mu.RLock()
+	fn := q.runner
+	q.mu.RUnlock()
+
+	logger := q.logger.WithFields(logrus.Fields{
+		"job_id":   job.ID,
+		"job_type": job.Type,
+		"team_id":  job.TeamID,
+	})
+
+	job.Attempts++
+	started := time.Now()
+	err := ErrNoRunner
+	if fn != nil {
+		err = fn(context.WithValue(ctx, jobContextKey{}, *job), *job)
+	}
+	logger = logger.WithFields(logrus.Fields{
+		"attempt":  job.Attempts,
+		"duration": time.Since(started).String(),
+		"queued":   started.Sub(job.EnqueuedAt).String(),
+	})
+
+	switch {
+	case err == nil:
+		jobMetrics.Add("succeeded", 1)
+		logger.Debug("Job finished")
+	case job.Attempts >= q.maxAttempts:
+		jobMetrics.Add("failed", 1)
+		logger.WithError(err).Error("Job failed, giving up")
+	default:
+		jobMetrics.Add("retried", 1)
+		logger.WithError(err).Warn("Job failed, retrying")
+	}
+	return err
+}
+
+func FromContext(ctx context.Context) (Job, bool) {
+	job, ok := ctx.Value(jobContextKey{}).(Job)
+	return job, ok
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay << (attempts - 1)
+	if delay > retryMaxDelay || delay <= 0 {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+func newJobID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,328 @@
+package queue
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return logger
+}
+
+func fastRetries(t *testing.T) {
+	t.Helper()
+	base, max := retryBaseDelay, retryMaxDelay
+	retryBaseDelay, retryMaxDelay = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { retryBaseDelay, retryMaxDelay = base, max })
+}
+
+type recordingBackend struct {
+	*MemoryBackend
+	mu      sync.Mutex
+	done    []Job
+	delayed []time.Duration
+	pushed  []Job
+}
+
+func newRecordingBackend() *recordingBackend {
+	return &recordingBackend{MemoryBackend: NewMemoryBackend()}
+}
+
+func (b *recordingBackend) Push(job Job) error {
+	b.mu.Lock()
+	b.pushed = append(b.pushed, job)
+	b.mu.Unlock()
+	return b.MemoryBackend.Push(job)
+}
+
+func (b *recordingBackend) Done(job Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.done = append(b.done, job)
+	return nil
+}
+
+func (b *recordingBackend) finished() []Job {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Job(nil), b.done...)
+}
+
+type delayedBackend struct {
+	recordingBackend
+}
+
+func (b *delayedBackend) PushAfter(job Job, delay time.Duration) error {
+	b.mu.Lock()
+	b.delayed = append(b.delayed, delay)
+	b.pushed = append(b.pushed, job)
+	b.mu.Unlock()
+	return nil
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRetryDelayBacksOffUpToTheMaximum(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 2 * time.Second},
+		{attempts: 2, want: 4 * time.Second},
+		{attempts: 5, want: 32 * time.Second},
+		{attempts: 6, want: time.Minute},
+		{attempts: 80, want: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestWorkerRetriesFailedJobsUntilTheySucceed(t *testing.T) {
+	fastRetries(t)
+	backend := newRecordingBackend()
+	q := NewWithBackend(backend, 1, 3, quietLogger())
+
+	var mu sync.Mutex
+	var attempts []int
+	q.SetRunner(func(ctx context.Context, job Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts = append(attempts, job.Attempts)
+		if len(attempts) < 3 {
+			return errors.New("opsgenie unavailable")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	if err := q.Enqueue("T1", "test.job", map[string]string{"k": "v"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	waitFor(t, func() bool { return len(backend.finished()) == 1 })
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Fatalf("attempts = %v, want [1 2 3]", attempts)
+	}
+}
+
+func TestWorkerGivesUpAfterMaxAttempts(t *testing.T) {
+	fastRetries(t)
+	backend := newRecordingBackend()
+	q := NewWithBackend(backend, 1, 2, quietLogger())
+
+	var mu sync.Mutex
+	runs := 0
+	q.SetRunner(func(ctx context.Context, job Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		runs++
+		return errors.New("always fails")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	if err := q.Enqueue("T1", "test.job", nil); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	waitFor(t, func() bool { return len(backend.finished()) == 1 })
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 2 {
+		t.Fatalf("job ran %d times, want 2", runs)
+	}
+	if done := backend.finished(); done[0].Attempts != 2 {
+		t.Fatalf("job finished after %d attempts, want 2", done[0].Attempts)
+	}
+}
+
+func TestProcessRequeuesThroughDelayedBackend(t *testing.T) {
+	backend := &delayedBackend{recordingBackend: *newRecordingBackend()}
+	q := NewWithBackend(backend, 0, 2, quietLogger())
+
+	var seen Job
+	q.SetRunner(func(ctx context.Context, job Job) error {
+		seen, _ = FromContext(ctx)
+		return errors.New("opsgenie unavailable")
+	})
+
+	job := Job{ID: "job-1", Type: "test.job"}
+	if err := q.Process(context.Background(), job); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if seen.ID != "job-1" || seen.Attempts != 1 {
+		t.Fatalf("runner context had job %+v, want job-1 on its first attempt", seen)
+	}
+	if len(backend.pushed) != 1 || backend.pushed[0].Attempts != 1 || backend.delayed[0] != retryBaseDelay {
+		t.Fatalf("requeued %+v after %v, want one retry after %s", backend.pushed, backend.delayed, retryBaseDelay)
+	}
+
+	if err := q.Process(context.Background(), backend.pushed[0]); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(backend.pushed) != 1 {
+		t.Fatalf("job was requeued after its last attempt")
+	}
+}
+
+func TestProcessWaitsBeforeRequeueingWithoutDelayedBackend(t *testing.T) {
+	fastRetries(t)
+	backend := newRecordingBackend()
+	q := NewWithBackend(backend, 0, 3, quietLogger())
+	q.SetRunner(func(ctx context.Context, job Job) error {
+		return errors.New("opsgenie unavailable")
+	})
+
+	if err := q.Process(context.Background(), Job{ID: "job-1"}); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(backend.pushed) != 1 || backend.pushed[0].Attempts != 1 {
+		t.Fatalf("pushed %+v, want the job once with one attempt", backend.pushed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := q.Process(ctx, Job{ID: "job-2"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Process() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestCloudTasksBackendCreatesSignedTasks(t *testing.T) {
+	var mu sync.Mutex
+	tokenRequests := 0
+	var tasks []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case metadataTokenPath:
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				t.Errorf("token request without Metadata-Flavor header")
+			}
+			tokenRequests++
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token-1", "expires_in": 3600})
+		case "/v2/projects/p/locations/l/queues/q/tasks":
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+			}
+			var body struct {
+				Task map[string]interface{} `json:"task"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			tasks = append(tasks, body.Task)
+			if len(tasks) == 3 {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+	backend := NewCloudTasksBackend(config.CloudTasksConfig{
+		Queue: "projects/p/locations/l/queues/q",
+		URL:   "https://example.com/jobs",
+	}, func() string { return "secret" })
+	backend.apiURL = server.URL + "/v2"
+
+	job := Job{ID: "job-1", Type: "test.job", Payload: json.RawMessage(`{"k":"v"}`)}
+	if err := backend.Push(job); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	job.Attempts = 1
+	if err := backend.PushAfter(job, time.Minute); err != nil {
+		t.Fatalf("PushAfter() error = %v", err)
+	}
+	if err := backend.PushAfter(job, time.Minute); err != nil {
+		t.Fatalf("PushAfter(duplicate) error = %v, want an existing task to count as queued", err)
+	}
+
+	if tokenRequests != 1 {
+		t.Fatalf("fetched %d access tokens, want 1 cached token", tokenRequests)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("created %d tasks, want 3", len(tasks))
+	}
+	if tasks[0]["name"] != "projects/p/locations/l/queues/q/tasks/job-1-0" || tasks[1]["name"] != "projects/p/locations/l/queues/q/tasks/job-1-1" {
+		t.Fatalf("task names = %v, %v", tasks[0]["name"], tasks[1]["name"])
+	}
+	if _, ok := tasks[0]["scheduleTime"]; ok {
+		t.Fatalf("immediate task has a scheduleTime")
+	}
+	if _, ok := tasks[1]["scheduleTime"]; !ok {
+		t.Fatalf("delayed task has no scheduleTime")
+	}
+
+	request := tasks[0]["httpRequest"].(map[string]interface{})
+	body, err := base64.StdEncoding.DecodeString(request["body"].(string))
+	if err != nil {
+		t.Fatalf("task body is not base64: %v", err)
+	}
+	signature := request["headers"].(map[string]interface{})[JobSignatureHeader].(string)
+	if request["url"] != "https://example.com/jobs" || !VerifyJob("secret", body, signature) {
+		t.Fatalf("task request = %v, want a signed POST to the jobs URL", request)
+	}
+	if !strings.Contains(string(body), `"id":"job-1"`) {
+		t.Fatalf("task body = %s, want the job", body)
+	}
+}
+
+func TestMetadataTokenURL(t *testing.T) {
+	t.Setenv("GCE_METADATA_HOST", "")
+	want := "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	if got := metadataTokenURL(); got != want {
+		t.Fatalf("metadataTokenURL() = %q, want %q", got, want)
+	}
+}
+
+func TestVerifyJob(t *testing.T) {
+	body := []byte(`{"id":"job-1"}`)
+	signature := SignJob("secret", body)
+
+	if !VerifyJob("secret", body, signature) {
+		t.Fatal("VerifyJob() rejected a valid signature")
+	}
+	if VerifyJob("other", body, signature) || VerifyJob("secret", []byte(`{"id":"job-2"}`), signature) {
+		t.Fatal("VerifyJob() accepted a signature for another secret or body")
+	}
+	if VerifyJob("", body, SignJob("", body)) {
+		t.Fatal("VerifyJob() accepted a job signed without a secret")
+	}
+}
@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+type SecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+type AWSProvider struct {
+	mu      sync.Mutex
+	clients map[string]SecretsManagerClient
+	load    func(ctx context.Context, region string) (SecretsManagerClient, error)
+}
+
+func NewAWSProvider() *AWSProvider {
+	return &AWSProvider{
+		clients: make(map[string]SecretsManagerClient),
+		load:    loadSecretsManagerClient,
+	}
+}
+
+func NewAWSProviderWithClient(client SecretsManagerClient) *AWSProvider {
+	return &AWSProvider{
+		clients: make(map[string]SecretsManagerClient),
+		load: func(context.Context, string) (SecretsManagerClient, error) {
+			return client, nil
+		},
+	}
+}
+
+func (p *AWSProvider) Fetch(ctx context.Context, secretID string) (string, error) {
+	client, err := p.client(ctx, awsRegion(secretID))
+	if err != nil {
+		return "", err
+	}
+
+	out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	if out.SecretBinary != nil {
+		return string(out.SecretBinary), nil
+	}
+	return "", fmt.Errorf("secret %s has no value", secretID)
+}
+
+func (p *AWSProvider) client(ctx context.Context, region string) (SecretsManagerClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, ok := p.clients[region]; ok {
+		return client, nil
+	}
+	client, err := p.load(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[region] = client
+	return client, nil
+}
+
+func loadSecretsManagerClient(ctx context.Context, region string) (SecretsManagerClient, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("AWS_REGION must be set")
+	}
+	return secretsmanager.NewFromConfig(cfg), nil
+}
+
+func awsRegion(secretID string) string {
+	if parts := strings.Split(secretID, ":"); len(parts) > 3 && parts[0] == "arn" && parts[3] != "" {
+		return parts[3]
+	}
+	return ""
+}
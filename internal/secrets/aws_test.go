@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+func TestAWSProviderFetchesSignedSecretValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "secretsmanager.GetSecretValue" {
+			t.Errorf("X-Amz-Target = %q", target)
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") {
+			t.Errorf("Authorization = %q, want a SigV4 signature for eu-west-1", auth)
+		}
+
+		var input struct {
+			SecretId string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Errorf("Decode() error = %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		switch input.SecretId {
+		case "slack-opsgenie-bot":
+			w.Write([]byte(`{"Name":"slack-opsgenie-bot","SecretString":"{\"bot_token\":\"xoxb-aws\"}"}`))
+		case "binary":
+			w.Write([]byte(`{"Name":"binary","SecretBinary":"aGVsbG8="}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer server.Close()
+
+	client := secretsmanager.New(secretsmanager.Options{
+		Region:       "eu-west-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  credentials.NewStaticCredentialsProvider("AKIDTEST", "secret", ""),
+	})
+	resolver := &Resolver{providers: map[string]Provider{SchemeAWS: NewAWSProviderWithClient(client)}}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "awssm://slack-opsgenie-bot#bot_token", want: "xoxb-aws"},
+		{ref: "awssm://binary", want: "hello"},
+		{ref: "awssm://missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, ok := resolver.Parse(tt.ref)
+			if !ok {
+				t.Fatalf("Parse(%q) failed", tt.ref)
+			}
+			got, err := resolver.Resolve(context.Background(), ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSRegionFromARN(t *testing.T) {
+	if got := awsRegion("arn:aws:secretsmanager:ap-south-1:123456789012:secret:bot-AbCdEf"); got != "ap-south-1" {
+		t.Fatalf("awsRegion(arn) = %q, want ap-south-1", got)
+	}
+	if got := awsRegion("slack-opsgenie-bot"); got != "" {
+		t.Fatalf("awsRegion(name) = %q, want the default region", got)
+	}
+}
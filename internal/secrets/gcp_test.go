@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGCPProviderUsesMetadataToken(t *testing.T) {
+	var mu sync.Mutex
+	tokenRequests := 0
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/computeMetadata/v1/instance/service-accounts/default/token" {
+			t.Errorf("metadata path = %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			t.Errorf("token request without Metadata-Flavor header")
+		}
+		mu.Lock()
+		tokenRequests++
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "metadata-token", "expires_in": 3600})
+	}))
+	defer metadata.Close()
+
+	var paths []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer metadata-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/v1/projects/demo/secrets/opsgenie/versions/latest:access":
+			payload := base64.StdEncoding.EncodeToString([]byte(`{"api_key":"gcp-key"}`))
+			json.NewEncoder(w).Encode(map[string]interface{}{"payload": map[string]string{"data": payload}})
+		case "/v1/projects/demo/secrets/slack/versions/2:access":
+			payload := base64.StdEncoding.EncodeToString([]byte("xoxb-gcp"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"payload": map[string]string{"data": payload}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(metadata.URL, "http://"))
+	resolver := NewResolverWithProviders(map[string]Provider{SchemeGCP: NewGCPProvider(api.URL)})
+
+	tests := []struct {
+		ref      string
+		want     string
+		wantPath string
+		wantErr  bool
+	}{
+		{"gcpsm://projects/demo/secrets/opsgenie#api_key", "gcp-key", "/v1/projects/demo/secrets/opsgenie/versions/latest:access", false},
+		{"gcpsm://projects/demo/secrets/slack/versions/2", "xoxb-gcp", "/v1/projects/demo/secrets/slack/versions/2:access", false},
+		{"gcpsm://projects/demo/secrets/missing", "", "/v1/projects/demo/secrets/missing/versions/latest:access", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, ok := resolver.Parse(tt.ref)
+			if !ok {
+				t.Fatalf("Parse(%q) failed", tt.ref)
+			}
+			got, err := resolver.Resolve(context.Background(), ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Resolve() = %q, want %q", got, tt.want)
+			}
+			mu.Lock()
+			last := paths[len(paths)-1]
+			mu.Unlock()
+			if last != tt.wantPath {
+				t.Fatalf("requested %s, want %s", last, tt.wantPath)
+			}
+		})
+	}
+
+	if tokenRequests != 1 {
+		t.Fatalf("fetched %d metadata tokens, want 1 cached token", tokenRequests)
+	}
+}
+
+func TestGCPProviderPrefersExplicitAccessToken(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer explicit-token" {
+			t.Errorf("Authorization = %q, want the explicit token", r.Header.Get("Authorization"))
+		}
+		payload := base64.StdEncoding.EncodeToString([]byte("value"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"payload": map[string]string{"data": payload}})
+	}))
+	defer api.Close()
+
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "explicit-token")
+	t.Setenv("GCE_METADATA_HOST", "127.0.0.1:1")
+
+	got, err := NewGCPProvider(api.URL).Fetch(context.Background(), "projects/demo/secrets/x")
+	if err != nil || got != "value" {
+		t.Fatalf("Fetch() = %q, %v, want value", got, err)
+	}
+}
@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SchemeFile  = "file"
+	SchemeVault = "vault"
+	SchemeGCP   = "gcpsm"
+	SchemeAWS   = "awssm"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+type Provider interface {
+	Fetch(ctx context.Context, path string) (string, error)
+}
+
+type Reference struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+func (r Reference) String() string {
+	ref := r.Scheme + "://" + r.Path
+	if r.Key != "" {
+		ref += "#" + r.Key
+	}
+	return ref
+}
+
+type Resolver struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+func NewResolver() *Resolver {
+	return NewResolverWithProviders(map[string]Provider{
+		SchemeFile:  FileProvider{},
+		SchemeVault: NewVaultProvider(os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_NAMESPACE")),
+		SchemeGCP:   NewGCPProvider(os.Getenv("GCP_SECRET_MANAGER_ENDPOINT")),
+		SchemeAWS:   NewAWSProvider(),
+	})
+}
+
+func NewResolverWithProviders(providers map[string]Provider) *Resolver {
+	r := &Resolver{providers: make(map[string]Provider, len(providers))}
+	for scheme, provider := range providers {
+		r.providers[scheme] = provider
+	}
+	return r
+}
+
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[scheme] = provider
+}
+
+func (r *Resolver) Parse(value string) (Reference, bool) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok {
+		return Reference{}, false
+	}
+
+	r.mu.RLock()
+	_, known := r.providers[scheme]
+	r.mu.RUnlock()
+	if !known {
+		return Reference{}, false
+	}
+
+	ref := Reference{Scheme: scheme, Path: rest}
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		ref.Path, ref.Key = rest[:i], rest[i+1:]
+	}
+	return ref, ref.Path != ""
+}
+
+func (r *Resolver) Resolve(ctx context.Context, ref Reference) (string, error) {
+	r.mu.RLock()
+	provider := r.providers[ref.Scheme]
+	r.mu.RUnlock()
+	if provider == nil {
+		return "", fmt.Errorf("no secret provider for %s://", ref.Scheme)
+	}
+
+	value, err := provider.Fetch(ctx, ref.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+	if ref.Key == "" {
+		return value, nil
+	}
+	return extractKey(value, ref)
+}
+
+type Static map[string]string
+
+func (s Static) Fetch(_ context.Context, path string) (string, error) {
+	value, ok := s[path]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", path)
+	}
+	return value, nil
+}
+
+type FileProvider struct{}
+
+func (FileProvider) Fetch(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func extractKey(value string, ref Reference) (string, error) {
+	secret := Reference{Scheme: ref.Scheme, Path: ref.Path}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object, cannot read key %q", secret, ref.Key)
+	}
+
+	field, ok := fields[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", secret, ref.Key)
+	}
+	if text, ok := field.(string); ok {
+		return text, nil
+	}
+
+	data, err := json.Marshal(field)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode key %q of %s: %w", ref.Key, secret, err)
+	}
+	return string(data), nil
+}
+
+func readJSON(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolverParse(t *testing.T) {
+	resolver := NewResolverWithProviders(map[string]Provider{SchemeVault: Static{}, SchemeFile: FileProvider{}})
+
+	tests := []struct {
+		value  string
+		want   Reference
+		wantOK bool
+	}{
+		{"vault://secret/data/opsgenie#api_key", Reference{Scheme: SchemeVault, Path: "secret/data/opsgenie", Key: "api_key"}, true},
+		{"file:///run/secrets/token", Reference{Scheme: SchemeFile, Path: "/run/secrets/token"}, true},
+		{"plain-value", Reference{}, false},
+		{"https://example.com/secret", Reference{}, false},
+		{"vault://#api_key", Reference{Scheme: SchemeVault, Key: "api_key"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := resolver.Parse(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("Parse() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Fatalf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("xoxb-file\r\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	jsonPath := filepath.Join(dir, "opsgenie.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"api_key":"file-key","retries":3}`+"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	resolver := NewResolverWithProviders(map[string]Provider{SchemeFile: FileProvider{}})
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"file://" + path, "xoxb-file", false},
+		{"file://" + jsonPath + "#api_key", "file-key", false},
+		{"file://" + jsonPath + "#retries", "3", false},
+		{"file://" + path + "#api_key", "", true},
+		{"file://" + filepath.Join(dir, "missing"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, ok := resolver.Parse(tt.ref)
+			if !ok {
+				t.Fatalf("Parse(%q) failed", tt.ref)
+			}
+			got, err := resolver.Resolve(context.Background(), ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultProviderReadsKVVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("X-Vault-Namespace") != "team-a" {
+			t.Errorf("X-Vault-Namespace = %q, want team-a", r.Header.Get("X-Vault-Namespace"))
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/opsgenie":
+			w.Write([]byte(`{"data":{"data":{"api_key":"kv2-key","team_id":"kv2-team"},"metadata":{"version":3}}}`))
+		case "/v1/kv/opsgenie":
+			w.Write([]byte(`{"data":{"api_key":"kv1-key"}}`))
+		case "/v1/kv/nested":
+			w.Write([]byte(`{"data":{"data":{"inner":"value"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewResolverWithProviders(map[string]Provider{
+		SchemeVault: NewVaultProvider(server.URL+"/", "vault-token", "team-a"),
+	})
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"KV v2 key", "vault://secret/data/opsgenie#api_key", "kv2-key", false},
+		{"KV v2 whole secret", "vault://secret/data/opsgenie", `{"api_key":"kv2-key","team_id":"kv2-team"}`, false},
+		{"KV v1 key", "vault://kv/opsgenie#api_key", "kv1-key", false},
+		{"KV v1 secret with a data field", "vault://kv/nested#data", `{"inner":"value"}`, false},
+		{"missing key", "vault://kv/opsgenie#team_id", "", true},
+		{"missing secret", "vault://kv/missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, ok := resolver.Parse(tt.ref)
+			if !ok {
+				t.Fatalf("Parse(%q) failed", tt.ref)
+			}
+			got, err := resolver.Resolve(context.Background(), ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultProviderRequiresAddressAndToken(t *testing.T) {
+	if _, err := NewVaultProvider("", "token", "").Fetch(context.Background(), "secret/data/x"); err == nil {
+		t.Fatal("Fetch() without VAULT_ADDR succeeded")
+	}
+	if _, err := NewVaultProvider("http://vault", "", "").Fetch(context.Background(), "secret/data/x"); err == nil {
+		t.Fatal("Fetch() without VAULT_TOKEN succeeded")
+	}
+}
+
+func TestVaultProviderRejectsBadToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewVaultProvider(server.URL, "wrong", "").Fetch(context.Background(), "secret/data/opsgenie"); err == nil {
+		t.Fatal("Fetch() with a rejected token succeeded")
+	}
+}
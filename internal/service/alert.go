@@ -3,21 +3,34 @@ package service
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	defaultOpsGenieEndpoint = "https://api.opsgenie.com"
+
+	alertPollAttempts = 6
+	alertPollInterval = 500 * time.Millisecond
+)
+
 type AlertService struct {
-	apiKey  string
-	teamID  string
-	baseURL string
-	domain  string
-	logger  *logrus.Logger
+	apiKey          string
+	teamID          string
+	baseURL         string
+	incidentBaseURL string
+	domain          string
+	client          *http.Client
+	logger          *logrus.Logger
 }
 
 func NewAlertService(apiKey, teamID string, domain string) *AlertService {
@@ -25,47 +38,80 @@ func NewAlertService(apiKey, teamID string, domain string) *AlertService {
 		domain = "app"
 	}
 	return &AlertService{
-		apiKey:  apiKey,
-		teamID:  teamID,
-		domain:  domain,
-		baseURL: "https://api.opsgenie.com/v2",
-		logger:  logrus.New(),
+		apiKey:          apiKey,
+		teamID:          teamID,
+		domain:          domain,
+		baseURL:         "https://api.opsgenie.com/v2",
+		incidentBaseURL: "https://api.opsgenie.com/v1",
+		client:          opsgenieHTTPClient,
+		logger:          logrus.New(),
 	}
 }
 
 func NewAlertServiceWithLogger(apiKey, teamID string, domain string, logger *logrus.Logger) *AlertService {
+	return NewAlertServiceWithEndpoint(apiKey, teamID, domain, defaultOpsGenieEndpoint, logger)
+}
+
+func NewAlertServiceWithEndpoint(apiKey, teamID, domain, endpoint string, logger *logrus.Logger) *AlertService {
 	if logger == nil {
 		logger = logrus.New()
 	}
 	if domain == "" {
 		domain = "app"
 	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
 	return &AlertService{
-		apiKey:  apiKey,
-		teamID:  teamID,
-		domain:  domain,
-		baseURL: "https://api.opsgenie.com/v2",
-		logger:  logger,
+		apiKey:          apiKey,
+		teamID:          teamID,
+		domain:          domain,
+		baseURL:         endpoint + "/v2",
+		incidentBaseURL: endpoint + "/v1",
+		client:          opsgenieHTTPClient,
+		logger:          logger,
 	}
 }
 
 func (s *AlertService) CreateAlert(alert model.Alert) (*model.AlertCreationResult, error) {
+	details := map[string]string{
+		"reportedBy":    alert.Reporter.Username,
+		"slackUserId":   alert.Reporter.ID,
+		"slackUsername": alert.Reporter.Name,
+	}
+	for key, value := range alert.Details {
+		details[key] = value
+	}
+
+	teamID := s.teamID
+	if alert.ResponderTeamID != "" {
+		teamID = alert.ResponderTeamID
+	}
+
+	alias := alert.Alias
+	if alias == "" {
+		alias = fmt.Sprintf("slack-incident-%s-%d", alert.Reporter.ID, time.Now().Unix())
+	}
+
 	payload := map[string]interface{}{
 		"message":     alert.Title,
 		"description": alert.Description,
 		"priority":    alert.Priority,
 		"responders": []map[string]string{{
 			"type": "team",
-			"id":   s.teamID,
+			"id":   teamID,
 		}},
-		"tags":   alert.Tags,
-		"source": alert.Source,
-		"alias":  fmt.Sprintf("slack-incident-%s-%d", alert.Reporter.ID, time.Now().Unix()),
-		"details": map[string]string{
-			"reportedBy":    alert.Reporter.Username,
-			"slackUserId":   alert.Reporter.ID,
-			"slackUsername": alert.Reporter.Name,
-		},
+		"tags":    alert.Tags,
+		"source":  alert.Source,
+		"alias":   alias,
+		"details": details,
+	}
+	if alert.Reporter.OpsGenieUser != "" {
+		payload["user"] = alert.Reporter.OpsGenieUser
+	}
+	if alert.Entity != "" {
+		payload["entity"] = alert.Entity
+	}
+	if len(alert.Actions) > 0 {
+		payload["actions"] = alert.Actions
 	}
 
 	jsonPayload, err := json.Marshal(payload)
@@ -83,8 +129,7 @@ func (s *AlertService) CreateAlert(alert model.Alert) (*model.AlertCreationResul
 	req.Header.Set("Authorization", "GenieKey "+s.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
@@ -112,23 +157,36 @@ func (s *AlertService) CreateAlert(alert model.Alert) (*model.AlertCreationResul
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	time.Sleep(2 * time.Second)
-
-	alertDetails, err := s.getAlertByRequestID(response.RequestID)
+	alertDetails, err := s.pollAlertRequest(response.RequestID)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get alert details")
 		return &model.AlertCreationResult{
-			ID:        response.RequestID,
 			Title:     alert.Title,
 			Priority:  alert.Priority,
-			URL:       fmt.Sprintf("https://%s.app.opsgenie.com/alert/detail/%s/details", s.domain, response.RequestID),
 			RequestID: response.RequestID,
+			Kind:      model.ResultKindAlert,
+			Pending:   true,
 		}, nil
 	}
 
 	return alertDetails, nil
 }
 
+func (s *AlertService) pollAlertRequest(requestID string) (*model.AlertCreationResult, error) {
+	var lastErr error
+	for attempt := 1; attempt <= alertPollAttempts; attempt++ {
+		time.Sleep(alertPollInterval)
+
+		result, err := s.getAlertByRequestID(requestID)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+		s.logger.WithError(err).WithField("attempt", attempt).Debug("Alert request not processed yet")
+	}
+	return nil, fmt.Errorf("alert request %s was not processed: %w", requestID, lastErr)
+}
+
 func (s *AlertService) getAlertByRequestID(requestID string) (*model.AlertCreationResult, error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/alerts/requests/%s", s.baseURL, requestID), nil)
 	if err != nil {
@@ -137,8 +195,7 @@ func (s *AlertService) getAlertByRequestID(requestID string) (*model.AlertCreati
 
 	req.Header.Set("Authorization", "GenieKey "+s.apiKey)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -172,8 +229,7 @@ func (s *AlertService) getAlertDetails(alertID string) (*model.AlertCreationResu
 
 	req.Header.Set("Authorization", "GenieKey "+s.apiKey)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -181,10 +237,11 @@ func (s *AlertService) getAlertDetails(alertID string) (*model.AlertCreationResu
 
 	var response struct {
 		Data struct {
-			ID       string `json:"id"`
-			Message  string `json:"message"`
-			Priority string `json:"priority"`
-			Tiny     string `json:"tinyId"`
+			ID        string    `json:"id"`
+			Message   string    `json:"message"`
+			Priority  string    `json:"priority"`
+			Tiny      string    `json:"tinyId"`
+			CreatedAt time.Time `json:"createdAt"`
 		} `json:"data"`
 	}
 
@@ -192,15 +249,138 @@ func (s *AlertService) getAlertDetails(alertID string) (*model.AlertCreationResu
 		return nil, err
 	}
 
-	alertURL := fmt.Sprintf("https://%s.app.opsgenie.com/alert/detail/%s/details",
-		s.domain,
-		response.Data.ID,
-	)
-
 	return &model.AlertCreationResult{
-		ID:       response.Data.ID,
-		Title:    response.Data.Message,
-		Priority: model.AlertPriority(response.Data.Priority),
-		URL:      alertURL,
+		ID:        response.Data.ID,
+		TinyID:    response.Data.Tiny,
+		Title:     response.Data.Message,
+		Priority:  model.AlertPriority(response.Data.Priority),
+		URL:       s.alertURL(response.Data.ID),
+		CreatedAt: response.Data.CreatedAt,
+		Kind:      model.ResultKindAlert,
 	}, nil
 }
+
+func (s *AlertService) ListAlerts(query string, limit int) ([]model.AlertSummary, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("sort", "createdAt")
+	params.Set("order", "desc")
+
+	var response struct {
+		Data []struct {
+			ID        string    `json:"id"`
+			TinyID    string    `json:"tinyId"`
+			Message   string    `json:"message"`
+			Status    string    `json:"status"`
+			Priority  string    `json:"priority"`
+			Owner     string    `json:"owner"`
+			CreatedAt time.Time `json:"createdAt"`
+		} `json:"data"`
+	}
+
+	if err := s.doRequest(http.MethodGet, "/alerts?"+params.Encode(), nil, &response); err != nil {
+		return nil, fmt.Errorf("error listing alerts: %w", err)
+	}
+
+	alerts := make([]model.AlertSummary, 0, len(response.Data))
+	for _, data := range response.Data {
+		alerts = append(alerts, model.AlertSummary{
+			ID:        data.ID,
+			TinyID:    data.TinyID,
+			Message:   data.Message,
+			Status:    data.Status,
+			Priority:  model.AlertPriority(data.Priority),
+			Owner:     data.Owner,
+			CreatedAt: data.CreatedAt,
+			URL:       s.alertURL(data.ID),
+		})
+	}
+
+	return alerts, nil
+}
+
+func (s *AlertService) AddDetails(alertID string, details map[string]string) error {
+	payload := map[string]interface{}{
+		"details": details,
+	}
+
+	path := fmt.Sprintf("/alerts/%s/details?identifierType=id", url.PathEscape(alertID))
+	if err := s.doRequest(http.MethodPost, path, payload, nil); err != nil {
+		return fmt.Errorf("error adding alert details: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AlertService) alertURL(alertID string) string {
+	return fmt.Sprintf("https://%s.app.opsgenie.com/alert/detail/%s/details", s.domain, alertID)
+}
+
+func (s *AlertService) doRequest(method, path string, payload interface{}, out interface{}) error {
+	return s.do(method, s.baseURL+path, payload, out)
+}
+
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+func (s *AlertService) do(method, requestURL string, payload interface{}, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		jsonPayload, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error marshaling request: %w", err)
+		}
+		body = bytes.NewBuffer(jsonPayload)
+	}
+
+	req, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "GenieKey "+s.apiKey)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"method":      method,
+		"url":         requestURL,
+		"status_code": resp.StatusCode,
+	}).Debug("OpsGenie API response")
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+	}
+
+	return nil
+}
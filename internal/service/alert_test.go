@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+)
+
+func TestCreateAlertUsesGivenAlias(t *testing.T) {
+	var aliases []string
+	s := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/alerts":
+			var payload struct {
+				Alias string `json:"alias"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("failed to decode alert: %v", err)
+			}
+			aliases = append(aliases, payload.Alias)
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]string{"requestId": "req-1"})
+		case strings.HasPrefix(r.URL.Path, "/v2/alerts/requests/"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"success": true, "alertId": "alert-1"}})
+		case r.URL.Path == "/v2/alerts/alert-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"id": "alert-1", "tinyId": "7"}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	alert := model.Alert{Title: "Database down", Priority: model.PriorityP2, Reporter: model.Reporter{ID: "U1"}}
+	if _, err := s.CreateAlert(alert); err != nil {
+		t.Fatalf("CreateAlert() error = %v", err)
+	}
+	alert.Alias = "slack-job-abc"
+	if _, err := s.CreateAlert(alert); err != nil {
+		t.Fatalf("CreateAlert() error = %v", err)
+	}
+
+	if len(aliases) != 2 || !strings.HasPrefix(aliases[0], "slack-incident-U1-") || aliases[1] != "slack-job-abc" {
+		t.Fatalf("aliases = %q, want a generated alias and then slack-job-abc", aliases)
+	}
+}
+
+func TestAcknowledgeAndClose(t *testing.T) {
+	alert := model.AlertRef{ID: "alert-1", Kind: model.ResultKindAlert}
+	incident := model.AlertRef{ID: "incident-1", Kind: model.ResultKindIncident}
+
+	tests := []struct {
+		name     string
+		call     func(s *AlertService) error
+		wantPath string
+		wantUser string
+		wantErr  error
+	}{
+		{"acknowledge alert", func(s *AlertService) error { return s.Acknowledge(alert, "jane@example.com") }, "/v2/alerts/alert-1/acknowledge", "jane@example.com", nil},
+		{"close alert", func(s *AlertService) error { return s.Close(alert, "") }, "/v2/alerts/alert-1/close", "", nil},
+		{"close incident", func(s *AlertService) error { return s.Close(incident, "jane@example.com") }, "/v1/incidents/incident-1/close", "", nil},
+		{"acknowledge incident", func(s *AlertService) error { return s.Acknowledge(incident, "") }, "", "", ErrAckNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			var user string
+			s := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+				var payload struct {
+					User string `json:"user"`
+				}
+				json.NewDecoder(r.Body).Decode(&payload)
+				paths = append(paths, r.URL.Path)
+				user = payload.User
+				if r.Method != http.MethodPost || r.URL.Query().Get("identifierType") != "id" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL)
+				}
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"result":"Request will be processed","requestId":"req-1"}`))
+			})
+
+			err := tt.call(s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantPath == "" {
+				if len(paths) != 0 {
+					t.Fatalf("called OpsGenie %v, want no calls", paths)
+				}
+				return
+			}
+			if len(paths) != 1 || paths[0] != tt.wantPath {
+				t.Fatalf("called %v, want %s", paths, tt.wantPath)
+			}
+			if user != tt.wantUser {
+				t.Fatalf("user = %q, want %q", user, tt.wantUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	EscalateActionID      = "escalate_alert"
+	TakeOwnershipActionID = "take_ownership"
+	AcknowledgeActionID   = "acknowledge_alert"
+	CloseActionID         = "close_alert"
+
+	alertActionsBlockID = "alert_actions"
+	alertStatusBlockID  = "alert_status"
+
+	announcementKeyPrefix = "announcements:"
+	respondersKeyPrefix   = "responders:"
+	ownerKeyPrefix        = "owner:"
+	stateKeyPrefix        = "state:"
+	ephemeralKeyPrefix    = "ephemeral:"
+	announcementTTL       = 30 * 24 * time.Hour
+
+	announcementLockPrefix = "lock:announcements:"
+	announcementLockTTL    = 30 * time.Second
+	announcementLockWait   = 10 * time.Second
+)
+
+type AnnouncementService struct {
+	slackService *SlackService
+	store        store.Store
+	logger       *logrus.Logger
+}
+
+type alertState struct {
+	Action string `json:"action"`
+	UserID string `json:"userId"`
+}
+
+type announcement struct {
+	ChannelID   string       `json:"channelId"`
+	MessageTS   string       `json:"messageTs"`
+	ThreadTS    string       `json:"threadTs,omitempty"`
+	ResponseURL string       `json:"responseUrl,omitempty"`
+	Text        string       `json:"text"`
+	Blocks      slack.Blocks `json:"blocks"`
+}
+
+func NewAnnouncementService(slackService *SlackService, store store.Store, logger *logrus.Logger) *AnnouncementService {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &AnnouncementService{
+		slackService: slackService,
+		store:        store,
+		logger:       logger,
+	}
+}
+
+func AlertActionsBlock(ref model.AlertRef) slack.Block {
+	value, err := json.Marshal(ref)
+	if err != nil {
+		return nil
+	}
+
+	var buttons []slack.BlockElement
+	if ref.Kind != model.ResultKindIncident {
+		buttons = append(buttons, slack.NewButtonBlockElement(TakeOwnershipActionID, string(value),
+			slack.NewTextBlockObject(slack.PlainTextType, "I'm on it", true, false)).
+			WithStyle(slack.StylePrimary))
+		buttons = append(buttons, slack.NewButtonBlockElement(AcknowledgeActionID, string(value),
+			slack.NewTextBlockObject(slack.PlainTextType, "Acknowledge", true, false)))
+	}
+	buttons = append(buttons, slack.NewButtonBlockElement(EscalateActionID, string(value),
+		slack.NewTextBlockObject(slack.PlainTextType, "Escalate", true, false)))
+	buttons = append(buttons, slack.NewButtonBlockElement(CloseActionID, string(value),
+		slack.NewTextBlockObject(slack.PlainTextType, "Close", true, false)).
+		WithStyle(slack.StyleDanger).
+		WithConfirm(slack.NewConfirmationBlockObject(
+			slack.NewTextBlockObject(slack.PlainTextType, "Close "+ref.Label()+"?", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "This closes it in OpsGenie for everyone.", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Close", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false))))
+
+	return slack.NewActionBlock(alertActionsBlockID, buttons...)
+}
+
+func (s *AnnouncementService) Post(ref model.AlertRef, channelID, threadTS, text string, blocks []slack.Block) (string, error) {
+	if actions := AlertActionsBlock(ref); actions != nil {
+		blocks = append(blocks, actions)
+	}
+
+	unlock, err := s.lock(ref.ID)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	posted := blocks
+	if status, ok, err := s.status(ref.ID); err != nil {
+		s.logger.WithError(err).Warn("Failed to read alert status")
+	} else if ok {
+		posted = withStatus(blocks, status)
+	}
+
+	channel, ts, err := s.slackService.PostMessageWithChannel(channelID, threadTS, text, posted)
+	if err != nil {
+		return "", err
+	}
+
+	var announcements []announcement
+	if err := s.load(announcementKeyPrefix+ref.ID, &announcements); err != nil {
+		s.logger.WithError(err).Warn("Failed to read alert announcements")
+	}
+	announcements = append(announcements, announcement{
+		ChannelID: channel,
+		MessageTS: ts,
+		ThreadTS:  threadTS,
+		Text:      text,
+		Blocks:    slack.Blocks{BlockSet: blocks},
+	})
+	if err := s.save(announcementKeyPrefix+ref.ID, announcements); err != nil {
+		s.logger.WithError(err).Warn("Failed to record alert announcement")
+	}
+
+	return ts, nil
+}
+
+func (s *AnnouncementService) RememberEphemeral(alertID, userID, text string, blocks []slack.Block) error {
+	return s.save(ephemeralKey(alertID, userID), announcement{
+		Text:   text,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	})
+}
+
+func (s *AnnouncementService) Track(alertID string, payload slack.InteractionCallback) error {
+	clicked, ok, err := s.clickedMessage(alertID, payload)
+	if err != nil || !ok {
+		return err
+	}
+
+	unlock, err := s.lock(alertID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var announcements []announcement
+	if err := s.load(announcementKeyPrefix+alertID, &announcements); err != nil {
+		return err
+	}
+	for i, posted := range announcements {
+		if posted.ChannelID == clicked.ChannelID && posted.MessageTS == clicked.MessageTS {
+			if clicked.ResponseURL == "" {
+				return nil
+			}
+			announcements[i].ResponseURL = clicked.ResponseURL
+			return s.save(announcementKeyPrefix+alertID, announcements)
+		}
+	}
+	return s.save(announcementKeyPrefix+alertID, append(announcements, clicked))
+}
+
+func (s *AnnouncementService) clickedMessage(alertID string, payload slack.InteractionCallback) (announcement, bool, error) {
+	clicked := announcement{
+		ChannelID: payload.Container.ChannelID,
+		MessageTS: payload.Container.MessageTs,
+		ThreadTS:  payload.Message.ThreadTimestamp,
+		Text:      payload.Message.Text,
+		Blocks:    slack.Blocks{BlockSet: withoutStatus(payload.Message.Blocks.BlockSet)},
+	}
+	if clicked.ChannelID == "" {
+		clicked.ChannelID = payload.Channel.ID
+	}
+	if clicked.MessageTS == "" {
+		clicked.MessageTS = payload.Message.Timestamp
+	}
+
+	if payload.Container.IsEphemeral {
+		if payload.ResponseURL == "" {
+			return announcement{}, false, nil
+		}
+		var remembered announcement
+		if err := s.load(ephemeralKey(alertID, payload.User.ID), &remembered); err != nil {
+			return announcement{}, false, err
+		}
+		if len(clicked.Blocks.BlockSet) == 0 {
+			clicked.Text = remembered.Text
+			clicked.Blocks = remembered.Blocks
+		}
+		clicked.ResponseURL = payload.ResponseURL
+	}
+
+	if clicked.ChannelID == "" || clicked.MessageTS == "" || len(clicked.Blocks.BlockSet) == 0 {
+		return announcement{}, false, nil
+	}
+	return clicked, true, nil
+}
+
+func (s *AnnouncementService) AddResponder(alertID string, responder model.AddedResponder) error {
+	unlock, err := s.lock(alertID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var responders []model.AddedResponder
+	if err := s.load(respondersKeyPrefix+alertID, &responders); err != nil {
+		return err
+	}
+	responders = append(responders, responder)
+	if err := s.save(respondersKeyPrefix+alertID, responders); err != nil {
+		return err
+	}
+
+	_, err = s.refresh(alertID)
+	return err
+}
+
+func (s *AnnouncementService) SetOwner(alertID, userID string) error {
+	unlock, err := s.lock(alertID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.save(ownerKeyPrefix+alertID, userID); err != nil {
+		return err
+	}
+
+	announcements, err := s.refresh(alertID)
+	if err != nil {
+		return err
+	}
+
+	s.reply(alertID, announcements, fmt.Sprintf("🙋 <@%s> took ownership", userID))
+	return nil
+}
+
+func (s *AnnouncementService) SetState(alertID, action, userID string) error {
+	unlock, err := s.lock(alertID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.save(stateKeyPrefix+alertID, alertState{Action: action, UserID: userID}); err != nil {
+		return err
+	}
+
+	announcements, err := s.refresh(alertID)
+	if err != nil {
+		return err
+	}
+
+	s.reply(alertID, announcements, stateLine(alertState{Action: action, UserID: userID}))
+	return nil
+}
+
+func (s *AnnouncementService) reply(alertID string, announcements []announcement, text string) {
+	for _, posted := range announcements {
+		if posted.ResponseURL != "" {
+			continue
+		}
+		threadTS := posted.ThreadTS
+		if threadTS == "" {
+			threadTS = posted.MessageTS
+		}
+		if _, err := s.slackService.PostMessage(posted.ChannelID, threadTS, text, nil); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"alert_id":   alertID,
+				"channel_id": posted.ChannelID,
+			}).Warn("Failed to reply to alert announcement")
+		}
+	}
+}
+
+func (s *AnnouncementService) status(alertID string) (slack.Block, bool, error) {
+	var responders []model.AddedResponder
+	if err := s.load(respondersKeyPrefix+alertID, &responders); err != nil {
+		return nil, false, err
+	}
+	var owner string
+	if err := s.load(ownerKeyPrefix+alertID, &owner); err != nil {
+		return nil, false, err
+	}
+	var state alertState
+	if err := s.load(stateKeyPrefix+alertID, &state); err != nil {
+		return nil, false, err
+	}
+	if owner == "" && len(responders) == 0 && state.Action == "" {
+		return nil, false, nil
+	}
+	return statusBlock(state, owner, responders), true, nil
+}
+
+func (s *AnnouncementService) refresh(alertID string) ([]announcement, error) {
+	status, ok, err := s.status(alertID)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var announcements []announcement
+	if err := s.load(announcementKeyPrefix+alertID, &announcements); err != nil {
+		return nil, err
+	}
+
+	for _, posted := range announcements {
+		blocks := withStatus(posted.Blocks.BlockSet, status)
+		var err error
+		if posted.ResponseURL != "" {
+			err = s.slackService.ReplaceOriginalMessage(posted.ResponseURL, posted.Text, blocks)
+		} else {
+			err = s.slackService.UpdateMessage(posted.ChannelID, posted.MessageTS, posted.Text, blocks)
+		}
+		if err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"alert_id":   alertID,
+				"channel_id": posted.ChannelID,
+			}).Warn("Failed to update alert announcement")
+		}
+	}
+	return announcements, nil
+}
+
+func (s *AnnouncementService) lock(alertID string) (func(), error) {
+	release, err := store.Lock(s.store, announcementLockPrefix+alertID, announcementLockTTL, announcementLockWait)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := release(); err != nil {
+			s.logger.WithError(err).WithField("alert_id", alertID).Warn("Failed to release alert announcement lock")
+		}
+	}, nil
+}
+
+func ephemeralKey(alertID, userID string) string {
+	return ephemeralKeyPrefix + alertID + ":" + userID
+}
+
+func (s *AnnouncementService) load(key string, out interface{}) error {
+	data, ok, err := s.store.Get(key)
+	if err != nil || !ok {
+		return err
+	}
+	if err := json.Unmarshal([]byte(data), out); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *AnnouncementService) save(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	return s.store.Set(key, string(data), announcementTTL)
+}
+
+func statusBlock(state alertState, owner string, responders []model.AddedResponder) slack.Block {
+	lines := make([]string, 0, len(responders)+2)
+	if state.Action != "" {
+		lines = append(lines, stateLine(state))
+	}
+	if owner != "" {
+		lines = append(lines, fmt.Sprintf("🙋 Owned by <@%s>", owner))
+	}
+	for _, responder := range responders {
+		lines = append(lines, fmt.Sprintf("🔺 Escalated to *%s* by <@%s>", responder.Target.Name, responder.UserID))
+	}
+
+	return slack.NewContextBlock(alertStatusBlockID,
+		slack.NewTextBlockObject(slack.MarkdownType, truncateText(strings.Join(lines, "\n"), 3000), false, false))
+}
+
+func stateLine(state alertState) string {
+	if state.Action == model.ActionClose {
+		return fmt.Sprintf("🔒 Closed by <@%s>", state.UserID)
+	}
+	return fmt.Sprintf("✅ Acknowledged by <@%s>", state.UserID)
+}
+
+func withStatus(blocks []slack.Block, status slack.Block) []slack.Block {
+	updated := make([]slack.Block, 0, len(blocks)+1)
+	inserted := false
+	for _, block := range blocks {
+		if actions, ok := block.(*slack.ActionBlock); ok && actions.BlockID == alertActionsBlockID && !inserted {
+			updated = append(updated, status)
+			inserted = true
+		}
+		updated = append(updated, block)
+	}
+	if !inserted {
+		updated = append(updated, status)
+	}
+	return updated
+}
+
+func withoutStatus(blocks []slack.Block) []slack.Block {
+	kept := make([]slack.Block, 0, len(blocks))
+	for _, block := range blocks {
+		if contextBlock, ok := block.(*slack.ContextBlock); ok && contextBlock.BlockID == alertStatusBlockID {
+			continue
+		}
+		kept = append(kept, block)
+	}
+	return kept
+}
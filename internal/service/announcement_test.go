@@ -0,0 +1,287 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/slack-go/slack"
+)
+
+type slackCall struct {
+	Path    string
+	Channel string
+	TS      string
+	Body    string
+}
+
+type fakeSlack struct {
+	mu     sync.Mutex
+	calls  []slackCall
+	posted int
+	server *httptest.Server
+}
+
+func newFakeSlack(t *testing.T) (*fakeSlack, *SlackService) {
+	t.Helper()
+
+	fake := &fakeSlack{}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.server.Close)
+
+	return fake, &SlackService{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(fake.server.URL+"/")),
+		logger: quietLogger(),
+	}
+}
+
+func (f *fakeSlack) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/respond" {
+		body, _ := io.ReadAll(r.Body)
+		f.calls = append(f.calls, slackCall{Path: r.URL.Path, Body: string(body)})
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+		return
+	}
+
+	call := slackCall{Path: r.URL.Path, Channel: r.FormValue("channel"), TS: r.FormValue("ts"), Body: r.FormValue("blocks")}
+	if call.Path == "/chat.postMessage" {
+		f.posted++
+		call.TS = fmt.Sprintf("1.%04d", f.posted)
+		if strings.HasPrefix(call.Channel, "U") {
+			call.Channel = "D" + strings.TrimPrefix(call.Channel, "U")
+		}
+	}
+	f.calls = append(f.calls, call)
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channel": call.Channel, "ts": call.TS})
+}
+
+func (f *fakeSlack) find(path string) []slackCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var found []slackCall
+	for _, call := range f.calls {
+		if call.Path == path {
+			found = append(found, call)
+		}
+	}
+	return found
+}
+
+func testAlertRef() model.AlertRef {
+	return model.AlertRef{ID: "alert-1", TinyID: "7", Kind: model.ResultKindAlert}
+}
+
+func alertBlocks(ref model.AlertRef) []slack.Block {
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "Database is down", false, false), nil, nil),
+		AlertActionsBlock(ref),
+	}
+}
+
+func TestAddResponderIsSerialized(t *testing.T) {
+	fake, slackService := newFakeSlack(t)
+	st := store.NewMemoryStore()
+	announcements := NewAnnouncementService(slackService, st, quietLogger())
+	ref := testAlertRef()
+
+	if _, err := announcements.Post(ref, "C1", "", "New alert", alertBlocks(ref)[:1]); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+
+	const count = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, count)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- announcements.AddResponder(ref.ID, model.AddedResponder{
+				Target: model.EscalationTarget{Name: fmt.Sprintf("team-%d", i)},
+				UserID: "U1",
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("AddResponder() error = %v", err)
+		}
+	}
+
+	var responders []model.AddedResponder
+	if err := announcements.load(respondersKeyPrefix+ref.ID, &responders); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if len(responders) != count {
+		t.Fatalf("stored %d responders, want %d", len(responders), count)
+	}
+
+	updates := fake.find("/chat.update")
+	if len(updates) != count {
+		t.Fatalf("got %d message updates, want %d", len(updates), count)
+	}
+	last := updates[len(updates)-1]
+	for i := 0; i < count; i++ {
+		if !strings.Contains(last.Body, fmt.Sprintf("team-%d", i)) {
+			t.Fatalf("last update is missing team-%d: %s", i, last.Body)
+		}
+	}
+}
+
+func TestPostToUserRecordsDirectMessageChannel(t *testing.T) {
+	fake, slackService := newFakeSlack(t)
+	announcements := NewAnnouncementService(slackService, store.NewMemoryStore(), quietLogger())
+	ref := testAlertRef()
+
+	if _, err := announcements.Post(ref, "U1", "", "Incident created successfully!", alertBlocks(ref)[:1]); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if err := announcements.AddResponder(ref.ID, model.AddedResponder{Target: model.EscalationTarget{Name: "dba"}, UserID: "U1"}); err != nil {
+		t.Fatalf("AddResponder() error = %v", err)
+	}
+
+	updates := fake.find("/chat.update")
+	if len(updates) != 1 || updates[0].Channel != "D1" || updates[0].TS != "1.0001" {
+		t.Fatalf("updates = %+v, want one update of D1 1.0001", updates)
+	}
+}
+
+func TestTrackUpdatesClickedMessage(t *testing.T) {
+	fake, slackService := newFakeSlack(t)
+	announcements := NewAnnouncementService(slackService, store.NewMemoryStore(), quietLogger())
+	ref := testAlertRef()
+
+	blocks := alertBlocks(ref)
+	blocks = withStatus(blocks, statusBlock(alertState{}, "U_OLD", nil))
+	payload := slack.InteractionCallback{
+		User:      slack.User{ID: "U2"},
+		Container: slack.Container{ChannelID: "C9", MessageTs: "9.0001"},
+		Message: slack.Message{Msg: slack.Msg{
+			Text:   "New alert",
+			Blocks: slack.Blocks{BlockSet: blocks},
+		}},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := announcements.Track(ref.ID, payload); err != nil {
+			t.Fatalf("Track() error = %v", err)
+		}
+	}
+	if err := announcements.SetOwner(ref.ID, "U2"); err != nil {
+		t.Fatalf("SetOwner() error = %v", err)
+	}
+
+	updates := fake.find("/chat.update")
+	if len(updates) != 1 || updates[0].Channel != "C9" || updates[0].TS != "9.0001" {
+		t.Fatalf("updates = %+v, want one update of C9 9.0001", updates)
+	}
+	if strings.Count(updates[0].Body, alertStatusBlockID) != 1 || strings.Contains(updates[0].Body, "U_OLD") {
+		t.Fatalf("update does not replace the old status: %s", updates[0].Body)
+	}
+	if !strings.Contains(updates[0].Body, `Owned by \u003c@U2\u003e`) {
+		t.Fatalf("update does not show the new owner: %s", updates[0].Body)
+	}
+}
+
+func TestTrackEphemeralMessageUsesResponseURL(t *testing.T) {
+	fake, slackService := newFakeSlack(t)
+	announcements := NewAnnouncementService(slackService, store.NewMemoryStore(), quietLogger())
+	ref := testAlertRef()
+
+	if err := announcements.RememberEphemeral(ref.ID, "U1", "Incident created successfully!", alertBlocks(ref)); err != nil {
+		t.Fatalf("RememberEphemeral() error = %v", err)
+	}
+	payload := slack.InteractionCallback{
+		User:        slack.User{ID: "U1"},
+		ResponseURL: fake.server.URL + "/respond",
+		Container:   slack.Container{ChannelID: "C1", MessageTs: "5.0001", IsEphemeral: true},
+	}
+	if err := announcements.Track(ref.ID, payload); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	if err := announcements.SetOwner(ref.ID, "U1"); err != nil {
+		t.Fatalf("SetOwner() error = %v", err)
+	}
+
+	replaced := fake.find("/respond")
+	if len(replaced) != 1 {
+		t.Fatalf("got %d response_url calls, want 1", len(replaced))
+	}
+	if !strings.Contains(replaced[0].Body, `"replace_original":true`) || !strings.Contains(replaced[0].Body, "Database is down") {
+		t.Fatalf("response_url call does not replace the original message: %s", replaced[0].Body)
+	}
+	if posts := fake.find("/chat.postMessage"); len(posts) != 0 {
+		t.Fatalf("posted %d thread replies to an ephemeral message, want none", len(posts))
+	}
+	if updates := fake.find("/chat.update"); len(updates) != 0 {
+		t.Fatalf("called chat.update %d times for an ephemeral message, want none", len(updates))
+	}
+}
+
+func TestAlertActionsBlock(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		want []string
+	}{
+		{"alert", model.ResultKindAlert, []string{TakeOwnershipActionID, AcknowledgeActionID, EscalateActionID, CloseActionID}},
+		{"incident", model.ResultKindIncident, []string{EscalateActionID, CloseActionID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := AlertActionsBlock(model.AlertRef{ID: "id-1", Kind: tt.kind}).(*slack.ActionBlock)
+
+			var got []string
+			for _, element := range block.Elements.ElementSet {
+				got = append(got, element.(*slack.ButtonBlockElement).ActionID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("buttons = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetStateUpdatesAnnouncements(t *testing.T) {
+	fake, slackService := newFakeSlack(t)
+	announcements := NewAnnouncementService(slackService, store.NewMemoryStore(), quietLogger())
+	ref := testAlertRef()
+
+	if _, err := announcements.Post(ref, "C1", "", "New alert", alertBlocks(ref)[:1]); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if err := announcements.SetState(ref.ID, model.ActionAck, "U1"); err != nil {
+		t.Fatalf("SetState() error = %v", err)
+	}
+	if err := announcements.SetState(ref.ID, model.ActionClose, "U2"); err != nil {
+		t.Fatalf("SetState() error = %v", err)
+	}
+
+	updates := fake.find("/chat.update")
+	if len(updates) != 2 {
+		t.Fatalf("got %d message updates, want 2", len(updates))
+	}
+	if !strings.Contains(updates[0].Body, `Acknowledged by \u003c@U1\u003e`) {
+		t.Fatalf("first update does not show the acknowledgement: %s", updates[0].Body)
+	}
+	if !strings.Contains(updates[1].Body, `Closed by \u003c@U2\u003e`) || strings.Contains(updates[1].Body, "Acknowledged") {
+		t.Fatalf("second update does not replace the state with closed: %s", updates[1].Body)
+	}
+
+	replies := fake.find("/chat.postMessage")
+	if len(replies) != 3 || replies[1].Channel != "C1" {
+		t.Fatalf("posts = %+v, want the announcement and two thread replies in C1", replies)
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/audit"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrAuditNotQueryable = errors.New("the configured audit sink cannot be queried")
+
+type AuditService struct {
+	sink    audit.Sink
+	teamID  string
+	enqueue func(event model.AuditEvent) error
+	logger  *logrus.Logger
+}
+
+func NewAuditService(sink audit.Sink, teamID string, logger *logrus.Logger) *AuditService {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &AuditService{
+		sink:   sink,
+		teamID: teamID,
+		logger: logger,
+	}
+}
+
+func (s *AuditService) SetEnqueue(fn func(event model.AuditEvent) error) {
+	s.enqueue = fn
+}
+
+func (s *AuditService) Record(event model.AuditEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if event.TeamID == "" {
+		event.TeamID = s.teamID
+	}
+
+	if s.enqueue != nil {
+		err := s.enqueue(event)
+		if err == nil {
+			return
+		}
+		s.logger.WithError(err).WithField("action", event.Action).Warn("Failed to queue audit event, writing it directly")
+	}
+
+	if err := s.Write(event); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"team_id": event.TeamID,
+			"action":  event.Action,
+			"outcome": event.Outcome,
+			"user_id": event.UserID,
+		}).Error("Failed to write audit event")
+	}
+}
+
+func (s *AuditService) Write(event model.AuditEvent) error {
+	return s.sink.Write(event)
+}
+
+func (s *AuditService) Recent(limit int) ([]model.AuditEvent, error) {
+	reader, ok := s.sink.(audit.Reader)
+	if !ok {
+		return nil, ErrAuditNotQueryable
+	}
+	return reader.Recent(s.teamID, limit)
+}
+
+func PayloadHash(payload interface{}) string {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
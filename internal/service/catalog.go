@@ -0,0 +1,268 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	servicesCacheKey = "catalog:services"
+	teamsCacheKey    = "catalog:teams"
+	escalationsKey   = "catalog:escalations"
+	maintenanceKey   = "catalog:maintenance:"
+	servicesCacheTTL = 5 * time.Minute
+	servicesPageSize = 100
+	servicesMaxPages = 10
+)
+
+func (s *AlertService) ListServices() ([]model.OpsGenieService, error) {
+	var services []model.OpsGenieService
+	for page := 0; page < servicesMaxPages; page++ {
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(servicesPageSize))
+		params.Set("offset", strconv.Itoa(page*servicesPageSize))
+
+		var response struct {
+			Data []model.OpsGenieService `json:"data"`
+		}
+
+		if err := s.do(http.MethodGet, s.incidentBaseURL+"/services?"+params.Encode(), nil, &response); err != nil {
+			return nil, fmt.Errorf("error listing services: %w", err)
+		}
+
+		services = append(services, response.Data...)
+		if len(response.Data) < servicesPageSize {
+			break
+		}
+	}
+
+	return services, nil
+}
+
+type ServiceCatalog struct {
+	alertService *AlertService
+	store        store.Store
+	logger       *logrus.Logger
+}
+
+func NewServiceCatalog(alertService *AlertService, store store.Store, logger *logrus.Logger) *ServiceCatalog {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &ServiceCatalog{
+		alertService: alertService,
+		store:        store,
+		logger:       logger,
+	}
+}
+
+func (c *ServiceCatalog) Search(query string, limit int) ([]model.OpsGenieService, error) {
+	services, err := c.services()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []model.OpsGenieService
+	for _, service := range services {
+		if query == "" || strings.Contains(strings.ToLower(service.Name), query) {
+			matches = append(matches, service)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (c *ServiceCatalog) services() ([]model.OpsGenieService, error) {
+	if cached, ok, err := c.store.Get(servicesCacheKey); err != nil {
+		c.logger.WithError(err).Warn("Failed to read cached services")
+	} else if ok {
+		var services []model.OpsGenieService
+		if err := json.Unmarshal([]byte(cached), &services); err == nil {
+			return services, nil
+		}
+	}
+
+	services, err := c.alertService.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(services)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal services: %w", err)
+	}
+	if err := c.store.Set(servicesCacheKey, string(data), servicesCacheTTL); err != nil {
+		c.logger.WithError(err).Warn("Failed to cache services")
+	}
+
+	return services, nil
+}
+
+func (c *ServiceCatalog) SearchTeams(query string, limit int) ([]model.OpsGenieTeam, error) {
+	teams, err := c.teams()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []model.OpsGenieTeam
+	for _, team := range teams {
+		if query == "" || strings.Contains(strings.ToLower(team.Name), query) {
+			matches = append(matches, team)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (c *ServiceCatalog) teams() ([]model.OpsGenieTeam, error) {
+	if cached, ok, err := c.store.Get(teamsCacheKey); err != nil {
+		c.logger.WithError(err).Warn("Failed to read cached teams")
+	} else if ok {
+		var teams []model.OpsGenieTeam
+		if err := json.Unmarshal([]byte(cached), &teams); err == nil {
+			return teams, nil
+		}
+	}
+
+	teams, err := c.alertService.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(teams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal teams: %w", err)
+	}
+	if err := c.store.Set(teamsCacheKey, string(data), servicesCacheTTL); err != nil {
+		c.logger.WithError(err).Warn("Failed to cache teams")
+	}
+
+	return teams, nil
+}
+
+func (c *ServiceCatalog) SearchEscalations(query string, limit int) ([]model.EscalationTarget, error) {
+	escalations, err := c.escalations()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []model.EscalationTarget
+	for _, escalation := range escalations {
+		if query == "" || strings.Contains(strings.ToLower(escalation.Name), query) {
+			matches = append(matches, escalation)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (c *ServiceCatalog) escalations() ([]model.EscalationTarget, error) {
+	if cached, ok, err := c.store.Get(escalationsKey); err != nil {
+		c.logger.WithError(err).Warn("Failed to read cached escalations")
+	} else if ok {
+		var escalations []model.EscalationTarget
+		if err := json.Unmarshal([]byte(cached), &escalations); err == nil {
+			return escalations, nil
+		}
+	}
+
+	escalations, err := c.alertService.ListEscalations()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(escalations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal escalations: %w", err)
+	}
+	if err := c.store.Set(escalationsKey, string(data), servicesCacheTTL); err != nil {
+		c.logger.WithError(err).Warn("Failed to cache escalations")
+	}
+
+	return escalations, nil
+}
+
+func (c *ServiceCatalog) SearchMaintenanceEntities(teamID, query string, limit int) ([]model.MaintenanceEntity, error) {
+	entities, err := c.maintenanceEntities(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []model.MaintenanceEntity
+	for _, entity := range entities {
+		if query == "" || strings.Contains(strings.ToLower(entity.Name), query) {
+			matches = append(matches, entity)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (c *ServiceCatalog) maintenanceEntities(teamID string) ([]model.MaintenanceEntity, error) {
+	if cached, ok, err := c.store.Get(maintenanceKey + teamID); err != nil {
+		c.logger.WithError(err).Warn("Failed to read cached maintenance entities")
+	} else if ok {
+		var entities []model.MaintenanceEntity
+		if err := json.Unmarshal([]byte(cached), &entities); err == nil {
+			return entities, nil
+		}
+	}
+
+	entities, err := c.alertService.ListIntegrations(teamID)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := c.alertService.ListAlertPolicies(teamID)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to list alert policies")
+	}
+	entities = append(entities, policies...)
+
+	data, err := json.Marshal(entities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal maintenance entities: %w", err)
+	}
+	if err := c.store.Set(maintenanceKey+teamID, string(data), servicesCacheTTL); err != nil {
+		c.logger.WithError(err).Warn("Failed to cache maintenance entities")
+	}
+
+	return entities, nil
+}
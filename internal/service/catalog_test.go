@@ -0,0 +1,141 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+)
+
+func TestListServicesPages(t *testing.T) {
+	var offsets []string
+	alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/services" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		offset := r.URL.Query().Get("offset")
+		offsets = append(offsets, offset)
+
+		start, _ := strconv.Atoi(offset)
+		count := servicesPageSize
+		if start > 0 {
+			count = 3
+		}
+		data := make([]model.OpsGenieService, 0, count)
+		for i := 0; i < count; i++ {
+			data = append(data, model.OpsGenieService{ID: fmt.Sprintf("svc-%d", start+i)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	})
+
+	services, err := alertService.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices() error = %v", err)
+	}
+	if len(services) != servicesPageSize+3 {
+		t.Fatalf("got %d services, want %d", len(services), servicesPageSize+3)
+	}
+	if len(offsets) != 2 || offsets[0] != "0" || offsets[1] != "100" {
+		t.Fatalf("offsets = %v, want [0 100]", offsets)
+	}
+}
+
+func TestServiceCatalogSearch(t *testing.T) {
+	services := []model.OpsGenieService{
+		{ID: "svc-3", Name: "payments-worker"},
+		{ID: "svc-1", Name: "Checkout API"},
+		{ID: "svc-2", Name: "Payments API"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{"empty query lists all sorted by name", "", 10, []string{"svc-1", "svc-2", "svc-3"}},
+		{"query is case insensitive", "  PAYMENTS ", 10, []string{"svc-2", "svc-3"}},
+		{"limit", "", 2, []string{"svc-1", "svc-2"}},
+		{"no match", "billing", 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": services})
+			})
+			catalog := NewServiceCatalog(alertService, store.NewMemoryStore(), quietLogger())
+
+			for i := 0; i < 2; i++ {
+				got, err := catalog.Search(tt.query, tt.limit)
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+				var ids []string
+				for _, service := range got {
+					ids = append(ids, service.ID)
+				}
+				if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+					t.Fatalf("Search() = %v, want %v", ids, tt.want)
+				}
+			}
+			if requests != 1 {
+				t.Fatalf("listed services %d times, want 1 cached lookup", requests)
+			}
+		})
+	}
+}
+
+func TestServiceCatalogSearchError(t *testing.T) {
+	alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	st := store.NewMemoryStore()
+	catalog := NewServiceCatalog(alertService, st, quietLogger())
+
+	if _, err := catalog.Search("", 10); err == nil {
+		t.Fatal("Search() error = nil, want the OpsGenie error")
+	}
+	if _, ok, _ := st.Get(servicesCacheKey); ok {
+		t.Fatal("cached services after a failed lookup")
+	}
+}
+
+func TestServiceCatalogSearchTeams(t *testing.T) {
+	requests := 0
+	alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/teams" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		requests++
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []model.OpsGenieTeam{
+			{ID: "t-2", Name: "Web"},
+			{ID: "t-1", Name: "DBA"},
+		}})
+	})
+	catalog := NewServiceCatalog(alertService, store.NewMemoryStore(), quietLogger())
+
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{{"", "[{t-1 DBA} {t-2 Web}]"}, {"we", "[{t-2 Web}]"}} {
+		got, err := catalog.SearchTeams(tt.query, 10)
+		if err != nil {
+			t.Fatalf("SearchTeams() error = %v", err)
+		}
+		if fmt.Sprint(got) != tt.want {
+			t.Fatalf("SearchTeams(%q) = %v, want %s", tt.query, got, tt.want)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("listed teams %d times, want 1 cached lookup", requests)
+	}
+}
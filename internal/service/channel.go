@@ -0,0 +1,204 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	maxChannelNameLength = 80
+	maxChannelAttempts   = 5
+)
+
+var channelSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+type IncidentChannelService struct {
+	config        config.IncidentChannelConfig
+	slackService  *SlackService
+	alertService  *AlertService
+	announcements *AnnouncementService
+	logger        *logrus.Logger
+}
+
+func NewIncidentChannelService(
+	channelConfig config.IncidentChannelConfig,
+	slackService *SlackService,
+	alertService *AlertService,
+	announcements *AnnouncementService,
+	logger *logrus.Logger,
+) *IncidentChannelService {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &IncidentChannelService{
+		config:        channelConfig,
+		slackService:  slackService,
+		alertService:  alertService,
+		announcements: announcements,
+		logger:        logger,
+	}
+}
+
+func (s *IncidentChannelService) ShouldCreate(priority model.AlertPriority) bool {
+	return s.config.AppliesTo(string(priority))
+}
+
+func (s *IncidentChannelService) CreateForAlert(alert model.Alert, result *model.AlertCreationResult) (*model.IncidentChannel, error) {
+	channel, err := s.createChannel(result)
+	if err != nil {
+		return nil, err
+	}
+
+	incidentChannel := &model.IncidentChannel{
+		ID:   channel.ID,
+		Name: channel.Name,
+		URL:  fmt.Sprintf("https://app.slack.com/client/%s/%s", alert.Team.ID, channel.ID),
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"alert_id":     result.ID,
+		"channel_id":   channel.ID,
+		"channel_name": channel.Name,
+	})
+	logger.Info("Created incident channel")
+
+	if err := s.slackService.InviteUsers(channel.ID, s.members(alert)); err != nil {
+		logger.WithError(err).Warn("Failed to invite some incident responders")
+	}
+
+	summary := s.summaryBlocks(alert, result)
+	ts, err := s.announcements.Post(result.Ref(), channel.ID, "", "Incident summary", summary)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to post incident summary")
+	} else if err := s.slackService.PinMessage(channel.ID, ts); err != nil {
+		logger.WithError(err).Warn("Failed to pin incident summary")
+	}
+
+	if result.URL != "" {
+		link := fmt.Sprintf("🔗 <%s|View in OpsGenie>", result.URL)
+		if _, err := s.slackService.PostMessage(channel.ID, "", link, nil); err != nil {
+			logger.WithError(err).Warn("Failed to post OpsGenie link")
+		}
+	}
+
+	details := map[string]string{
+		"slackChannel":   incidentChannel.URL,
+		"slackChannelId": incidentChannel.ID,
+	}
+	addDetails := s.alertService.AddDetails
+	if result.Kind == model.ResultKindIncident {
+		addDetails = s.alertService.AddIncidentDetails
+	}
+	if err := addDetails(result.ID, details); err != nil {
+		logger.WithError(err).Warn("Failed to write incident channel to alert")
+	}
+
+	return incidentChannel, nil
+}
+
+func (s *IncidentChannelService) createChannel(result *model.AlertCreationResult) (*slack.Channel, error) {
+	id := result.TinyID
+	if id == "" {
+		id = shortID(result.ID)
+	}
+	base := channelName(s.config.Prefix, id, result.Title)
+
+	var lastErr error
+	for attempt := 1; attempt <= maxChannelAttempts; attempt++ {
+		name := base
+		if attempt > 1 {
+			suffix := fmt.Sprintf("-%d", attempt)
+			name = strings.TrimRight(truncateText(base, maxChannelNameLength-len(suffix)), "-") + suffix
+		}
+
+		channel, err := s.slackService.CreateChannel(name, s.config.Private)
+		if err == nil {
+			return channel, nil
+		}
+		lastErr = err
+		if !strings.Contains(err.Error(), "name_taken") {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (s *IncidentChannelService) members(alert model.Alert) []string {
+	seen := make(map[string]bool)
+	var members []string
+	add := func(userID string) {
+		if userID != "" && !seen[userID] {
+			seen[userID] = true
+			members = append(members, userID)
+		}
+	}
+
+	add(alert.Reporter.ID)
+	for _, stakeholder := range s.config.Stakeholders {
+		add(stakeholder)
+	}
+
+	recipients, err := s.alertService.GetOnCallRecipients(alert.ResponderTeamID)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to resolve on-call responders")
+		return members
+	}
+	for _, email := range recipients {
+		userID, err := s.slackService.LookupUserIDByEmail(email)
+		if err != nil {
+			s.logger.WithError(err).WithField("email", email).Debug("On-call responder has no Slack user")
+			continue
+		}
+		add(userID)
+	}
+
+	return members
+}
+
+func (s *IncidentChannelService) summaryBlocks(alert model.Alert, result *model.AlertCreationResult) []slack.Block {
+	title := fmt.Sprintf("🚨 %s", result.Title)
+	if result.TinyID != "" {
+		title = fmt.Sprintf("🚨 #%s %s", result.TinyID, result.Title)
+	}
+
+	text := fmt.Sprintf("*Priority:* %s\n*Reported by:* <@%s>", string(result.Priority), alert.Reporter.ID)
+	if alert.Description != "" {
+		text += "\n\n" + alert.Description
+	}
+
+	return []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, truncateText(title, 150), true, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, truncateText(text, 3000), false, false), nil, nil),
+	}
+}
+
+func channelName(prefix, id, title string) string {
+	slug := strings.Trim(channelSlugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	name := strings.ToLower(fmt.Sprintf("%s-%s", prefix, id))
+	if slug != "" {
+		name += "-" + slug
+	}
+	return strings.TrimRight(truncateText(name, maxChannelNameLength), "-")
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func truncateText(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChannelName(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		id     string
+		title  string
+		want   string
+	}{
+		{"slugged title", "inc", "42", "Database is DOWN!", "inc-42-database-is-down"},
+		{"punctuation collapses", "inc", "7", "  API -- 5xx / errors  ", "inc-7-api-5xx-errors"},
+		{"empty title", "inc", "7", "", "inc-7"},
+		{"title without slug characters", "inc", "7", "🔥🔥", "inc-7"},
+		{"uppercase prefix and id", "INC", "AB12", "Outage", "inc-ab12-outage"},
+		{"non-ascii letters are dropped", "inc", "3", "Café crashed", "inc-3-caf-crashed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := channelName(tt.prefix, tt.id, tt.title); got != tt.want {
+				t.Fatalf("channelName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelNameTruncates(t *testing.T) {
+	got := channelName("inc", "1234", strings.Repeat("very long title ", 10))
+	if len(got) > maxChannelNameLength {
+		t.Fatalf("channelName() has %d characters, want at most %d", len(got), maxChannelNameLength)
+	}
+	if strings.HasSuffix(got, "-") {
+		t.Fatalf("channelName() = %q ends with a dash", got)
+	}
+	if !strings.HasPrefix(got, "inc-1234-very-long-title") {
+		t.Fatalf("channelName() = %q lost its prefix", got)
+	}
+}
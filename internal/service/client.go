@@ -0,0 +1,11 @@
+package service
+
+import (
+	"net/http"
+	"time"
+)
+
+var (
+	opsgenieHTTPClient = &http.Client{Timeout: 10 * time.Second}
+	slackHTTPClient    = &http.Client{Timeout: 30 * time.Second}
+)
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/slack-go/slack"
+)
+
+const (
+	EscalateModalCallbackID = "escalate_modal"
+
+	EscalationTargetBlockID  = "escalation_target_block"
+	EscalationTargetActionID = "escalation_target"
+	EscalationNoteBlockID    = "escalation_note_block"
+	EscalationNoteActionID   = "escalation_note"
+)
+
+func (s *SlackService) EscalateModal(metadata model.EscalationMetadata) slack.ModalViewRequest {
+	privateMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to marshal escalation metadata")
+	}
+
+	minQueryLength := 0
+	target := slack.NewOptionsSelectBlockElement(slack.OptTypeExternal,
+		slack.NewTextBlockObject(slack.PlainTextType, "Search teams and escalations", true, false),
+		EscalationTargetActionID)
+	target.MinQueryLength = &minQueryLength
+
+	note := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "Why does this need another team?", true, false),
+		EscalationNoteActionID)
+	note.Multiline = true
+	noteInput := slack.NewInputBlock(EscalationNoteBlockID,
+		slack.NewTextBlockObject(slack.PlainTextType, "Note", true, false), nil, note)
+	noteInput.Optional = true
+
+	return slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  slack.NewTextBlockObject(slack.PlainTextType, "Escalate", true, false),
+		Submit: slack.NewTextBlockObject(slack.PlainTextType, "Escalate", true, false),
+		Close:  slack.NewTextBlockObject(slack.PlainTextType, "Cancel", true, false),
+		Blocks: slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+					fmt.Sprintf("Add responders to *%s*.", metadata.Alert.Label()), false, false), nil, nil),
+				slack.NewInputBlock(EscalationTargetBlockID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Team or escalation", true, false), nil, target),
+				noteInput,
+			},
+		},
+		CallbackID:      EscalateModalCallbackID,
+		PrivateMetadata: string(privateMetadata),
+	}
+}
+
+func EscalationTargetValue(target model.EscalationTarget) string {
+	return target.Type + ":" + target.ID
+}
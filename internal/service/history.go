@@ -0,0 +1,71 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+)
+
+const (
+	historyTTL   = 30 * 24 * time.Hour
+	historyLimit = 20
+)
+
+type HistoryService struct {
+	store store.Store
+}
+
+func NewHistoryService(store store.Store) *HistoryService {
+	return &HistoryService{store: store}
+}
+
+func (s *HistoryService) RecordReported(userID string, result *model.AlertCreationResult) error {
+	entries, err := s.RecentReported(userID, historyLimit)
+	if err != nil {
+		return err
+	}
+
+	entry := *result
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	entries = append([]model.AlertCreationResult{entry}, entries...)
+	if len(entries) > historyLimit {
+		entries = entries[:historyLimit]
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident history: %w", err)
+	}
+
+	return s.store.Set(reportedKey(userID), string(data), historyTTL)
+}
+
+func (s *HistoryService) RecentReported(userID string, limit int) ([]model.AlertCreationResult, error) {
+	data, ok, err := s.store.Get(reportedKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load incident history: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	var entries []model.AlertCreationResult
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse incident history: %w", err)
+	}
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func reportedKey(userID string) string {
+	return "history:reported:" + userID
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+var errIncidentRequestPending = errors.New("incident request was not processed in time")
+
+const (
+	incidentPollAttempts = 5
+	maxTagLength         = 50
+)
+
+var incidentPollInterval = time.Second
+
+func (s *AlertService) CreateIncident(incident model.Incident) (*model.AlertCreationResult, error) {
+	if len(incident.Responders) == 0 {
+		incident.Responders = []model.Responder{{Type: "team", ID: s.teamID}}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"message":  incident.Message,
+		"priority": incident.Priority,
+	}).Debug("Creating OpsGenie incident")
+
+	var response struct {
+		RequestID string `json:"requestId"`
+	}
+
+	if err := s.do(http.MethodPost, s.incidentBaseURL+"/incidents/create", incident, &response); err != nil {
+		return nil, fmt.Errorf("error creating incident: %w", err)
+	}
+
+	incidentID, err := s.pollIncidentRequest(response.RequestID)
+	if errors.Is(err, errIncidentRequestPending) {
+		s.logger.WithError(err).Warn("Incident request is still being processed")
+		return &model.AlertCreationResult{
+			Title:     incident.Message,
+			Priority:  incident.Priority,
+			RequestID: response.RequestID,
+			Kind:      model.ResultKindIncident,
+			Pending:   true,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.getIncidentDetails(incidentID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get incident details")
+		return &model.AlertCreationResult{
+			ID:        incidentID,
+			Title:     incident.Message,
+			Priority:  incident.Priority,
+			URL:       s.incidentURL(incidentID),
+			RequestID: response.RequestID,
+			Kind:      model.ResultKindIncident,
+		}, nil
+	}
+
+	result.RequestID = response.RequestID
+	return result, nil
+}
+
+func (s *AlertService) AddIncidentDetails(incidentID string, details map[string]string) error {
+	payload := map[string]interface{}{
+		"details": details,
+	}
+
+	requestURL := fmt.Sprintf("%s/incidents/%s/details?identifierType=id", s.incidentBaseURL, url.PathEscape(incidentID))
+	if err := s.do(http.MethodPost, requestURL, payload, nil); err != nil {
+		return fmt.Errorf("error adding incident details: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AlertService) pollIncidentRequest(requestID string) (string, error) {
+	requestURL := fmt.Sprintf("%s/incidents/requests/%s", s.incidentBaseURL, url.PathEscape(requestID))
+
+	var lastErr error
+	for attempt := 1; attempt <= incidentPollAttempts; attempt++ {
+		time.Sleep(incidentPollInterval)
+
+		var response struct {
+			Data struct {
+				Success    bool   `json:"success"`
+				IsSuccess  bool   `json:"isSuccess"`
+				Status     string `json:"status"`
+				IncidentID string `json:"incidentId"`
+			} `json:"data"`
+		}
+
+		if err := s.do(http.MethodGet, requestURL, nil, &response); err != nil {
+			lastErr = err
+			s.logger.WithError(err).WithField("attempt", attempt).Debug("Incident request not processed yet")
+			continue
+		}
+
+		if response.Data.IncidentID != "" && (response.Data.Success || response.Data.IsSuccess) {
+			return response.Data.IncidentID, nil
+		}
+		if response.Data.Status != "" && !response.Data.Success && !response.Data.IsSuccess {
+			return "", fmt.Errorf("incident creation was not successful: %s", response.Data.Status)
+		}
+	}
+
+	if lastErr != nil {
+		return "", fmt.Errorf("%w: %s: %v", errIncidentRequestPending, requestID, lastErr)
+	}
+	return "", fmt.Errorf("%w: %s", errIncidentRequestPending, requestID)
+}
+
+func (s *AlertService) getIncidentDetails(incidentID string) (*model.AlertCreationResult, error) {
+	var response struct {
+		Data struct {
+			ID        string    `json:"id"`
+			TinyID    string    `json:"tinyId"`
+			Message   string    `json:"message"`
+			Priority  string    `json:"priority"`
+			CreatedAt time.Time `json:"createdAt"`
+		} `json:"data"`
+	}
+
+	requestURL := fmt.Sprintf("%s/incidents/%s?identifierType=id", s.incidentBaseURL, url.PathEscape(incidentID))
+	if err := s.do(http.MethodGet, requestURL, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &model.AlertCreationResult{
+		ID:        response.Data.ID,
+		TinyID:    response.Data.TinyID,
+		Title:     response.Data.Message,
+		Priority:  model.AlertPriority(response.Data.Priority),
+		URL:       s.incidentURL(response.Data.ID),
+		CreatedAt: response.Data.CreatedAt,
+		Kind:      model.ResultKindIncident,
+	}, nil
+}
+
+func (s *AlertService) FindIncidentByTag(tag string) (*model.AlertCreationResult, bool, error) {
+	params := url.Values{}
+	params.Set("query", fmt.Sprintf("tag:%q", tag))
+	params.Set("limit", "1")
+
+	var response struct {
+		Data []struct {
+			ID        string    `json:"id"`
+			TinyID    string    `json:"tinyId"`
+			Message   string    `json:"message"`
+			Priority  string    `json:"priority"`
+			CreatedAt time.Time `json:"createdAt"`
+		} `json:"data"`
+	}
+
+	if err := s.do(http.MethodGet, s.incidentBaseURL+"/incidents?"+params.Encode(), nil, &response); err != nil {
+		return nil, false, fmt.Errorf("error searching incidents: %w", err)
+	}
+	if len(response.Data) == 0 {
+		return nil, false, nil
+	}
+
+	data := response.Data[0]
+	return &model.AlertCreationResult{
+		ID:        data.ID,
+		TinyID:    data.TinyID,
+		Title:     data.Message,
+		Priority:  model.AlertPriority(data.Priority),
+		URL:       s.incidentURL(data.ID),
+		CreatedAt: data.CreatedAt,
+		Kind:      model.ResultKindIncident,
+	}, true, nil
+}
+
+func (s *AlertService) incidentURL(incidentID string) string {
+	return fmt.Sprintf("https://%s.app.opsgenie.com/incident/detail/%s/details", s.domain, incidentID)
+}
+
+type IncidentService struct {
+	config       config.IncidentConfig
+	alertService *AlertService
+	users        *UserDirectory
+	audit        *AuditService
+	logger       *logrus.Logger
+}
+
+func NewIncidentService(
+	incidentConfig config.IncidentConfig,
+	alertService *AlertService,
+	users *UserDirectory,
+	audit *AuditService,
+	logger *logrus.Logger,
+) *IncidentService {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &IncidentService{
+		config:       incidentConfig,
+		alertService: alertService,
+		users:        users,
+		audit:        audit,
+		logger:       logger,
+	}
+}
+
+func (s *IncidentService) Create(alert model.Alert, source string) (*model.AlertCreationResult, error) {
+	alert = withImpactedServices(alert)
+	if user, ok := s.users.OpsGenieUser(alert.Reporter.ID); ok {
+		alert.Reporter.OpsGenieUser = user
+	}
+
+	result, err := s.create(alert, source)
+	s.recordCreate(alert, source, result, err)
+	return result, err
+}
+
+func (s *IncidentService) Find(alert model.Alert, source string) (*model.AlertCreationResult, bool, error) {
+	if alert.Alias == "" {
+		return nil, false, nil
+	}
+	if s.config.UsesIncidentAPI(string(alert.Priority), source) {
+		return s.alertService.FindIncidentByTag(alert.Alias)
+	}
+
+	return s.alertService.FindAlertByAlias(alert.Alias)
+}
+
+func (s *IncidentService) recordCreate(alert model.Alert, source string, result *model.AlertCreationResult, err error) {
+	event := model.AuditEvent{
+		Action:      model.ActionCreate,
+		Outcome:     model.AuditOutcomeSuccess,
+		UserID:      alert.Reporter.ID,
+		UserName:    alert.Reporter.Username,
+		ChannelID:   alert.ChannelID,
+		Source:      source,
+		Priority:    alert.Priority,
+		PayloadHash: PayloadHash(alert),
+	}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+	}
+	if result != nil {
+		event.OpsGenieID = result.ID
+		event.TinyID = result.TinyID
+		event.Kind = result.Kind
+	}
+	s.audit.Record(event)
+}
+
+func (s *IncidentService) create(alert model.Alert, source string) (*model.AlertCreationResult, error) {
+	if !s.config.UsesIncidentAPI(string(alert.Priority), source) {
+		return s.alertService.CreateAlert(alert)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"priority": alert.Priority,
+		"source":   source,
+	}).Info("Creating OpsGenie incident")
+
+	return s.alertService.CreateIncident(s.toIncident(alert))
+}
+
+func (s *IncidentService) toIncident(alert model.Alert) model.Incident {
+	details := map[string]string{
+		"reportedBy":    alert.Reporter.Username,
+		"slackUserId":   alert.Reporter.ID,
+		"slackUsername": alert.Reporter.Name,
+	}
+	for key, value := range alert.Details {
+		details[key] = value
+	}
+	if alert.Entity != "" {
+		details["entity"] = alert.Entity
+	}
+	if alert.Reporter.OpsGenieUser != "" {
+		details["opsgenieUser"] = alert.Reporter.OpsGenieUser
+	}
+
+	tags := alert.Tags
+	if alert.Alias != "" {
+		tags = append(append([]string{}, alert.Tags...), alert.Alias)
+	}
+
+	incident := model.Incident{
+		Message:            alert.Title,
+		Description:        alert.Description,
+		Priority:           alert.Priority,
+		Tags:               tags,
+		Details:            details,
+		ImpactedServices:   s.config.ImpactedServices,
+		NotifyStakeholders: s.config.NotifyStakeholders,
+	}
+
+	if len(alert.ImpactedServices) > 0 {
+		incident.ImpactedServices = nil
+		for _, service := range alert.ImpactedServices {
+			incident.ImpactedServices = append(incident.ImpactedServices, service.ID)
+		}
+	}
+
+	if alert.ResponderTeamID != "" {
+		incident.Responders = []model.Responder{{Type: "team", ID: alert.ResponderTeamID}}
+	}
+
+	if s.config.StatusPageEntry {
+		incident.StatusPageEntry = &model.StatusPageEntry{
+			Title:  alert.Title,
+			Detail: alert.Description,
+		}
+	}
+
+	return incident
+}
+
+func withImpactedServices(alert model.Alert) model.Alert {
+	if len(alert.ImpactedServices) == 0 {
+		return alert
+	}
+
+	details := make(map[string]string, len(alert.Details)+1)
+	for key, value := range alert.Details {
+		details[key] = value
+	}
+
+	tags := append([]string{}, alert.Tags...)
+	names := make([]string, 0, len(alert.ImpactedServices))
+	for _, service := range alert.ImpactedServices {
+		names = append(names, service.Name)
+		tags = append(tags, serviceTag(service.Name))
+	}
+	details["impactedServices"] = strings.Join(names, ", ")
+
+	alert.Details = details
+	alert.Tags = tags
+	return alert
+}
+
+func serviceTag(name string) string {
+	slug := strings.Trim(channelSlugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	return truncateText("service:"+slug, maxTagLength)
+}
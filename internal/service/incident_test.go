@@ -0,0 +1,82 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestAlertService(t *testing.T, handler http.HandlerFunc) *AlertService {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	s := NewAlertServiceWithLogger("key", "team", "", logger)
+	s.baseURL = server.URL + "/v2"
+	s.incidentBaseURL = server.URL + "/v1"
+	s.client = server.Client()
+	return s
+}
+
+func TestCreateIncidentPendingWhenRequestIsNotProcessed(t *testing.T) {
+	interval := incidentPollInterval
+	incidentPollInterval = time.Millisecond
+	t.Cleanup(func() { incidentPollInterval = interval })
+
+	creates := 0
+	s := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/incidents/create":
+			creates++
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]string{"requestId": "req-1"})
+		case strings.HasPrefix(r.URL.Path, "/v1/incidents/requests/"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	result, err := s.CreateIncident(model.Incident{Message: "Database down", Priority: model.PriorityP1})
+	if err != nil {
+		t.Fatalf("CreateIncident() error = %v", err)
+	}
+	if !result.Pending || result.RequestID != "req-1" || result.ID != "" {
+		t.Fatalf("CreateIncident() = %+v, want a pending result for req-1", result)
+	}
+	if creates != 1 {
+		t.Fatalf("incident was created %d times, want 1", creates)
+	}
+}
+
+func TestCreateIncidentFailsWhenRequestIsRejected(t *testing.T) {
+	interval := incidentPollInterval
+	incidentPollInterval = time.Millisecond
+	t.Cleanup(func() { incidentPollInterval = interval })
+
+	s := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]string{"requestId": "req-1"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+			"success": false,
+			"status":  "Invalid responders",
+		}})
+	})
+
+	if _, err := s.CreateIncident(model.Incident{Message: "Database down", Priority: model.PriorityP1}); err == nil {
+		t.Fatal("CreateIncident() error = nil, want the rejection")
+	}
+}
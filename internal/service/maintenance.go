@@ -0,0 +1,365 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	StopMaintenanceActionID = "stop_maintenance"
+
+	maintenanceActionsBlockID = "maintenance_actions"
+	maintenanceKeyPrefix      = "maintenance:"
+	maintenanceRecordGrace    = 24 * time.Hour
+)
+
+type maintenanceTime struct {
+	Type      string    `json:"type"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+type maintenanceEntity struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type maintenanceRule struct {
+	State  string            `json:"state"`
+	Entity maintenanceEntity `json:"entity"`
+}
+
+func (s *AlertService) ListAlertPolicies(teamID string) ([]model.MaintenanceEntity, error) {
+	path := "/policies/alert"
+	if teamID != "" {
+		path += "?teamId=" + url.QueryEscape(teamID)
+	}
+
+	var response struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := s.doRequest(http.MethodGet, path, nil, &response); err != nil {
+		return nil, fmt.Errorf("error listing alert policies: %w", err)
+	}
+
+	policies := make([]model.MaintenanceEntity, 0, len(response.Data))
+	for _, data := range response.Data {
+		policies = append(policies, model.MaintenanceEntity{Type: model.MaintenancePolicy, ID: data.ID, Name: data.Name})
+	}
+	return policies, nil
+}
+
+func (s *AlertService) ListIntegrations(teamID string) ([]model.MaintenanceEntity, error) {
+	path := "/integrations"
+	if teamID != "" {
+		path += "?teamId=" + url.QueryEscape(teamID)
+	}
+
+	var response struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := s.doRequest(http.MethodGet, path, nil, &response); err != nil {
+		return nil, fmt.Errorf("error listing integrations: %w", err)
+	}
+
+	integrations := make([]model.MaintenanceEntity, 0, len(response.Data))
+	for _, data := range response.Data {
+		integrations = append(integrations, model.MaintenanceEntity{Type: model.MaintenanceIntegration, ID: data.ID, Name: data.Name})
+	}
+	return integrations, nil
+}
+
+func (s *AlertService) CreateMaintenance(maintenance model.Maintenance) (*model.Maintenance, error) {
+	rules := make([]maintenanceRule, 0, len(maintenance.Rules))
+	for _, rule := range maintenance.Rules {
+		rules = append(rules, maintenanceRule{
+			State:  rule.State,
+			Entity: maintenanceEntity{ID: rule.Entity.ID, Type: rule.Entity.Type},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"description": maintenance.Description,
+		"time": maintenanceTime{
+			Type:      "schedule",
+			StartDate: maintenance.StartDate.UTC().Truncate(time.Second),
+			EndDate:   maintenance.EndDate.UTC().Truncate(time.Second),
+		},
+		"rules": rules,
+	}
+
+	var response struct {
+		Data struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	if err := s.do(http.MethodPost, s.incidentBaseURL+"/maintenance", payload, &response); err != nil {
+		return nil, fmt.Errorf("error creating maintenance: %w", err)
+	}
+
+	created := maintenance
+	created.ID = response.Data.ID
+	created.Status = response.Data.Status
+	return &created, nil
+}
+
+func (s *AlertService) ListMaintenance() ([]model.Maintenance, error) {
+	var response struct {
+		Data []struct {
+			ID          string          `json:"id"`
+			Status      string          `json:"status"`
+			Description string          `json:"description"`
+			Time        maintenanceTime `json:"time"`
+		} `json:"data"`
+	}
+	if err := s.do(http.MethodGet, s.incidentBaseURL+"/maintenance?type=non-expired", nil, &response); err != nil {
+		return nil, fmt.Errorf("error listing maintenance: %w", err)
+	}
+
+	windows := make([]model.Maintenance, 0, len(response.Data))
+	for _, data := range response.Data {
+		windows = append(windows, model.Maintenance{
+			ID:          data.ID,
+			Description: data.Description,
+			Status:      data.Status,
+			StartDate:   data.Time.StartDate,
+			EndDate:     data.Time.EndDate,
+		})
+	}
+	return windows, nil
+}
+
+func (s *AlertService) CancelMaintenance(id string) error {
+	requestURL := fmt.Sprintf("%s/maintenance/%s/cancel", s.incidentBaseURL, url.PathEscape(id))
+	if err := s.do(http.MethodPost, requestURL, nil, nil); err != nil {
+		return fmt.Errorf("error cancelling maintenance %s: %w", id, err)
+	}
+	return nil
+}
+
+type MaintenanceService struct {
+	alertService *AlertService
+	slackService *SlackService
+	store        store.Store
+	logger       *logrus.Logger
+}
+
+type maintenanceAnnouncement struct {
+	ChannelID   string            `json:"channelId"`
+	MessageTS   string            `json:"messageTs"`
+	UserID      string            `json:"userId"`
+	Maintenance model.Maintenance `json:"maintenance"`
+}
+
+func NewMaintenanceService(alertService *AlertService, slackService *SlackService, store store.Store, logger *logrus.Logger) *MaintenanceService {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &MaintenanceService{
+		alertService: alertService,
+		slackService: slackService,
+		store:        store,
+		logger:       logger,
+	}
+}
+
+func (s *MaintenanceService) Start(maintenance model.Maintenance) (*model.Maintenance, error) {
+	return s.alertService.CreateMaintenance(maintenance)
+}
+
+func (s *MaintenanceService) Announce(maintenance *model.Maintenance, channelID, userID string) error {
+	text := fmt.Sprintf("🛠️ Maintenance window started by <@%s>", userID)
+	blocks := maintenanceBlocks(text, maintenance)
+	blocks = append(blocks, slack.NewActionBlock(maintenanceActionsBlockID,
+		slack.NewButtonBlockElement(StopMaintenanceActionID, maintenance.ID,
+			slack.NewTextBlockObject(slack.PlainTextType, "End maintenance", true, false)).
+			WithStyle(slack.StyleDanger).
+			WithConfirm(slack.NewConfirmationBlockObject(
+				slack.NewTextBlockObject(slack.PlainTextType, "End maintenance?", true, false),
+				slack.NewTextBlockObject(slack.PlainTextType, "Alerts will be processed normally again.", true, false),
+				slack.NewTextBlockObject(slack.PlainTextType, "End now", true, false),
+				slack.NewTextBlockObject(slack.PlainTextType, "Cancel", true, false),
+			))))
+
+	ts, err := s.slackService.PostMessage(channelID, "", text, blocks)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(maintenanceAnnouncement{
+		ChannelID:   channelID,
+		MessageTS:   ts,
+		UserID:      userID,
+		Maintenance: *maintenance,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal maintenance announcement: %w", err)
+	}
+	ttl := time.Until(maintenance.EndDate) + maintenanceRecordGrace
+	if err := s.store.Set(maintenanceKeyPrefix+maintenance.ID, string(data), ttl); err != nil {
+		s.logger.WithError(err).WithField("maintenance_id", maintenance.ID).Warn("Failed to record maintenance announcement")
+	}
+	return nil
+}
+
+func (s *MaintenanceService) List() ([]model.Maintenance, error) {
+	return s.alertService.ListMaintenance()
+}
+
+func (s *MaintenanceService) Stop(id, userID string) error {
+	if err := s.alertService.CancelMaintenance(id); err != nil {
+		return err
+	}
+
+	logger := s.logger.WithField("maintenance_id", id)
+	data, ok, err := s.store.Get(maintenanceKeyPrefix + id)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to read maintenance announcement")
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	var posted maintenanceAnnouncement
+	if err := json.Unmarshal([]byte(data), &posted); err != nil {
+		logger.WithError(err).Warn("Failed to parse maintenance announcement")
+		return nil
+	}
+
+	text := fmt.Sprintf("✅ Maintenance window started by <@%s> was ended early by <@%s>", posted.UserID, userID)
+	if err := s.slackService.UpdateMessage(posted.ChannelID, posted.MessageTS, text, maintenanceBlocks(text, &posted.Maintenance)); err != nil {
+		logger.WithError(err).Warn("Failed to update maintenance announcement")
+	}
+	if err := s.store.Delete(maintenanceKeyPrefix + id); err != nil {
+		logger.WithError(err).Warn("Failed to delete maintenance announcement")
+	}
+	return nil
+}
+
+func maintenanceBlocks(headline string, maintenance *model.Maintenance) []slack.Block {
+	lines := []string{"*" + headline + "*"}
+	if maintenance.Description != "" {
+		lines = append(lines, maintenance.Description)
+	}
+	lines = append(lines, fmt.Sprintf("Until <!date^%d^{date_short_pretty} at {time}|%s>",
+		maintenance.EndDate.Unix(), maintenance.EndDate.UTC().Format(time.RFC1123)))
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, truncateText(strings.Join(lines, "\n"), 3000), false, false), nil, nil),
+	}
+	if len(maintenance.Rules) > 0 {
+		rules := make([]string, 0, len(maintenance.Rules))
+		for _, rule := range maintenance.Rules {
+			rules = append(rules, fmt.Sprintf("%s %s _%s_", MaintenanceEntityIcon(rule.Entity.Type), rule.Entity.Name, rule.State))
+		}
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, truncateText(strings.Join(rules, " · "), 3000), false, false)))
+	}
+	return blocks
+}
+
+func MaintenanceEntityIcon(entityType string) string {
+	if entityType == model.MaintenancePolicy {
+		return "📜"
+	}
+	return "🔌"
+}
+
+const (
+	MaintenanceModalCallbackID = "maintenance_modal"
+
+	MaintenanceDurationBlockID     = "maintenance_duration_block"
+	MaintenanceDurationActionID    = "maintenance_duration"
+	MaintenanceDescriptionBlockID  = "maintenance_description_block"
+	MaintenanceDescriptionActionID = "maintenance_description"
+	MaintenanceRulesBlockID        = "maintenance_rules_block"
+	MaintenanceRulesActionID       = "maintenance_rules"
+	MaintenancePolicyStateBlockID  = "maintenance_policy_state_block"
+	MaintenancePolicyStateActionID = "maintenance_policy_state"
+	MaintenanceChannelBlockID      = "maintenance_channel_block"
+	MaintenanceChannelActionID     = "maintenance_channel"
+)
+
+func (s *SlackService) MaintenanceModal(metadata model.MaintenanceMetadata, duration string) slack.ModalViewRequest {
+	privateMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to marshal maintenance metadata")
+	}
+
+	durationInput := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "e.g. 30m or 2h", true, false),
+		MaintenanceDurationActionID)
+	durationInput.InitialValue = duration
+
+	description := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "What is being deployed or changed?", true, false),
+		MaintenanceDescriptionActionID)
+	descriptionInput := slack.NewInputBlock(MaintenanceDescriptionBlockID,
+		slack.NewTextBlockObject(slack.PlainTextType, "Description", true, false), nil, description)
+	descriptionInput.Optional = true
+
+	minQueryLength := 0
+	rules := slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeExternal,
+		slack.NewTextBlockObject(slack.PlainTextType, "Search policies and integrations", true, false),
+		MaintenanceRulesActionID)
+	rules.MinQueryLength = &minQueryLength
+
+	enabled := slack.NewOptionBlockObject(model.MaintenanceStateEnabled,
+		slack.NewTextBlockObject(slack.PlainTextType, "Enable them", true, false), nil)
+	disabled := slack.NewOptionBlockObject(model.MaintenanceStateDisabled,
+		slack.NewTextBlockObject(slack.PlainTextType, "Disable them", true, false), nil)
+	policyState := slack.NewRadioButtonsBlockElement(MaintenancePolicyStateActionID, enabled, disabled)
+	policyState.InitialOption = enabled
+
+	channel := slack.NewOptionsSelectBlockElement(slack.OptTypeConversations,
+		slack.NewTextBlockObject(slack.PlainTextType, "Pick a channel", true, false),
+		MaintenanceChannelActionID)
+	channel.InitialConversation = metadata.ChannelID
+	channelInput := slack.NewInputBlock(MaintenanceChannelBlockID,
+		slack.NewTextBlockObject(slack.PlainTextType, "Announce in", true, false), nil, channel)
+	channelInput.Optional = true
+
+	return slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  slack.NewTextBlockObject(slack.PlainTextType, "Maintenance", true, false),
+		Submit: slack.NewTextBlockObject(slack.PlainTextType, "Start", true, false),
+		Close:  slack.NewTextBlockObject(slack.PlainTextType, "Cancel", true, false),
+		Blocks: slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewInputBlock(MaintenanceDurationBlockID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Duration", true, false), nil, durationInput),
+				descriptionInput,
+				slack.NewInputBlock(MaintenanceRulesBlockID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Policies and integrations", true, false),
+					slack.NewTextBlockObject(slack.PlainTextType, "Integrations are disabled during the window.", true, false),
+					rules),
+				slack.NewInputBlock(MaintenancePolicyStateBlockID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Selected policies during the window", true, false), nil, policyState),
+				channelInput,
+			},
+		},
+		CallbackID:      MaintenanceModalCallbackID,
+		PrivateMetadata: string(privateMetadata),
+	}
+}
+
+func MaintenanceEntityValue(entity model.MaintenanceEntity) string {
+	return entity.Type + ":" + entity.ID
+}
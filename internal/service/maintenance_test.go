@@ -0,0 +1,202 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+)
+
+func TestCreateMaintenance(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 500, time.FixedZone("CEST", 2*60*60))
+	var body string
+	alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/maintenance" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "GenieKey key" {
+			t.Errorf("Authorization = %q, want GenieKey key", r.Header.Get("Authorization"))
+		}
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"id": "m-1", "status": "active"}})
+	})
+
+	maintenance := model.Maintenance{
+		Description: "Deploying payments",
+		StartDate:   start,
+		EndDate:     start.Add(time.Hour),
+		Rules: []model.MaintenanceRule{
+			{State: model.MaintenanceStateDisabled, Entity: model.MaintenanceEntity{Type: model.MaintenanceIntegration, ID: "int-1", Name: "Datadog"}},
+			{State: model.MaintenanceStateEnabled, Entity: model.MaintenanceEntity{Type: model.MaintenancePolicy, ID: "pol-1", Name: "Nightly"}},
+		},
+	}
+	created, err := alertService.CreateMaintenance(maintenance)
+	if err != nil {
+		t.Fatalf("CreateMaintenance() error = %v", err)
+	}
+	if created.ID != "m-1" || created.Status != model.MaintenanceStatusActive || created.Description != "Deploying payments" {
+		t.Fatalf("CreateMaintenance() = %+v, want m-1 active with the description", created)
+	}
+
+	want := `{"description":"Deploying payments",` +
+		`"rules":[{"state":"disabled","entity":{"id":"int-1","type":"integration"}},{"state":"enabled","entity":{"id":"pol-1","type":"policy"}}],` +
+		`"time":{"type":"schedule","startDate":"2026-10-19T10:00:00Z","endDate":"2026-10-19T11:00:00Z"}}`
+	if strings.TrimSpace(body) != want {
+		t.Fatalf("request body = %s\nwant %s", body, want)
+	}
+}
+
+func TestListMaintenance(t *testing.T) {
+	alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/maintenance" || r.URL.Query().Get("type") != "non-expired" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]interface{}{{
+			"id":          "m-1",
+			"status":      "active",
+			"description": "Deploying payments",
+			"time":        map[string]string{"type": "schedule", "startDate": "2026-10-19T10:00:00Z", "endDate": "2026-10-19T11:00:00Z"},
+		}}})
+	})
+
+	windows, err := alertService.ListMaintenance()
+	if err != nil {
+		t.Fatalf("ListMaintenance() error = %v", err)
+	}
+	want := []model.Maintenance{{
+		ID:          "m-1",
+		Status:      "active",
+		Description: "Deploying payments",
+		StartDate:   time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2026, 10, 19, 11, 0, 0, 0, time.UTC),
+	}}
+	if !reflect.DeepEqual(windows, want) {
+		t.Fatalf("ListMaintenance() = %+v, want %+v", windows, want)
+	}
+}
+
+func TestCancelMaintenance(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"cancelled", http.StatusAccepted, false},
+		{"not found", http.StatusNotFound, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("method = %s, want POST", r.Method)
+				}
+				path = r.URL.EscapedPath()
+				w.WriteHeader(tt.status)
+			})
+
+			err := alertService.CancelMaintenance("m 1")
+			if path != "/v1/maintenance/m%201/cancel" {
+				t.Fatalf("path = %s, want /v1/maintenance/m%%201/cancel", path)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CancelMaintenance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var apiErr *APIError
+			if tt.wantErr && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.status) {
+				t.Fatalf("CancelMaintenance() error = %v, want an API error with status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestListMaintenanceEntities(t *testing.T) {
+	tests := []struct {
+		name      string
+		list      func(*AlertService, string) ([]model.MaintenanceEntity, error)
+		teamID    string
+		wantQuery string
+		wantPath  string
+		wantType  string
+	}{
+		{"policies", (*AlertService).ListAlertPolicies, "", "", "/v2/policies/alert", model.MaintenancePolicy},
+		{"team policies", (*AlertService).ListAlertPolicies, "team 1", "teamId=team+1", "/v2/policies/alert", model.MaintenancePolicy},
+		{"integrations", (*AlertService).ListIntegrations, "", "", "/v2/integrations", model.MaintenanceIntegration},
+		{"team integrations", (*AlertService).ListIntegrations, "team-1", "teamId=team-1", "/v2/integrations", model.MaintenanceIntegration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath || r.URL.RawQuery != tt.wantQuery {
+					t.Errorf("request = %s, want %s?%s", r.URL, tt.wantPath, tt.wantQuery)
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]string{{"id": "e-1", "name": "Datadog"}}})
+			})
+
+			entities, err := tt.list(alertService, tt.teamID)
+			if err != nil {
+				t.Fatalf("list error = %v", err)
+			}
+			want := []model.MaintenanceEntity{{Type: tt.wantType, ID: "e-1", Name: "Datadog"}}
+			if !reflect.DeepEqual(entities, want) {
+				t.Fatalf("entities = %+v, want %+v", entities, want)
+			}
+		})
+	}
+}
+
+func TestMaintenanceStopUpdatesAnnouncement(t *testing.T) {
+	cancelled := 0
+	alertService := newTestAlertService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/maintenance/m-1/cancel" {
+			cancelled++
+		}
+	})
+	fake, slackService := newFakeSlack(t)
+	st := store.NewMemoryStore()
+	maintenances := NewMaintenanceService(alertService, slackService, st, quietLogger())
+
+	maintenance := &model.Maintenance{ID: "m-1", Description: "Deploying payments", EndDate: time.Now().Add(time.Hour)}
+	if err := maintenances.Announce(maintenance, "C1", "U1"); err != nil {
+		t.Fatalf("Announce() error = %v", err)
+	}
+	posts := fake.find("/chat.postMessage")
+	if len(posts) != 1 || !strings.Contains(posts[0].Body, StopMaintenanceActionID) {
+		t.Fatalf("posts = %+v, want one announcement with a stop button", posts)
+	}
+
+	if err := maintenances.Stop("m-1", "U2"); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if cancelled != 1 {
+		t.Fatalf("cancelled %d times, want 1", cancelled)
+	}
+	updates := fake.find("/chat.update")
+	if len(updates) != 1 || updates[0].Channel != "C1" || updates[0].TS != posts[0].TS {
+		t.Fatalf("updates = %+v, want the announcement updated", updates)
+	}
+	if !strings.Contains(updates[0].Body, `ended early by \u003c@U2\u003e`) || strings.Contains(updates[0].Body, StopMaintenanceActionID) {
+		t.Fatalf("update does not end the maintenance: %s", updates[0].Body)
+	}
+	if _, ok, _ := st.Get(maintenanceKeyPrefix + "m-1"); ok {
+		t.Fatal("announcement record was not deleted")
+	}
+
+	if err := maintenances.Stop("m-1", "U2"); err != nil {
+		t.Fatalf("second Stop() error = %v", err)
+	}
+	if updates := fake.find("/chat.update"); len(updates) != 1 {
+		t.Fatalf("got %d updates after a second stop, want 1", len(updates))
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/slack-go/slack"
+)
+
+const (
+	IncidentModalCallbackID  = "incident_modal"
+	TemplatePickerCallbackID = "incident_template_picker"
+
+	TemplatePickerBlockID  = "template_block"
+	TemplatePickerActionID = "template"
+	BlankTemplateValue     = "blank"
+
+	UrgencyBlockID        = "urgency_block"
+	UrgencyActionID       = "urgency"
+	ImpactBlockID         = "impact_block"
+	ImpactActionID        = "impact"
+	ImpactConfirmBlockID  = "impact_confirm_block"
+	ImpactConfirmActionID = "impact_confirm"
+	TeamBlockID           = "team_block"
+	TeamActionID          = "team"
+	onCallBlockID         = "oncall_block"
+)
+
+type IncidentForm struct {
+	Metadata    model.ModalMetadata
+	Description string
+	Template    *config.IncidentTemplate
+	Templates   []config.IncidentTemplate
+	Urgency     string
+	Urgencies   []config.UrgencyLevel
+	Team        *model.OpsGenieTeam
+	OnCall      []string
+}
+
+func (s *SlackService) TemplatePickerModal(metadata model.ModalMetadata, templates []config.IncidentTemplate) slack.ModalViewRequest {
+	picker := templateSelectElement(templates, nil)
+
+	return slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  slack.NewTextBlockObject(slack.PlainTextType, "Create Incident", true, false),
+		Submit: slack.NewTextBlockObject(slack.PlainTextType, "Next", true, false),
+		Close:  slack.NewTextBlockObject(slack.PlainTextType, "Cancel", true, false),
+		Blocks: slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewInputBlock(TemplatePickerBlockID,
+					slack.NewTextBlockObject(slack.PlainTextType, "Incident template", true, false),
+					nil,
+					picker),
+			},
+		},
+		CallbackID:      TemplatePickerCallbackID,
+		ClearOnClose:    true,
+		PrivateMetadata: s.createPrivateMetadata(metadata),
+	}
+}
+
+func templateSelectElement(templates []config.IncidentTemplate, selected *config.IncidentTemplate) *slack.SelectBlockElement {
+	options := []*slack.OptionBlockObject{
+		slack.NewOptionBlockObject(BlankTemplateValue,
+			slack.NewTextBlockObject(slack.PlainTextType, "Blank incident", true, false), nil),
+	}
+	for _, template := range templates {
+		options = append(options, slack.NewOptionBlockObject(template.ID,
+			slack.NewTextBlockObject(slack.PlainTextType, truncateText(template.Name, 75), true, false), nil))
+	}
+
+	picker := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic,
+		slack.NewTextBlockObject(slack.PlainTextType, "Choose a template", true, false),
+		TemplatePickerActionID,
+		options...)
+	picker.InitialOption = options[0]
+	if selected != nil {
+		if option := initialOption(options, selected.ID); option != nil {
+			picker.InitialOption = option
+		}
+	}
+	return picker
+}
+
+func templateSelectBlock(templates []config.IncidentTemplate, selected *config.IncidentTemplate) slack.Block {
+	return slack.NewInputBlock(TemplatePickerBlockID,
+		slack.NewTextBlockObject(slack.PlainTextType, "Template", true, false),
+		nil,
+		templateSelectElement(templates, selected)).WithDispatchAction(true)
+}
+
+func urgencyOptionBlocks(levels []config.UrgencyLevel, selected string) ([]*slack.OptionBlockObject, bool) {
+	options := make([]*slack.OptionBlockObject, 0, len(levels))
+	critical := false
+	for _, level := range levels {
+		label := level.Label
+		if level.Emoji != "" {
+			label = level.Emoji + " " + label
+		}
+
+		var description *slack.TextBlockObject
+		if level.Description != "" {
+			description = slack.NewTextBlockObject(slack.PlainTextType, truncateText(level.Description, 75), true, false)
+		}
+
+		options = append(options, slack.NewOptionBlockObject(level.Value,
+			slack.NewTextBlockObject(slack.PlainTextType, truncateText(label, 75), true, false), description))
+		if level.Value == selected && level.Priority == string(model.PriorityP1) {
+			critical = true
+		}
+	}
+	return options, critical
+}
+
+func criticalImpactBlocks() []slack.Block {
+	impact := slack.NewPlainTextInputBlockElement(
+		slack.NewTextBlockObject(slack.PlainTextType, "Which customers are affected and how?", true, false),
+		ImpactActionID)
+	impact.Multiline = true
+
+	confirm := slack.NewCheckboxGroupsBlockElement(ImpactConfirmActionID,
+		slack.NewOptionBlockObject("confirmed",
+			slack.NewTextBlockObject(slack.PlainTextType, "This is a critical incident and responders should be paged now", true, false), nil))
+
+	return []slack.Block{
+		slack.NewInputBlock(ImpactBlockID,
+			slack.NewTextBlockObject(slack.PlainTextType, "Customer impact", true, false), nil, impact),
+		slack.NewInputBlock(ImpactConfirmBlockID,
+			slack.NewTextBlockObject(slack.PlainTextType, "Confirmation", true, false), nil, confirm),
+	}
+}
+
+func teamBlocks(team *model.OpsGenieTeam, onCall []string) []slack.Block {
+	minQueryLength := 0
+	element := slack.NewOptionsSelectBlockElement(slack.OptTypeExternal,
+		slack.NewTextBlockObject(slack.PlainTextType, "Search OpsGenie teams", true, false),
+		TeamActionID)
+	element.MinQueryLength = &minQueryLength
+	if team != nil {
+		element.InitialOption = slack.NewOptionBlockObject(team.ID,
+			slack.NewTextBlockObject(slack.PlainTextType, truncateText(team.Name, 75), true, false), nil)
+	}
+
+	input := slack.NewInputBlock(TeamBlockID,
+		slack.NewTextBlockObject(slack.PlainTextType, "Responder team", true, false), nil, element).WithDispatchAction(true)
+	input.Optional = true
+
+	blocks := []slack.Block{input}
+	if team == nil {
+		return blocks
+	}
+
+	text := fmt.Sprintf(":pager: Nobody is on call for *%s* right now", team.Name)
+	if len(onCall) > 0 {
+		text = fmt.Sprintf(":pager: On call for *%s*: %s", team.Name, strings.Join(onCall, ", "))
+	}
+	return append(blocks, slack.NewContextBlock(onCallBlockID,
+		slack.NewTextBlockObject(slack.MarkdownType, text, false, false)))
+}
+
+func TemplateFieldBlockID(fieldID string) string {
+	return "field_" + fieldID + "_block"
+}
+
+func TemplateFieldActionID(fieldID string) string {
+	return "field_" + fieldID
+}
+
+func templateFieldBlocks(template *config.IncidentTemplate) []slack.Block {
+	blocks := make([]slack.Block, 0, len(template.Fields))
+	for _, field := range template.Fields {
+		input := slack.NewInputBlock(
+			TemplateFieldBlockID(field.ID),
+			slack.NewTextBlockObject(slack.PlainTextType, field.Label, true, false),
+			nil,
+			templateFieldElement(field),
+		)
+		input.Optional = !field.Required
+		blocks = append(blocks, input)
+	}
+	return blocks
+}
+
+func templateFieldElement(field config.TemplateField) slack.BlockElement {
+	actionID := TemplateFieldActionID(field.ID)
+	var placeholder *slack.TextBlockObject
+	if field.Placeholder != "" {
+		placeholder = slack.NewTextBlockObject(slack.PlainTextType, field.Placeholder, true, false)
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(field.Options))
+	for _, option := range field.Options {
+		options = append(options, slack.NewOptionBlockObject(option.Value,
+			slack.NewTextBlockObject(slack.PlainTextType, option.Label, true, false), nil))
+	}
+
+	switch field.Type {
+	case config.FieldTypeSelect:
+		element := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, actionID, options...)
+		element.InitialOption = initialOption(options, field.Default)
+		return element
+	case config.FieldTypeMultiSelect:
+		element := slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeStatic, placeholder, actionID, options...)
+		if option := initialOption(options, field.Default); option != nil {
+			element.InitialOptions = []*slack.OptionBlockObject{option}
+		}
+		return element
+	case config.FieldTypeCheckbox:
+		element := slack.NewCheckboxGroupsBlockElement(actionID, options...)
+		if option := initialOption(options, field.Default); option != nil {
+			element.InitialOptions = []*slack.OptionBlockObject{option}
+		}
+		return element
+	case config.FieldTypeDate:
+		element := slack.NewDatePickerBlockElement(actionID)
+		element.Placeholder = placeholder
+		element.InitialDate = field.Default
+		return element
+	case config.FieldTypeUser:
+		element := slack.NewOptionsSelectBlockElement(slack.OptTypeUser, placeholder, actionID)
+		element.InitialUser = field.Default
+		return element
+	default:
+		element := slack.NewPlainTextInputBlockElement(placeholder, actionID)
+		element.Multiline = field.Multiline
+		element.InitialValue = field.Default
+		return element
+	}
+}
+
+func initialOption(options []*slack.OptionBlockObject, value string) *slack.OptionBlockObject {
+	for _, option := range options {
+		if option.Value == value {
+			return option
+		}
+	}
+	return nil
+}
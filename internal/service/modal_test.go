@@ -0,0 +1,253 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/slack-go/slack"
+)
+
+func testTemplate() *config.IncidentTemplate {
+	return &config.IncidentTemplate{
+		ID:          "db-outage",
+		Name:        "Database outage",
+		Title:       "Database outage: ",
+		Description: "Which database is affected?",
+		Urgency:     "critical",
+		Fields: []config.TemplateField{
+			{ID: "cluster", Label: "Cluster", Type: config.FieldTypeText, Required: true},
+		},
+	}
+}
+
+func modalInput(t *testing.T, view slack.ModalViewRequest, blockID string) *slack.InputBlock {
+	t.Helper()
+
+	for _, block := range view.Blocks.BlockSet {
+		if input, ok := block.(*slack.InputBlock); ok && input.BlockID == blockID {
+			return input
+		}
+	}
+	t.Fatalf("view has no %s block", blockID)
+	return nil
+}
+
+func TestTemplateFieldElement(t *testing.T) {
+	options := []config.FieldOption{{Label: "EU", Value: "eu"}, {Label: "US", Value: "us"}}
+
+	tests := []struct {
+		name  string
+		field config.TemplateField
+		check func(t *testing.T, element slack.BlockElement)
+	}{
+		{
+			name:  "text",
+			field: config.TemplateField{ID: "f", Type: config.FieldTypeText, Multiline: true, Default: "n/a"},
+			check: func(t *testing.T, element slack.BlockElement) {
+				input := element.(*slack.PlainTextInputBlockElement)
+				if !input.Multiline || input.InitialValue != "n/a" {
+					t.Fatalf("text input = %+v, want a multiline input with the default", input)
+				}
+			},
+		},
+		{
+			name:  "select",
+			field: config.TemplateField{ID: "f", Type: config.FieldTypeSelect, Options: options, Default: "us"},
+			check: func(t *testing.T, element slack.BlockElement) {
+				selected := element.(*slack.SelectBlockElement)
+				if selected.Type != slack.OptTypeStatic || len(selected.Options) != 2 || selected.InitialOption.Value != "us" {
+					t.Fatalf("select = %+v, want a static select defaulting to us", selected)
+				}
+			},
+		},
+		{
+			name:  "select with an unknown default",
+			field: config.TemplateField{ID: "f", Type: config.FieldTypeSelect, Options: options, Default: "apac"},
+			check: func(t *testing.T, element slack.BlockElement) {
+				if selected := element.(*slack.SelectBlockElement); selected.InitialOption != nil {
+					t.Fatalf("initial option = %+v, want none", selected.InitialOption)
+				}
+			},
+		},
+		{
+			name:  "multi select",
+			field: config.TemplateField{ID: "f", Type: config.FieldTypeMultiSelect, Options: options, Default: "eu"},
+			check: func(t *testing.T, element slack.BlockElement) {
+				selected := element.(*slack.MultiSelectBlockElement)
+				if len(selected.InitialOptions) != 1 || selected.InitialOptions[0].Value != "eu" {
+					t.Fatalf("multi select = %+v, want eu selected", selected)
+				}
+			},
+		},
+		{
+			name:  "checkbox",
+			field: config.TemplateField{ID: "f", Type: config.FieldTypeCheckbox, Options: options, Default: "eu"},
+			check: func(t *testing.T, element slack.BlockElement) {
+				checkboxes := element.(*slack.CheckboxGroupsBlockElement)
+				if len(checkboxes.Options) != 2 || len(checkboxes.InitialOptions) != 1 {
+					t.Fatalf("checkboxes = %+v, want two options with eu checked", checkboxes)
+				}
+			},
+		},
+		{
+			name:  "date",
+			field: config.TemplateField{ID: "f", Type: config.FieldTypeDate, Default: "2026-01-02"},
+			check: func(t *testing.T, element slack.BlockElement) {
+				if date := element.(*slack.DatePickerBlockElement); date.InitialDate != "2026-01-02" {
+					t.Fatalf("date = %q, want 2026-01-02", date.InitialDate)
+				}
+			},
+		},
+		{
+			name:  "user",
+			field: config.TemplateField{ID: "f", Type: config.FieldTypeUser, Default: "U1"},
+			check: func(t *testing.T, element slack.BlockElement) {
+				user := element.(*slack.SelectBlockElement)
+				if user.Type != slack.OptTypeUser || user.InitialUser != "U1" {
+					t.Fatalf("user select = %+v, want a user select defaulting to U1", user)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, templateFieldElement(tt.field))
+		})
+	}
+}
+
+func TestIncidentModalTemplatePrefill(t *testing.T) {
+	template := testTemplate()
+	templates := []config.IncidentTemplate{*template}
+
+	tests := []struct {
+		name            string
+		form            IncidentForm
+		wantTitle       string
+		wantDescription string
+		wantUrgency     string
+		wantTemplateID  string
+	}{
+		{
+			name:            "blank form",
+			form:            IncidentForm{Description: "from the message", Metadata: model.ModalMetadata{TemplateID: "stale"}},
+			wantDescription: "from the message",
+			wantUrgency:     "medium",
+		},
+		{
+			name:            "template",
+			form:            IncidentForm{Template: template, Templates: templates},
+			wantTitle:       "Database outage: ",
+			wantDescription: "Which database is affected?",
+			wantUrgency:     "critical",
+			wantTemplateID:  "db-outage",
+		},
+		{
+			name:            "message text and picked urgency win over the template",
+			form:            IncidentForm{Template: template, Templates: templates, Description: "from the message", Urgency: "low"},
+			wantTitle:       "Database outage: ",
+			wantDescription: "from the message",
+			wantUrgency:     "low",
+			wantTemplateID:  "db-outage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SlackService{logger: quietLogger()}
+			view := s.IncidentModal(tt.form)
+
+			title := modalInput(t, view, "title_block").Element.(*slack.PlainTextInputBlockElement)
+			if title.InitialValue != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title.InitialValue, tt.wantTitle)
+			}
+			description := modalInput(t, view, "description_block").Element.(*slack.PlainTextInputBlockElement)
+			if description.InitialValue != tt.wantDescription {
+				t.Errorf("description = %q, want %q", description.InitialValue, tt.wantDescription)
+			}
+			urgency := modalInput(t, view, UrgencyBlockID).Element.(*slack.SelectBlockElement)
+			if urgency.InitialOption == nil || urgency.InitialOption.Value != tt.wantUrgency {
+				t.Errorf("urgency = %+v, want %q", urgency.InitialOption, tt.wantUrgency)
+			}
+
+			var metadata model.ModalMetadata
+			if err := json.Unmarshal([]byte(view.PrivateMetadata), &metadata); err != nil {
+				t.Fatalf("failed to decode private metadata: %v", err)
+			}
+			if metadata.TemplateID != tt.wantTemplateID {
+				t.Errorf("metadata template = %q, want %q", metadata.TemplateID, tt.wantTemplateID)
+			}
+
+			if tt.form.Template == nil {
+				return
+			}
+			picker := modalInput(t, view, TemplatePickerBlockID).Element.(*slack.SelectBlockElement)
+			if picker.InitialOption.Value != template.ID {
+				t.Errorf("template picker = %q, want %q", picker.InitialOption.Value, template.ID)
+			}
+			if field := modalInput(t, view, TemplateFieldBlockID("cluster")); field.Optional {
+				t.Error("required template field is optional")
+			}
+		})
+	}
+}
+
+func TestUrgencyOptionBlocks(t *testing.T) {
+	levels := []config.UrgencyLevel{
+		{Value: "sev1", Label: "Sev 1", Emoji: "🔥", Description: "Customers are affected", Priority: "P1"},
+		{Value: "sev2", Label: "Sev 2", Priority: "P2"},
+		{Value: "fyi", Label: "FYI", Priority: "P5"},
+	}
+
+	tests := []struct {
+		selected     string
+		wantCritical bool
+	}{
+		{"sev1", true},
+		{"sev2", false},
+		{"fyi", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selected, func(t *testing.T) {
+			options, critical := urgencyOptionBlocks(levels, tt.selected)
+			if critical != tt.wantCritical {
+				t.Fatalf("critical = %v, want %v", critical, tt.wantCritical)
+			}
+			if len(options) != 3 || options[2].Value != "fyi" {
+				t.Fatalf("options = %+v, want one option per level in order", options)
+			}
+			if options[0].Text.Text != "🔥 Sev 1" || options[0].Description.Text != "Customers are affected" {
+				t.Fatalf("first option = %q %+v, want the emoji label and description", options[0].Text.Text, options[0].Description)
+			}
+			if options[1].Description != nil {
+				t.Fatalf("second option description = %+v, want none", options[1].Description)
+			}
+		})
+	}
+}
+
+func TestIncidentModalAsksForImpactOnlyWhenCritical(t *testing.T) {
+	s := &SlackService{logger: quietLogger()}
+
+	for _, tt := range []struct {
+		urgency string
+		want    bool
+	}{{"critical", true}, {"high", false}, {"", false}} {
+		view := s.IncidentModal(IncidentForm{Urgency: tt.urgency})
+
+		found := false
+		for _, block := range view.Blocks.BlockSet {
+			if input, ok := block.(*slack.InputBlock); ok && input.BlockID == ImpactBlockID {
+				found = true
+			}
+		}
+		if found != tt.want {
+			t.Errorf("urgency %q: impact block = %v, want %v", tt.urgency, found, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+)
+
+func (s *AlertService) GetUserTeams(email string) ([]model.OpsGenieTeam, error) {
+	var response struct {
+		Data []model.OpsGenieTeam `json:"data"`
+	}
+
+	if err := s.doRequest(http.MethodGet, "/users/"+url.PathEscape(email)+"/teams", nil, &response); err != nil {
+		return nil, fmt.Errorf("error getting user teams: %w", err)
+	}
+
+	return response.Data, nil
+}
+
+func (s *AlertService) ListTeams() ([]model.OpsGenieTeam, error) {
+	var response struct {
+		Data []model.OpsGenieTeam `json:"data"`
+	}
+
+	if err := s.doRequest(http.MethodGet, "/teams", nil, &response); err != nil {
+		return nil, fmt.Errorf("error listing teams: %w", err)
+	}
+
+	return response.Data, nil
+}
+
+func (s *AlertService) GetOnCallForUser(email string) ([]model.OnCall, error) {
+	teams := []model.OpsGenieTeam{{ID: s.teamID}}
+	if email != "" {
+		userTeams, err := s.GetUserTeams(email)
+		if err != nil {
+			s.logger.WithError(err).Debug("Falling back to default team for on-call lookup")
+		} else if len(userTeams) > 0 {
+			teams = userTeams
+		}
+	}
+
+	return s.GetOnCallForTeams(teams)
+}
+
+func (s *AlertService) GetOnCallRecipients(teamID string) ([]string, error) {
+	if teamID == "" {
+		teamID = s.teamID
+	}
+
+	onCalls, err := s.GetOnCallForTeams([]model.OpsGenieTeam{{ID: teamID}})
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []string
+	for _, onCall := range onCalls {
+		recipients = append(recipients, onCall.Recipients...)
+	}
+	return recipients, nil
+}
+
+func (s *AlertService) GetOnCallForTeams(teams []model.OpsGenieTeam) ([]model.OnCall, error) {
+	var schedules struct {
+		Data []struct {
+			ID        string             `json:"id"`
+			Name      string             `json:"name"`
+			Enabled   bool               `json:"enabled"`
+			OwnerTeam model.OpsGenieTeam `json:"ownerTeam"`
+		} `json:"data"`
+	}
+
+	if err := s.doRequest(http.MethodGet, "/schedules", nil, &schedules); err != nil {
+		return nil, fmt.Errorf("error listing schedules: %w", err)
+	}
+
+	teamNames := make(map[string]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+
+	var onCalls []model.OnCall
+	for _, schedule := range schedules.Data {
+		teamName, ok := teamNames[schedule.OwnerTeam.ID]
+		if !ok || !schedule.Enabled {
+			continue
+		}
+		if teamName == "" {
+			teamName = schedule.OwnerTeam.Name
+		}
+
+		var response struct {
+			Data struct {
+				OnCallRecipients []string `json:"onCallRecipients"`
+			} `json:"data"`
+		}
+
+		path := fmt.Sprintf("/schedules/%s/on-calls?flat=true", url.PathEscape(schedule.ID))
+		if err := s.doRequest(http.MethodGet, path, nil, &response); err != nil {
+			s.logger.WithError(err).WithField("schedule_id", schedule.ID).Warn("Failed to get on-call recipients")
+			continue
+		}
+
+		onCalls = append(onCalls, model.OnCall{
+			TeamName:     teamName,
+			ScheduleName: schedule.Name,
+			Recipients:   response.Data.OnCallRecipients,
+		})
+	}
+
+	return onCalls, nil
+}
+
+func (s *AlertService) GetUser(identifier string) (*model.OpsGenieUser, error) {
+	var response struct {
+		Data model.OpsGenieUser `json:"data"`
+	}
+
+	if err := s.doRequest(http.MethodGet, "/users/"+url.PathEscape(identifier), nil, &response); err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	return &response.Data, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	groupMembersKeyPrefix = "permissions:group:"
+	groupMembersTTL       = 5 * time.Minute
+)
+
+type PermissionService struct {
+	config       config.PermissionConfig
+	slackService *SlackService
+	store        store.Store
+	audit        *AuditService
+	logger       *logrus.Logger
+}
+
+func NewPermissionService(
+	permissionConfig config.PermissionConfig,
+	slackService *SlackService,
+	store store.Store,
+	audit *AuditService,
+	logger *logrus.Logger,
+) *PermissionService {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &PermissionService{
+		config:       permissionConfig,
+		slackService: slackService,
+		store:        store,
+		audit:        audit,
+		logger:       logger,
+	}
+}
+
+func (s *PermissionService) Authorize(request model.AccessRequest) model.AccessDecision {
+	effect := s.config.Default
+	var lookupErr error
+	for _, rule := range s.config.Rules {
+		if !rule.AppliesTo(request.Action, string(request.Priority)) {
+			continue
+		}
+		matched, err := s.matches(rule, request)
+		if err != nil && rule.Effect == config.EffectDeny {
+			effect = config.EffectDeny
+			lookupErr = err
+			break
+		}
+		if !matched {
+			continue
+		}
+		effect = rule.Effect
+		break
+	}
+
+	if effect != config.EffectDeny {
+		return model.AccessDecision{Allowed: true}
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"user_id":    request.UserID,
+		"channel_id": request.ChannelID,
+		"action":     request.Action,
+		"priority":   request.Priority,
+	})
+
+	event := model.AuditEvent{
+		Action:    request.Action,
+		Outcome:   model.AuditOutcomeDenied,
+		UserID:    request.UserID,
+		ChannelID: request.ChannelID,
+		Priority:  request.Priority,
+	}
+
+	message := s.deniedMessage(request)
+	if lookupErr != nil {
+		logger.WithError(lookupErr).Warn("Permission denied because group membership could not be verified")
+		event.Error = lookupErr.Error()
+		message = "Your permissions could not be verified because Slack user groups could not be loaded. Please try again in a moment."
+	} else {
+		logger.Warn("Permission denied")
+	}
+	s.audit.Record(event)
+
+	return model.AccessDecision{Allowed: false, Message: message}
+}
+
+func (s *PermissionService) matches(rule config.PermissionRule, request model.AccessRequest) (bool, error) {
+	if rule.Everyone() {
+		return true, nil
+	}
+	for _, channelID := range rule.Channels {
+		if channelID == request.ChannelID {
+			return true, nil
+		}
+	}
+	return s.isMember(request.UserID, rule.Users, rule.Groups)
+}
+
+func (s *PermissionService) IsMember(userID string, users, groups []string) bool {
+	member, err := s.isMember(userID, users, groups)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to resolve user group members")
+	}
+	return member
+}
+
+func (s *PermissionService) isMember(userID string, users, groups []string) (bool, error) {
+	for _, candidate := range users {
+		if candidate == userID {
+			return true, nil
+		}
+	}
+
+	var lookupErr error
+	for _, groupID := range groups {
+		members, err := s.groupMembers(groupID)
+		if err != nil {
+			lookupErr = fmt.Errorf("failed to resolve members of user group %s: %w", groupID, err)
+			continue
+		}
+		for _, member := range members {
+			if member == userID {
+				return true, nil
+			}
+		}
+	}
+	return false, lookupErr
+}
+
+func (s *PermissionService) groupMembers(groupID string) ([]string, error) {
+	key := groupMembersKeyPrefix + groupID
+	if cached, ok, err := s.store.Get(key); err != nil {
+		s.logger.WithError(err).Warn("Failed to read cached group members")
+	} else if ok {
+		var members []string
+		if err := json.Unmarshal([]byte(cached), &members); err == nil {
+			return members, nil
+		}
+	}
+
+	members, err := s.slackService.GetUserGroupMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal group members: %w", err)
+	}
+	if err := s.store.Set(key, string(data), groupMembersTTL); err != nil {
+		s.logger.WithError(err).Warn("Failed to cache group members")
+	}
+
+	return members, nil
+}
+
+func (s *PermissionService) deniedMessage(request model.AccessRequest) string {
+	message := fmt.Sprintf("You are not allowed to %s incidents", actionVerb(request.Action))
+	if request.Action == model.ActionMaintenance {
+		message = "You are not allowed to manage maintenance windows"
+	} else if request.Priority != "" {
+		message = fmt.Sprintf("You are not allowed to %s %s incidents", actionVerb(request.Action), request.Priority)
+	}
+	message += "."
+	if s.config.DeniedMessage != "" {
+		message += " " + s.config.DeniedMessage
+	}
+	return message
+}
+
+func actionVerb(action string) string {
+	switch action {
+	case model.ActionAck:
+		return "acknowledge"
+	case model.ActionCreate:
+		return "raise"
+	default:
+		return action
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/audit"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+func newTestSlackService(t *testing.T, groups map[string][]string) *SlackService {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/usergroups.users.list" {
+			t.Errorf("unexpected Slack call %s", r.URL.Path)
+			return
+		}
+		members, ok := groups[r.FormValue("usergroup")]
+		if !ok {
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "no_such_subteam"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "users": members})
+	}))
+	t.Cleanup(server.Close)
+
+	return &SlackService{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/")),
+		logger: quietLogger(),
+	}
+}
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return logger
+}
+
+func newTestPermissionService(t *testing.T, permissions config.PermissionConfig, groups map[string][]string) (*PermissionService, store.Store) {
+	t.Helper()
+
+	st := store.NewMemoryStore()
+	auditService := NewAuditService(audit.NewStoreSink(st), "", quietLogger())
+	return NewPermissionService(permissions, newTestSlackService(t, groups), st, auditService, quietLogger()), st
+}
+
+func TestAuthorize(t *testing.T) {
+	permissions := config.PermissionConfig{
+		Default: config.EffectAllow,
+		Rules: []config.PermissionRule{
+			{Effect: config.EffectAllow, Actions: []string{"create"}, Priorities: []string{"P1"}, Groups: []string{"S_ONCALL"}},
+			{Effect: config.EffectAllow, Actions: []string{"create"}, Priorities: []string{"P1"}, Channels: []string{"C_WAR_ROOM"}},
+			{Effect: config.EffectDeny, Actions: []string{"create"}, Priorities: []string{"P1"}},
+			{Effect: config.EffectDeny, Actions: []string{"maintenance"}, Users: []string{"U_INTERN"}},
+			{Effect: config.EffectDeny, Actions: []string{"close"}, Priorities: []string{"P1"}, Users: []string{"U_INTERN"}},
+			{Effect: config.EffectDeny, Actions: []string{"ack"}, Users: []string{"U_GUEST"}},
+		},
+	}
+	groups := map[string][]string{"S_ONCALL": {"U_ONCALL"}}
+
+	tests := []struct {
+		name    string
+		request model.AccessRequest
+		allowed bool
+	}{
+		{"group member raises P1", model.AccessRequest{UserID: "U_ONCALL", ChannelID: "C_GENERAL", Action: "create", Priority: "P1"}, true},
+		{"anyone raises P1 in the war room", model.AccessRequest{UserID: "U_OTHER", ChannelID: "C_WAR_ROOM", Action: "create", Priority: "P1"}, true},
+		{"others cannot raise P1", model.AccessRequest{UserID: "U_OTHER", ChannelID: "C_GENERAL", Action: "create", Priority: "P1"}, false},
+		{"no rule for P3 uses the default", model.AccessRequest{UserID: "U_OTHER", ChannelID: "C_GENERAL", Action: "create", Priority: "P3"}, true},
+		{"listed user is denied maintenance", model.AccessRequest{UserID: "U_INTERN", Action: "maintenance"}, false},
+		{"other users may manage maintenance", model.AccessRequest{UserID: "U_OTHER", Action: "maintenance"}, true},
+		{"listed user cannot close P1", model.AccessRequest{UserID: "U_INTERN", Action: "close", Priority: "P1"}, false},
+		{"listed user may close P3", model.AccessRequest{UserID: "U_INTERN", Action: "close", Priority: "P3"}, true},
+		{"listed user cannot acknowledge", model.AccessRequest{UserID: "U_GUEST", Action: "ack", Priority: "P2"}, false},
+		{"close rules do not apply to ack", model.AccessRequest{UserID: "U_INTERN", Action: "ack", Priority: "P1"}, true},
+	}
+
+	s, _ := newTestPermissionService(t, permissions, groups)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := s.Authorize(tt.request)
+			if decision.Allowed != tt.allowed {
+				t.Fatalf("Authorize() allowed = %v, want %v (%s)", decision.Allowed, tt.allowed, decision.Message)
+			}
+			if !decision.Allowed && decision.Message == "" {
+				t.Fatal("Authorize() denied without a message")
+			}
+		})
+	}
+}
+
+func TestAuthorizeDefaultDeny(t *testing.T) {
+	s, _ := newTestPermissionService(t, config.PermissionConfig{
+		Default: config.EffectDeny,
+		Rules: []config.PermissionRule{
+			{Effect: config.EffectAllow, Users: []string{"U_ADMIN"}},
+		},
+	}, nil)
+
+	if !s.Authorize(model.AccessRequest{UserID: "U_ADMIN", Action: "create"}).Allowed {
+		t.Error("listed user was denied")
+	}
+	if s.Authorize(model.AccessRequest{UserID: "U_OTHER", Action: "create"}).Allowed {
+		t.Error("unlisted user was allowed")
+	}
+}
+
+func TestAuthorizeFailsClosedWhenGroupLookupFails(t *testing.T) {
+	s, st := newTestPermissionService(t, config.PermissionConfig{
+		Default: config.EffectAllow,
+		Rules: []config.PermissionRule{
+			{Effect: config.EffectDeny, Actions: []string{"create"}, Groups: []string{"S_MISSING"}},
+		},
+	}, nil)
+
+	decision := s.Authorize(model.AccessRequest{UserID: "U_OTHER", Action: "create", Priority: "P2"})
+	if decision.Allowed {
+		t.Fatal("Authorize() allowed a request whose deny rule could not be evaluated")
+	}
+	if decision.Message == "" {
+		t.Fatal("Authorize() did not explain the failure")
+	}
+
+	events, err := audit.NewStoreSink(st).Recent("", 10)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Outcome != model.AuditOutcomeDenied || events[0].Error == "" {
+		t.Fatalf("audit events = %+v, want one denial with the lookup error", events)
+	}
+}
+
+func TestAuthorizeSkipsAllowRuleWhenGroupLookupFails(t *testing.T) {
+	s, _ := newTestPermissionService(t, config.PermissionConfig{
+		Default: config.EffectDeny,
+		Rules: []config.PermissionRule{
+			{Effect: config.EffectAllow, Groups: []string{"S_MISSING"}},
+		},
+	}, nil)
+
+	if s.Authorize(model.AccessRequest{UserID: "U_OTHER", Action: "create"}).Allowed {
+		t.Fatal("Authorize() allowed a request whose allow rule could not be evaluated")
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+var rateLimitMetrics = expvar.NewMap("rate_limit")
+
+type RateLimiter struct {
+	config      config.RateLimitConfig
+	permissions *PermissionService
+	store       store.Store
+	audit       *AuditService
+	logger      *logrus.Logger
+}
+
+func NewRateLimiter(
+	rateLimitConfig config.RateLimitConfig,
+	permissions *PermissionService,
+	store store.Store,
+	audit *AuditService,
+	logger *logrus.Logger,
+) *RateLimiter {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &RateLimiter{
+		config:      rateLimitConfig,
+		permissions: permissions,
+		store:       store,
+		audit:       audit,
+		logger:      logger,
+	}
+}
+
+func (l *RateLimiter) Allow(request model.AccessRequest) model.AccessDecision {
+	if len(l.config.Rules) == 0 {
+		return model.AccessDecision{Allowed: true}
+	}
+	rateLimitMetrics.Add("checked", 1)
+
+	if l.permissions.IsMember(request.UserID, l.config.OverrideUsers, l.config.OverrideGroups) {
+		rateLimitMetrics.Add("overridden", 1)
+		return model.AccessDecision{Allowed: true}
+	}
+
+	type counter struct {
+		key string
+		ttl time.Duration
+	}
+
+	now := time.Now()
+	var counted []counter
+	for i, rule := range l.config.Rules {
+		subject := request.UserID
+		if rule.Scope == config.RateLimitScopeChannel {
+			subject = request.ChannelID
+		}
+		if subject == "" || !rule.AppliesTo(string(request.Priority)) {
+			continue
+		}
+
+		window := now.Truncate(rule.Window)
+		key := fmt.Sprintf("%s%d:%s:%d", rateLimitKeyPrefix, i, subject, window.Unix())
+		count, err := l.store.Increment(key, 1, rule.Window)
+		if err != nil {
+			l.logger.WithError(err).Warn("Failed to update rate limit counter")
+			continue
+		}
+		counted = append(counted, counter{key: key, ttl: rule.Window})
+
+		if count > int64(rule.Max) {
+			for _, c := range counted {
+				if _, err := l.store.Increment(c.key, -1, c.ttl); err != nil {
+					l.logger.WithError(err).Warn("Failed to release rate limit counter")
+				}
+			}
+			return l.deny(request, rule, window.Add(rule.Window).Sub(now))
+		}
+	}
+
+	rateLimitMetrics.Add("allowed", 1)
+	return model.AccessDecision{Allowed: true}
+}
+
+func (l *RateLimiter) deny(request model.AccessRequest, rule config.RateLimitRule, retryAfter time.Duration) model.AccessDecision {
+	rateLimitMetrics.Add("limited", 1)
+	rateLimitMetrics.Add("limited_"+rule.Scope, 1)
+
+	l.logger.WithFields(logrus.Fields{
+		"user_id":    request.UserID,
+		"channel_id": request.ChannelID,
+		"priority":   request.Priority,
+		"scope":      rule.Scope,
+		"max":        rule.Max,
+		"window":     rule.Window.String(),
+	}).Warn("Rate limit reached")
+
+	l.audit.Record(model.AuditEvent{
+		Action:    request.Action,
+		Outcome:   model.AuditOutcomeLimited,
+		UserID:    request.UserID,
+		ChannelID: request.ChannelID,
+		Priority:  request.Priority,
+	})
+
+	kind := "incidents"
+	if len(rule.Priorities) > 0 {
+		kind = fmt.Sprintf("%s incidents", request.Priority)
+	}
+	who := "You have"
+	if rule.Scope == config.RateLimitScopeChannel {
+		who = "This channel has"
+	}
+
+	return model.AccessDecision{
+		Allowed: false,
+		Message: fmt.Sprintf("%s reached the limit of %d %s per %s. Try again in %s, or ask an incident commander to raise it for you.",
+			who, rule.Max, kind, strings.TrimPrefix(formatWindow(rule.Window), "1 "), formatWindow(retryAfter.Round(time.Minute))),
+	}
+}
+
+func formatWindow(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return plural(int(d/(24*time.Hour)), "day")
+	case d >= time.Hour && d%time.Hour == 0:
+		return plural(int(d/time.Hour), "hour")
+	case d < time.Minute:
+		return "a minute"
+	default:
+		return plural(int(d/time.Minute), "minute")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/audit"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+)
+
+func newTestRateLimiter(t *testing.T, rateLimits config.RateLimitConfig) *RateLimiter {
+	t.Helper()
+	return newTestRateLimiterWithStore(t, rateLimits, store.NewMemoryStore())
+}
+
+func newTestRateLimiterWithStore(t *testing.T, rateLimits config.RateLimitConfig, st store.Store) *RateLimiter {
+	t.Helper()
+
+	auditService := NewAuditService(audit.NewStoreSink(st), "", quietLogger())
+	permissions := NewPermissionService(config.PermissionConfig{}, newTestSlackService(t, nil), st, auditService, quietLogger())
+	return NewRateLimiter(rateLimits, permissions, st, auditService, quietLogger())
+}
+
+func TestRateLimiterPerUser(t *testing.T) {
+	limiter := newTestRateLimiter(t, config.RateLimitConfig{
+		Rules: []config.RateLimitRule{{Scope: config.RateLimitScopeUser, Max: 2, Window: time.Hour}},
+	})
+
+	request := model.AccessRequest{UserID: "U1", ChannelID: "C1", Action: model.ActionCreate, Priority: model.PriorityP3}
+	for i := 0; i < 2; i++ {
+		if decision := limiter.Allow(request); !decision.Allowed {
+			t.Fatalf("request %d was limited: %s", i+1, decision.Message)
+		}
+	}
+
+	decision := limiter.Allow(request)
+	if decision.Allowed {
+		t.Fatal("third request was allowed")
+	}
+	if !strings.HasPrefix(decision.Message, "You have reached the limit of 2 incidents per hour.") {
+		t.Fatalf("unexpected message %q", decision.Message)
+	}
+
+	other := request
+	other.UserID = "U2"
+	if !limiter.Allow(other).Allowed {
+		t.Fatal("another user was limited")
+	}
+}
+
+func TestRateLimiterPerChannelAndPriority(t *testing.T) {
+	limiter := newTestRateLimiter(t, config.RateLimitConfig{
+		Rules: []config.RateLimitRule{{Scope: config.RateLimitScopeChannel, Priorities: []string{"P1"}, Max: 1, Window: 24 * time.Hour}},
+	})
+
+	p1 := model.AccessRequest{UserID: "U1", ChannelID: "C1", Action: model.ActionCreate, Priority: model.PriorityP1}
+	if !limiter.Allow(p1).Allowed {
+		t.Fatal("first P1 was limited")
+	}
+
+	p1.UserID = "U2"
+	decision := limiter.Allow(p1)
+	if decision.Allowed {
+		t.Fatal("second P1 in the channel was allowed")
+	}
+	if !strings.HasPrefix(decision.Message, "This channel has reached the limit of 1 P1 incidents per day.") {
+		t.Fatalf("unexpected message %q", decision.Message)
+	}
+
+	p2 := p1
+	p2.Priority = model.PriorityP2
+	if !limiter.Allow(p2).Allowed {
+		t.Fatal("P2 was limited by a P1 rule")
+	}
+
+	p1.ChannelID = "C2"
+	if !limiter.Allow(p1).Allowed {
+		t.Fatal("P1 in another channel was limited")
+	}
+}
+
+func TestRateLimiterDoesNotCountDeniedRequests(t *testing.T) {
+	limiter := newTestRateLimiter(t, config.RateLimitConfig{
+		Rules: []config.RateLimitRule{
+			{Scope: config.RateLimitScopeUser, Max: 5, Window: time.Hour},
+			{Scope: config.RateLimitScopeChannel, Max: 1, Window: time.Hour},
+		},
+	})
+
+	request := model.AccessRequest{UserID: "U1", ChannelID: "C1", Action: model.ActionCreate}
+	limiter.Allow(request)
+	for i := 0; i < 10; i++ {
+		limiter.Allow(request)
+	}
+
+	request.ChannelID = "C2"
+	if !limiter.Allow(request).Allowed {
+		t.Fatal("requests denied by the channel rule were counted against the user")
+	}
+}
+
+func TestRateLimiterSharesCountersAcrossInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	rateLimits := config.RateLimitConfig{
+		Rules: []config.RateLimitRule{{Scope: config.RateLimitScopeUser, Max: 5, Window: time.Hour}},
+	}
+
+	var limiters []*RateLimiter
+	for i := 0; i < 3; i++ {
+		st, err := store.NewRedisStore("redis://"+server.Addr(), "bot:")
+		if err != nil {
+			t.Fatalf("NewRedisStore() error = %v", err)
+		}
+		limiters = append(limiters, newTestRateLimiterWithStore(t, rateLimits, st))
+	}
+
+	request := model.AccessRequest{UserID: "U1", ChannelID: "C1", Action: model.ActionCreate, Priority: model.PriorityP3}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(limiter *RateLimiter) {
+			defer wg.Done()
+			if limiter.Allow(request).Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}(limiters[i%len(limiters)])
+	}
+	wg.Wait()
+
+	if allowed != 5 {
+		t.Fatalf("allowed %d requests across instances, want 5", allowed)
+	}
+}
+
+func TestRateLimiterOverrideUsers(t *testing.T) {
+	limiter := newTestRateLimiter(t, config.RateLimitConfig{
+		Rules:         []config.RateLimitRule{{Scope: config.RateLimitScopeUser, Max: 1, Window: time.Hour}},
+		OverrideUsers: []string{"U_COMMANDER"},
+	})
+
+	request := model.AccessRequest{UserID: "U_COMMANDER", ChannelID: "C1", Action: model.ActionCreate}
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow(request).Allowed {
+			t.Fatalf("override user was limited on request %d", i+1)
+		}
+	}
+}
+
+func TestFormatWindow(t *testing.T) {
+	tests := []struct {
+		window time.Duration
+		want   string
+	}{
+		{30 * time.Second, "a minute"},
+		{time.Minute, "1 minute"},
+		{45 * time.Minute, "45 minutes"},
+		{90 * time.Minute, "90 minutes"},
+		{time.Hour, "1 hour"},
+		{3 * time.Hour, "3 hours"},
+		{24 * time.Hour, "1 day"},
+		{48 * time.Hour, "2 days"},
+	}
+
+	for _, tt := range tests {
+		if got := formatWindow(tt.window); got != tt.want {
+			t.Errorf("formatWindow(%s) = %q, want %q", tt.window, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+)
+
+var (
+	ErrEscalationNotSupported = errors.New("incidents can only be escalated to a team")
+	ErrAssignNotSupported     = errors.New("incidents cannot be assigned")
+	ErrAckNotSupported        = errors.New("incidents cannot be acknowledged")
+)
+
+func (s *AlertService) FindByTinyID(tinyID string) (*model.AlertCreationResult, error) {
+	var response struct {
+		Data struct {
+			ID        string    `json:"id"`
+			TinyID    string    `json:"tinyId"`
+			Message   string    `json:"message"`
+			Priority  string    `json:"priority"`
+			CreatedAt time.Time `json:"createdAt"`
+		} `json:"data"`
+	}
+
+	err := s.doRequest(http.MethodGet, "/alerts/"+url.PathEscape(tinyID)+"?identifierType=tiny", nil, &response)
+	if err == nil {
+		return &model.AlertCreationResult{
+			ID:        response.Data.ID,
+			TinyID:    response.Data.TinyID,
+			Title:     response.Data.Message,
+			Priority:  model.AlertPriority(response.Data.Priority),
+			URL:       s.alertURL(response.Data.ID),
+			CreatedAt: response.Data.CreatedAt,
+			Kind:      model.ResultKindAlert,
+		}, nil
+	}
+	if !IsNotFound(err) {
+		return nil, fmt.Errorf("error getting alert %s: %w", tinyID, err)
+	}
+
+	requestURL := fmt.Sprintf("%s/incidents/%s?identifierType=tiny", s.incidentBaseURL, url.PathEscape(tinyID))
+	if err := s.do(http.MethodGet, requestURL, nil, &response); err != nil {
+		return nil, fmt.Errorf("error getting incident %s: %w", tinyID, err)
+	}
+
+	return &model.AlertCreationResult{
+		ID:        response.Data.ID,
+		TinyID:    response.Data.TinyID,
+		Title:     response.Data.Message,
+		Priority:  model.AlertPriority(response.Data.Priority),
+		URL:       s.incidentURL(response.Data.ID),
+		CreatedAt: response.Data.CreatedAt,
+		Kind:      model.ResultKindIncident,
+	}, nil
+}
+
+func (s *AlertService) FindAlertByAlias(alias string) (*model.AlertCreationResult, bool, error) {
+	var response struct {
+		Data struct {
+			ID        string    `json:"id"`
+			TinyID    string    `json:"tinyId"`
+			Message   string    `json:"message"`
+			Priority  string    `json:"priority"`
+			CreatedAt time.Time `json:"createdAt"`
+		} `json:"data"`
+	}
+
+	err := s.doRequest(http.MethodGet, "/alerts/"+url.PathEscape(alias)+"?identifierType=alias", nil, &response)
+	if IsNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error getting alert %s: %w", alias, err)
+	}
+
+	return &model.AlertCreationResult{
+		ID:        response.Data.ID,
+		TinyID:    response.Data.TinyID,
+		Title:     response.Data.Message,
+		Priority:  model.AlertPriority(response.Data.Priority),
+		URL:       s.alertURL(response.Data.ID),
+		CreatedAt: response.Data.CreatedAt,
+		Kind:      model.ResultKindAlert,
+	}, true, nil
+}
+
+func (s *AlertService) ListEscalations() ([]model.EscalationTarget, error) {
+	var response struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+
+	if err := s.doRequest(http.MethodGet, "/escalations", nil, &response); err != nil {
+		return nil, fmt.Errorf("error listing escalations: %w", err)
+	}
+
+	escalations := make([]model.EscalationTarget, 0, len(response.Data))
+	for _, data := range response.Data {
+		escalations = append(escalations, model.EscalationTarget{
+			Type: model.ResponderEscalation,
+			ID:   data.ID,
+			Name: data.Name,
+		})
+	}
+	return escalations, nil
+}
+
+func (s *AlertService) Escalate(ref model.AlertRef, target model.EscalationTarget, note, user string) error {
+	if ref.Kind == model.ResultKindIncident {
+		if target.Type != model.ResponderTeam {
+			return ErrEscalationNotSupported
+		}
+
+		payload := map[string]interface{}{
+			"responders": []model.Responder{{Type: model.ResponderTeam, ID: target.ID}},
+		}
+		if note != "" {
+			payload["note"] = note
+		}
+
+		requestURL := fmt.Sprintf("%s/incidents/%s/responders?identifierType=id", s.incidentBaseURL, url.PathEscape(ref.ID))
+		if err := s.do(http.MethodPost, requestURL, payload, nil); err != nil {
+			return fmt.Errorf("error adding incident responder: %w", err)
+		}
+		return nil
+	}
+
+	payload := map[string]interface{}{
+		"source": "Slack",
+	}
+	if note != "" {
+		payload["note"] = note
+	}
+	if user != "" {
+		payload["user"] = user
+	}
+
+	var path string
+	switch target.Type {
+	case model.ResponderEscalation:
+		path = fmt.Sprintf("/alerts/%s/escalate?identifierType=id", url.PathEscape(ref.ID))
+		payload["escalation"] = map[string]string{"id": target.ID}
+	default:
+		path = fmt.Sprintf("/alerts/%s/responders?identifierType=id", url.PathEscape(ref.ID))
+		payload["responder"] = model.Responder{Type: model.ResponderTeam, ID: target.ID}
+	}
+
+	if err := s.doRequest(http.MethodPost, path, payload, nil); err != nil {
+		return fmt.Errorf("error escalating alert: %w", err)
+	}
+	return nil
+}
+
+func (s *AlertService) Assign(ref model.AlertRef, owner, user string) error {
+	if ref.Kind == model.ResultKindIncident {
+		return ErrAssignNotSupported
+	}
+
+	payload := map[string]interface{}{
+		"owner":  map[string]string{"username": owner},
+		"source": "Slack",
+	}
+	if user != "" {
+		payload["user"] = user
+	}
+
+	path := fmt.Sprintf("/alerts/%s/assign?identifierType=id", url.PathEscape(ref.ID))
+	if err := s.doRequest(http.MethodPost, path, payload, nil); err != nil {
+		return fmt.Errorf("error assigning alert: %w", err)
+	}
+	return nil
+}
+
+func (s *AlertService) Acknowledge(ref model.AlertRef, user string) error {
+	if ref.Kind == model.ResultKindIncident {
+		return ErrAckNotSupported
+	}
+
+	path := fmt.Sprintf("/alerts/%s/acknowledge?identifierType=id", url.PathEscape(ref.ID))
+	if err := s.doRequest(http.MethodPost, path, actionPayload(user), nil); err != nil {
+		return fmt.Errorf("error acknowledging alert: %w", err)
+	}
+	return nil
+}
+
+func (s *AlertService) Close(ref model.AlertRef, user string) error {
+	if ref.Kind == model.ResultKindIncident {
+		requestURL := fmt.Sprintf("%s/incidents/%s/close?identifierType=id", s.incidentBaseURL, url.PathEscape(ref.ID))
+		if err := s.do(http.MethodPost, requestURL, map[string]interface{}{"note": "Closed from Slack"}, nil); err != nil {
+			return fmt.Errorf("error closing incident: %w", err)
+		}
+		return nil
+	}
+
+	path := fmt.Sprintf("/alerts/%s/close?identifierType=id", url.PathEscape(ref.ID))
+	if err := s.doRequest(http.MethodPost, path, actionPayload(user), nil); err != nil {
+		return fmt.Errorf("error closing alert: %w", err)
+	}
+	return nil
+}
+
+func actionPayload(user string) map[string]interface{} {
+	payload := map[string]interface{}{
+		"source": "Slack",
+	}
+	if user != "" {
+		payload["user"] = user
+	}
+	return payload
+}
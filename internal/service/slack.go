@@ -4,11 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
 	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
 
+const (
+	maxModalInputLength = 3000
+	maxModalTitleLength = 24
+)
+
 type SlackService struct {
 	client *slack.Client
 	logger *logrus.Logger
@@ -16,7 +22,7 @@ type SlackService struct {
 
 func NewSlackService(token string) *SlackService {
 	return &SlackService{
-		client: slack.New(token),
+		client: slack.New(token, slack.OptionHTTPClient(slackHTTPClient)),
 		logger: logrus.New(),
 	}
 }
@@ -26,17 +32,57 @@ func NewSlackServiceWithLogger(token string, logger *logrus.Logger) *SlackServic
 		logger = logrus.New()
 	}
 	return &SlackService{
-		client: slack.New(token),
+		client: slack.New(token, slack.OptionHTTPClient(slackHTTPClient)),
+		logger: logger,
+	}
+}
+
+func NewSlackServiceWithClient(client *slack.Client, logger *logrus.Logger) *SlackService {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &SlackService{
+		client: client,
 		logger: logger,
 	}
 }
 
-func (s *SlackService) OpenIncidentModal(triggerID string, channelInfo model.SlackCommand) error {
+func (s *SlackService) IncidentModal(form IncidentForm) slack.ModalViewRequest {
+	minQueryLength := 0
+	metadata := form.Metadata
+	template := form.Template
+	description := form.Description
+	modalTitle := "Create Incident"
+	title := ""
+	urgency := form.Urgency
+	metadata.TemplateID = ""
+	if template != nil {
+		metadata.TemplateID = template.ID
+		modalTitle = truncateText(template.Name, maxModalTitleLength)
+		title = template.Title
+		if description == "" {
+			description = template.Description
+		}
+		if urgency == "" {
+			urgency = template.Urgency
+		}
+	}
+	levels := form.Urgencies
+	if len(levels) == 0 {
+		levels = config.DefaultUrgencies()
+	}
+	if urgency == "" {
+		urgency = config.DefaultUrgency(levels)
+	}
+	description = truncateText(description, maxModalInputLength)
+
+	urgencyOptions, critical := urgencyOptionBlocks(levels, urgency)
+
 	modalView := slack.ModalViewRequest{
 		Type: "modal",
 		Title: &slack.TextBlockObject{
 			Type:  "plain_text",
-			Text:  "Create Incident",
+			Text:  modalTitle,
 			Emoji: true,
 		},
 		Submit: &slack.TextBlockObject{
@@ -59,14 +105,16 @@ func (s *SlackService) OpenIncidentModal(triggerID string, channelInfo model.Sla
 						Text:  "Title",
 						Emoji: true,
 					},
-					Element: slack.NewPlainTextInputBlockElement(
-						&slack.TextBlockObject{
+					Element: &slack.PlainTextInputBlockElement{
+						Type:         slack.METPlainTextInput,
+						ActionID:     "title",
+						InitialValue: title,
+						Placeholder: &slack.TextBlockObject{
 							Type:  "plain_text",
 							Text:  "Enter incident title",
 							Emoji: true,
 						},
-						"title",
-					),
+					},
 				},
 				&slack.InputBlock{
 					Type:    "input",
@@ -77,9 +125,10 @@ func (s *SlackService) OpenIncidentModal(triggerID string, channelInfo model.Sla
 						Emoji: true,
 					},
 					Element: &slack.PlainTextInputBlockElement{
-						Type:      slack.METPlainTextInput,
-						ActionID:  "description",
-						Multiline: true,
+						Type:         slack.METPlainTextInput,
+						ActionID:     "description",
+						Multiline:    true,
+						InitialValue: description,
 						Placeholder: &slack.TextBlockObject{
 							Type:  "plain_text",
 							Text:  "Describe the incident",
@@ -89,8 +138,9 @@ func (s *SlackService) OpenIncidentModal(triggerID string, channelInfo model.Sla
 					Optional: true,
 				},
 				&slack.InputBlock{
-					Type:    "input",
-					BlockID: "urgency_block",
+					Type:           "input",
+					BlockID:        UrgencyBlockID,
+					DispatchAction: true,
 					Label: &slack.TextBlockObject{
 						Type:  "plain_text",
 						Text:  "Urgency",
@@ -98,70 +148,67 @@ func (s *SlackService) OpenIncidentModal(triggerID string, channelInfo model.Sla
 					},
 					Element: &slack.SelectBlockElement{
 						Type:     slack.OptTypeStatic,
-						ActionID: "urgency",
+						ActionID: UrgencyActionID,
 						Placeholder: &slack.TextBlockObject{
 							Type:  "plain_text",
 							Text:  "Select urgency level",
 							Emoji: true,
 						},
-						Options: []*slack.OptionBlockObject{
-							{
-								Text: &slack.TextBlockObject{
-									Type:  "plain_text",
-									Text:  "Critical",
-									Emoji: true,
-								},
-								Value: "critical",
-							},
-							{
-								Text: &slack.TextBlockObject{
-									Type:  "plain_text",
-									Text:  "High",
-									Emoji: true,
-								},
-								Value: "high",
-							},
-							{
-								Text: &slack.TextBlockObject{
-									Type:  "plain_text",
-									Text:  "Medium",
-									Emoji: true,
-								},
-								Value: "medium",
-							},
-							{
-								Text: &slack.TextBlockObject{
-									Type:  "plain_text",
-									Text:  "Low",
-									Emoji: true,
-								},
-								Value: "low",
-							},
-						},
-						InitialOption: &slack.OptionBlockObject{
-							Text: &slack.TextBlockObject{
-								Type:  "plain_text",
-								Text:  "Medium",
-								Emoji: true,
-							},
-							Value: "medium",
+						Options:       urgencyOptions,
+						InitialOption: initialOption(urgencyOptions, urgency),
+					},
+				},
+				&slack.InputBlock{
+					Type:    "input",
+					BlockID: "services_block",
+					Label: &slack.TextBlockObject{
+						Type:  "plain_text",
+						Text:  "Impacted services",
+						Emoji: true,
+					},
+					Element: &slack.MultiSelectBlockElement{
+						Type:     slack.MultiOptTypeExternal,
+						ActionID: "services",
+						Placeholder: &slack.TextBlockObject{
+							Type:  "plain_text",
+							Text:  "Search OpsGenie services",
+							Emoji: true,
 						},
+						MinQueryLength: &minQueryLength,
 					},
+					Optional: true,
 				},
 			},
 		},
-		CallbackID:      "incident_modal",
+		CallbackID:      IncidentModalCallbackID,
 		ClearOnClose:    true,
 		NotifyOnClose:   false,
-		PrivateMetadata: s.createPrivateMetadata(channelInfo),
+		PrivateMetadata: s.createPrivateMetadata(metadata),
 	}
 
+	blocks := modalView.Blocks.BlockSet
+	if len(form.Templates) > 0 {
+		blocks = append([]slack.Block{templateSelectBlock(form.Templates, template)}, blocks...)
+	}
+	if critical {
+		blocks = append(blocks, criticalImpactBlocks()...)
+	}
+	blocks = append(blocks, teamBlocks(form.Team, form.OnCall)...)
+	if template != nil {
+		blocks = append(blocks, templateFieldBlocks(template)...)
+	}
+	modalView.Blocks.BlockSet = blocks
+
+	return modalView
+}
+
+func (s *SlackService) OpenView(triggerID string, view slack.ModalViewRequest) error {
 	s.logger.WithFields(logrus.Fields{
-		"trigger_id": triggerID,
-		"channel_id": channelInfo.ChannelID,
+		"trigger_id":  triggerID,
+		"callback_id": view.CallbackID,
 	}).Debug("Opening modal")
 
-	_, err := s.client.OpenView(triggerID, modalView)
+	_, err := s.client.OpenView(triggerID, view)
 	if err != nil {
 		if err.Error() == "expired_trigger_id" {
 			return fmt.Errorf("trigger ID expired, please try again")
@@ -171,17 +218,26 @@ func (s *SlackService) OpenIncidentModal(triggerID string, channelInfo model.Sla
 
 	return nil
 }
-func (s *SlackService) createPrivateMetadata(channelInfo model.SlackCommand) string {
-	metadata := struct {
-		ChannelID   string `json:"channelId"`
-		ChannelName string `json:"channelName"`
-		TeamDomain  string `json:"teamDomain"`
-	}{
-		ChannelID:   channelInfo.ChannelID,
-		ChannelName: channelInfo.ChannelName,
-		TeamDomain:  channelInfo.TeamDomain,
+
+func (s *SlackService) UpdateView(viewID, hash string, view slack.ModalViewRequest) error {
+	s.logger.WithFields(logrus.Fields{
+		"view_id":     viewID,
+		"callback_id": view.CallbackID,
+	}).Debug("Updating modal")
+
+	_, err := s.client.UpdateView(view, "", hash, viewID)
+	if err != nil {
+		if err.Error() == "hash_conflict" {
+			s.logger.WithField("view_id", viewID).Debug("Modal changed since it was loaded, skipping update")
+			return nil
+		}
+		return fmt.Errorf("failed to update modal: %w", err)
 	}
 
+	return nil
+}
+
+func (s *SlackService) createPrivateMetadata(metadata model.ModalMetadata) string {
 	bytes, err := json.Marshal(metadata)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to marshal private metadata")
@@ -190,6 +246,28 @@ func (s *SlackService) createPrivateMetadata(channelInfo model.SlackCommand) str
 	return string(bytes)
 }
 
+func (s *SlackService) GetPermalink(channelID, messageTS string) (string, error) {
+	permalink, err := s.client.GetPermalink(&slack.PermalinkParameters{
+		Channel: channelID,
+		Ts:      messageTS,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get permalink: %w", err)
+	}
+	return permalink, nil
+}
+
+func (s *SlackService) GetUserName(userID string) (string, error) {
+	user, err := s.client.GetUserInfo(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user info: %w", err)
+	}
+	if user.RealName != "" {
+		return user.RealName, nil
+	}
+	return user.Name, nil
+}
+
 func (s *SlackService) SendMessage(channelID string, text string, blocks []slack.Block) error {
 	options := []slack.MsgOption{
 		slack.MsgOptionText(text, false),
@@ -210,3 +288,222 @@ func (s *SlackService) SendMessage(channelID string, text string, blocks []slack
 
 	return nil
 }
+
+func (s *SlackService) SendEphemeralMessage(channelID, userID, text string, blocks []slack.Block) error {
+	options := []slack.MsgOption{
+		slack.MsgOptionText(text, false),
+	}
+
+	if len(blocks) > 0 {
+		options = append(options, slack.MsgOptionBlocks(blocks...))
+	}
+
+	_, err := s.client.PostEphemeral(channelID, userID, options...)
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"channel_id": channelID,
+			"user_id":    userID,
+		}).Error("Failed to send ephemeral message")
+		return fmt.Errorf("failed to send ephemeral message: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SlackService) ReplaceOriginalMessage(responseURL, text string, blocks []slack.Block) error {
+	options := []slack.MsgOption{
+		slack.MsgOptionReplaceOriginal(responseURL),
+		slack.MsgOptionText(text, false),
+	}
+
+	if len(blocks) > 0 {
+		options = append(options, slack.MsgOptionBlocks(blocks...))
+	}
+
+	if _, _, err := s.client.PostMessage("", options...); err != nil {
+		return fmt.Errorf("failed to replace original message: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SlackService) SendResponse(responseURL, text string, blocks []slack.Block) error {
+	options := []slack.MsgOption{
+		slack.MsgOptionResponseURL(responseURL, slack.ResponseTypeEphemeral),
+		slack.MsgOptionText(text, false),
+	}
+
+	if len(blocks) > 0 {
+		options = append(options, slack.MsgOptionBlocks(blocks...))
+	}
+
+	if _, _, err := s.client.PostMessage("", options...); err != nil {
+		return fmt.Errorf("failed to send response: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SlackService) DeleteOriginalMessage(responseURL string) error {
+	if _, _, err := s.client.PostMessage("", slack.MsgOptionDeleteOriginal(responseURL)); err != nil {
+		return fmt.Errorf("failed to delete original message: %w", err)
+	}
+	return nil
+}
+
+func (s *SlackService) GetMessage(channelID, messageTS string) (*slack.Message, error) {
+	history, err := s.client.GetConversationHistory(&slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Latest:    messageTS,
+		Oldest:    messageTS,
+		Inclusive: true,
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation history: %w", err)
+	}
+	for _, msg := range history.Messages {
+		if msg.Timestamp == messageTS {
+			return &msg, nil
+		}
+	}
+
+	replies, _, _, err := s.client.GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: messageTS,
+		Latest:    messageTS,
+		Oldest:    messageTS,
+		Inclusive: true,
+		Limit:     1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation replies: %w", err)
+	}
+	for _, msg := range replies {
+		if msg.Timestamp == messageTS {
+			return &msg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("message %s not found in channel %s", messageTS, channelID)
+}
+
+func (s *SlackService) PublishHomeView(userID string, blocks []slack.Block) error {
+	view := slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+
+	if _, err := s.client.PublishView(userID, view, ""); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to publish home view")
+		return fmt.Errorf("failed to publish home view: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SlackService) GetUserEmail(userID string) (string, error) {
+	user, err := s.client.GetUserInfo(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user info: %w", err)
+	}
+	return user.Profile.Email, nil
+}
+
+func (s *SlackService) LookupUserIDByEmail(email string) (string, error) {
+	user, err := s.client.GetUserByEmail(email)
+	if err != nil {
+		return "", fmt.Errorf("failed to lookup user by email: %w", err)
+	}
+	return user.ID, nil
+}
+
+func (s *SlackService) PostMessage(channelID, threadTS, text string, blocks []slack.Block) (string, error) {
+	_, ts, err := s.PostMessageWithChannel(channelID, threadTS, text, blocks)
+	return ts, err
+}
+
+func (s *SlackService) PostMessageWithChannel(channelID, threadTS, text string, blocks []slack.Block) (string, string, error) {
+	options := []slack.MsgOption{
+		slack.MsgOptionText(text, false),
+	}
+
+	if threadTS != "" {
+		options = append(options, slack.MsgOptionTS(threadTS))
+	}
+	if len(blocks) > 0 {
+		options = append(options, slack.MsgOptionBlocks(blocks...))
+	}
+
+	channel, ts, err := s.client.PostMessage(channelID, options...)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to post message: %w", err)
+	}
+
+	return channel, ts, nil
+}
+
+func (s *SlackService) UpdateMessage(channelID, messageTS, text string, blocks []slack.Block) error {
+	options := []slack.MsgOption{
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(blocks...),
+	}
+
+	if _, _, _, err := s.client.UpdateMessage(channelID, messageTS, options...); err != nil {
+		return fmt.Errorf("failed to update message: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SlackService) CreateChannel(name string, private bool) (*slack.Channel, error) {
+	channel, err := s.client.CreateConversation(slack.CreateConversationParams{
+		ChannelName: name,
+		IsPrivate:   private,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel %s: %w", name, err)
+	}
+	return channel, nil
+}
+
+func (s *SlackService) InviteUsers(channelID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	if _, err := s.client.InviteUsersToConversation(channelID, userIDs...); err == nil {
+		return nil
+	}
+
+	var failed []string
+	for _, userID := range userIDs {
+		if _, err := s.client.InviteUsersToConversation(channelID, userID); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"channel_id": channelID,
+				"user_id":    userID,
+			}).Warn("Failed to invite user to channel")
+			failed = append(failed, userID)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to invite users %v to channel %s", failed, channelID)
+	}
+	return nil
+}
+
+func (s *SlackService) GetUserGroupMembers(groupID string) ([]string, error) {
+	members, err := s.client.GetUserGroupMembers(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user group members: %w", err)
+	}
+	return members, nil
+}
+
+func (s *SlackService) PinMessage(channelID, messageTS string) error {
+	if err := s.client.AddPin(channelID, slack.NewRefToMessage(channelID, messageTS)); err != nil {
+		return fmt.Errorf("failed to pin message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	userMappingKeyPrefix = "users:opsgenie:"
+	userMappingTTL       = 24 * time.Hour
+	userMissingTTL       = time.Hour
+)
+
+type UserDirectory struct {
+	slackService *SlackService
+	alertService *AlertService
+	store        store.Store
+	logger       *logrus.Logger
+}
+
+func NewUserDirectory(
+	slackService *SlackService,
+	alertService *AlertService,
+	store store.Store,
+	logger *logrus.Logger,
+) *UserDirectory {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &UserDirectory{
+		slackService: slackService,
+		alertService: alertService,
+		store:        store,
+		logger:       logger,
+	}
+}
+
+func (d *UserDirectory) OpsGenieUser(slackUserID string) (string, bool) {
+	if slackUserID == "" {
+		return "", false
+	}
+
+	key := userMappingKeyPrefix + slackUserID
+	if cached, ok, err := d.store.Get(key); err != nil {
+		d.logger.WithError(err).Warn("Failed to read cached user mapping")
+	} else if ok {
+		return cached, cached != ""
+	}
+
+	logger := d.logger.WithField("user_id", slackUserID)
+
+	email, err := d.slackService.GetUserEmail(slackUserID)
+	if err != nil || email == "" {
+		logger.WithError(err).Debug("No Slack email to match an OpsGenie user")
+		d.remember(key, "", userMissingTTL)
+		return "", false
+	}
+
+	user, err := d.alertService.GetUser(email)
+	if err != nil {
+		if !IsNotFound(err) {
+			logger.WithError(err).Warn("Failed to look up OpsGenie user")
+			return "", false
+		}
+		logger.Debug("Slack user has no matching OpsGenie user")
+		d.remember(key, "", userMissingTTL)
+		return "", false
+	}
+
+	d.remember(key, user.Username, userMappingTTL)
+	return user.Username, user.Username != ""
+}
+
+func (d *UserDirectory) remember(key, username string, ttl time.Duration) {
+	if err := d.store.Set(key, username, ttl); err != nil {
+		d.logger.WithError(err).Warn("Failed to cache user mapping")
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/model"
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/store"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const workspaceKeyPrefix = "workspace:"
+
+var ErrWorkspaceNotInstalled = errors.New("workspace is not installed")
+
+type WorkspaceService struct {
+	config func() config.OAuthConfig
+	store  store.Store
+	logger *logrus.Logger
+}
+
+func NewWorkspaceService(oauthConfig func() config.OAuthConfig, store store.Store, logger *logrus.Logger) *WorkspaceService {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &WorkspaceService{
+		config: oauthConfig,
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (s *WorkspaceService) Install(code string) (*model.Workspace, error) {
+	oauthConfig := s.config()
+	resp, err := slack.GetOAuthV2Response(slackHTTPClient, oauthConfig.ClientID, oauthConfig.ClientSecret, code, oauthConfig.RedirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange oauth code: %w", err)
+	}
+	if resp.Team.ID == "" || resp.AccessToken == "" {
+		return nil, fmt.Errorf("oauth response is missing the team or bot token")
+	}
+
+	workspace := model.Workspace{
+		TeamID:       resp.Team.ID,
+		TeamName:     resp.Team.Name,
+		EnterpriseID: resp.Enterprise.ID,
+		AppID:        resp.AppID,
+		BotUserID:    resp.BotUserID,
+		BotToken:     resp.AccessToken,
+		Scope:        resp.Scope,
+		InstalledBy:  resp.AuthedUser.ID,
+		InstalledAt:  time.Now(),
+	}
+
+	if existing, err := s.Get(workspace.TeamID); err == nil {
+		workspace.OpsGenieAPIKey = existing.OpsGenieAPIKey
+		workspace.OpsGenieTeamID = existing.OpsGenieTeamID
+		workspace.OpsGenieDomain = existing.OpsGenieDomain
+	}
+
+	if err := s.Save(workspace); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"team_id":      workspace.TeamID,
+		"team_name":    workspace.TeamName,
+		"installed_by": workspace.InstalledBy,
+	}).Info("Workspace installed")
+	return &workspace, nil
+}
+
+func (s *WorkspaceService) Get(teamID string) (*model.Workspace, error) {
+	data, ok, err := s.store.Get(workspaceKeyPrefix + teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace %s: %w", teamID, err)
+	}
+	if !ok {
+		return nil, ErrWorkspaceNotInstalled
+	}
+
+	var workspace model.Workspace
+	if err := json.Unmarshal([]byte(data), &workspace); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace %s: %w", teamID, err)
+	}
+	return &workspace, nil
+}
+
+func (s *WorkspaceService) Save(workspace model.Workspace) error {
+	data, err := json.Marshal(workspace)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workspace: %w", err)
+	}
+	if err := s.store.Set(workspaceKeyPrefix+workspace.TeamID, string(data), 0); err != nil {
+		return fmt.Errorf("failed to save workspace %s: %w", workspace.TeamID, err)
+	}
+	return nil
+}
+
+func (s *WorkspaceService) ConnectOpsGenie(teamID, apiKey, opsgenieTeamID, domain string) error {
+	workspace, err := s.Get(teamID)
+	if err != nil {
+		return err
+	}
+
+	workspace.OpsGenieAPIKey = apiKey
+	workspace.OpsGenieTeamID = opsgenieTeamID
+	workspace.OpsGenieDomain = domain
+	return s.Save(*workspace)
+}
+
+func (s *WorkspaceService) Delete(teamID string) error {
+	if err := s.store.Delete(workspaceKeyPrefix + teamID); err != nil {
+		return fmt.Errorf("failed to delete workspace %s: %w", teamID, err)
+	}
+	s.logger.WithField("team_id", teamID).Info("Workspace uninstalled")
+	return nil
+}
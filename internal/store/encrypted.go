@@ -0,0 +1,161 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type EncryptedStore struct {
+	inner Store
+
+	mu    sync.RWMutex
+	keys  [][]byte
+	aeads []cipher.AEAD
+}
+
+func NewEncryptedStore(inner Store, key []byte, previous ...[]byte) (*EncryptedStore, error) {
+	s := &EncryptedStore{inner: inner}
+	if err := s.Rotate(append([][]byte{key}, previous...)...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *EncryptedStore) Rotate(keys ...[]byte) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no encryption key")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		ring  [][]byte
+		aeads []cipher.AEAD
+	)
+	for _, key := range append(keys, s.keys...) {
+		if containsKey(ring, key) {
+			continue
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return err
+		}
+		ring = append(ring, key)
+		aeads = append(aeads, aead)
+	}
+	s.keys, s.aeads = ring, aeads
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return aead, nil
+}
+
+func containsKey(keys [][]byte, key []byte) bool {
+	for _, existing := range keys {
+		if bytes.Equal(existing, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *EncryptedStore) Get(key string) (string, bool, error) {
+	value, ok, err := s.inner.Get(key)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+
+	plain, err := s.open(key, value)
+	if err != nil {
+		return "", false, err
+	}
+	return plain, true, nil
+}
+
+func (s *EncryptedStore) Set(key, value string, ttl time.Duration) error {
+	sealed, err := s.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return s.inner.Set(key, sealed, ttl)
+}
+
+func (s *EncryptedStore) SetIfAbsent(key, value string, ttl time.Duration) (bool, error) {
+	sealed, err := s.seal(key, value)
+	if err != nil {
+		return false, err
+	}
+	return s.inner.SetIfAbsent(key, sealed, ttl)
+}
+
+func (s *EncryptedStore) Increment(key string, delta int64, ttl time.Duration) (int64, error) {
+	return s.inner.Increment(key, delta, ttl)
+}
+
+func (s *EncryptedStore) Delete(key string) error {
+	return s.inner.Delete(key)
+}
+
+func (s *EncryptedStore) DeleteIfValue(key, value string) (bool, error) {
+	sealed, ok, err := s.inner.Get(key)
+	if err != nil || !ok {
+		return false, err
+	}
+	plain, err := s.open(key, sealed)
+	if err != nil || plain != value {
+		return false, err
+	}
+	return s.inner.DeleteIfValue(key, sealed)
+}
+
+func (s *EncryptedStore) seal(key, value string) (string, error) {
+	s.mu.RLock()
+	aead := s.aeads[0]
+	s.mu.RUnlock()
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(key))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *EncryptedStore) open(key, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted value for %s: %w", key, err)
+	}
+
+	s.mu.RLock()
+	aeads := s.aeads
+	s.mu.RUnlock()
+
+	for _, aead := range aeads {
+		size := aead.NonceSize()
+		if len(data) < size {
+			return "", fmt.Errorf("encrypted value for %s is too short", key)
+		}
+		if plain, err := aead.Open(nil, data[:size], data[size:], []byte(key)); err == nil {
+			return string(plain), nil
+		}
+	}
+	return "", fmt.Errorf("failed to decrypt value for %s with any configured key", key)
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const lockPollInterval = 20 * time.Millisecond
+
+var (
+	ErrLockTimeout = errors.New("timed out waiting for lock")
+	ErrLockLost    = errors.New("lock expired before it was released")
+)
+
+func Lock(st Store, key string, ttl, wait time.Duration) (func() error, error) {
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		acquired, err := st.SetIfAbsent(key, token, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take lock %s: %w", key, err)
+		}
+		if acquired {
+			return func() error {
+				released, err := st.DeleteIfValue(key, token)
+				if err != nil {
+					return fmt.Errorf("failed to release lock %s: %w", key, err)
+				}
+				if !released {
+					return fmt.Errorf("%w: %s", ErrLockLost, key)
+				}
+				return nil
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w %s", ErrLockTimeout, key)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+func lockToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
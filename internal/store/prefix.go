@@ -0,0 +1,36 @@
+package store
+
+import "time"
+
+type PrefixStore struct {
+	inner  Store
+	prefix string
+}
+
+func NewPrefixStore(inner Store, prefix string) *PrefixStore {
+	return &PrefixStore{inner: inner, prefix: prefix}
+}
+
+func (s *PrefixStore) Get(key string) (string, bool, error) {
+	return s.inner.Get(s.prefix + key)
+}
+
+func (s *PrefixStore) Set(key, value string, ttl time.Duration) error {
+	return s.inner.Set(s.prefix+key, value, ttl)
+}
+
+func (s *PrefixStore) SetIfAbsent(key, value string, ttl time.Duration) (bool, error) {
+	return s.inner.SetIfAbsent(s.prefix+key, value, ttl)
+}
+
+func (s *PrefixStore) Increment(key string, delta int64, ttl time.Duration) (int64, error) {
+	return s.inner.Increment(s.prefix+key, delta, ttl)
+}
+
+func (s *PrefixStore) Delete(key string) error {
+	return s.inner.Delete(s.prefix + key)
+}
+
+func (s *PrefixStore) DeleteIfValue(key, value string) (bool, error) {
+	return s.inner.DeleteIfValue(s.prefix+key, value)
+}
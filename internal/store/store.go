@@ -0,0 +1,170 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hcavarsan/slack-opsgenie-bot/internal/config"
+)
+
+type Store interface {
+	Get(key string) (string, bool, error)
+	Set(key, value string, ttl time.Duration) error
+	SetIfAbsent(key, value string, ttl time.Duration) (bool, error)
+	Increment(key string, delta int64, ttl time.Duration) (int64, error)
+	Delete(key string) error
+	DeleteIfValue(key, value string) (bool, error)
+}
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+const memorySweepInterval = time.Minute
+
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]entry
+	sweepEvery time.Duration
+	lastSweep  time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:    make(map[string]entry),
+		sweepEvery: memorySweepInterval,
+		lastSweep:  time.Now(),
+	}
+}
+
+func (s *MemoryStore) Get(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+	if e.expired(time.Now()) {
+		delete(s.entries, key)
+		return "", false, nil
+	}
+	return e.value, true, nil
+}
+
+func (s *MemoryStore) Set(key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+	s.entries[key] = newEntry(value, ttl)
+	return nil
+}
+
+func (s *MemoryStore) SetIfAbsent(key, value string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+	if e, ok := s.entries[key]; ok && !e.expired(time.Now()) {
+		return false, nil
+	}
+	s.entries[key] = newEntry(value, ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Increment(key string, delta int64, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+	e, ok := s.entries[key]
+	if !ok || e.expired(time.Now()) {
+		e = newEntry("0", ttl)
+	}
+	count, err := parseCounter(key, e.value)
+	if err != nil {
+		return 0, err
+	}
+	count += delta
+	e.value = strconv.FormatInt(count, 10)
+	s.entries[key] = e
+	return count, nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) DeleteIfValue(key, value string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.expired(time.Now()) || e.value != value {
+		return false, nil
+	}
+	delete(s.entries, key)
+	return true, nil
+}
+
+func (s *MemoryStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < s.sweepEvery {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if e.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func newEntry(value string, ttl time.Duration) entry {
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+	return e
+}
+
+func parseCounter(key, value string) (int64, error) {
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a counter: %w", key, err)
+	}
+	return count, nil
+}
+
+func New(storeConfig config.StoreConfig) (Store, error) {
+	switch storeConfig.Backend {
+	case config.StoreBackendFile:
+		fileStore, err := NewFileStore(storeConfig.Path)
+		if err != nil {
+			return nil, err
+		}
+		return fileStore, nil
+	case config.StoreBackendRedis:
+		redisStore, err := NewRedisStore(storeConfig.RedisURL, storeConfig.KeyPrefix)
+		if err != nil {
+			return nil, err
+		}
+		return redisStore, nil
+	case config.StoreBackendMemory, "":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", storeConfig.Backend)
+	}
+}
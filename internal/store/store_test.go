@@ -0,0 +1,325 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func testStore(t *testing.T, st Store) {
+	t.Helper()
+
+	if _, ok, err := st.Get("missing"); err != nil || ok {
+		t.Fatalf("Get(missing) = %v, %v, want not found", ok, err)
+	}
+
+	if err := st.Set("key", "value", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if value, ok, err := st.Get("key"); err != nil || !ok || value != "value" {
+		t.Fatalf("Get(key) = %q, %v, %v, want value", value, ok, err)
+	}
+
+	if set, err := st.SetIfAbsent("key", "other", 0); err != nil || set {
+		t.Fatalf("SetIfAbsent(existing) = %v, %v, want false", set, err)
+	}
+	if set, err := st.SetIfAbsent("fresh", "first", 0); err != nil || !set {
+		t.Fatalf("SetIfAbsent(fresh) = %v, %v, want true", set, err)
+	}
+	if value, _, _ := st.Get("fresh"); value != "first" {
+		t.Fatalf("Get(fresh) = %q, want first", value)
+	}
+
+	if err := st.Delete("key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok, _ := st.Get("key"); ok {
+		t.Fatal("Get(key) found a deleted key")
+	}
+	if set, err := st.SetIfAbsent("key", "again", 0); err != nil || !set {
+		t.Fatalf("SetIfAbsent(deleted) = %v, %v, want true", set, err)
+	}
+
+	for i, want := range []int64{1, 2, 3} {
+		if count, err := st.Increment("counter", 1, time.Minute); err != nil || count != want {
+			t.Fatalf("Increment() #%d = %d, %v, want %d", i+1, count, err, want)
+		}
+	}
+	if count, err := st.Increment("counter", -1, time.Minute); err != nil || count != 2 {
+		t.Fatalf("Increment(-1) = %d, %v, want 2", count, err)
+	}
+	if _, err := st.Increment("fresh", 1, time.Minute); err == nil {
+		t.Fatal("Increment() of a non-counter value succeeded")
+	}
+
+	if deleted, err := st.DeleteIfValue("fresh", "other"); err != nil || deleted {
+		t.Fatalf("DeleteIfValue(other value) = %v, %v, want false", deleted, err)
+	}
+	if _, ok, _ := st.Get("fresh"); !ok {
+		t.Fatal("DeleteIfValue() removed a key holding another value")
+	}
+	if deleted, err := st.DeleteIfValue("fresh", "first"); err != nil || !deleted {
+		t.Fatalf("DeleteIfValue(matching value) = %v, %v, want true", deleted, err)
+	}
+	if _, ok, _ := st.Get("fresh"); ok {
+		t.Fatal("DeleteIfValue() left the key behind")
+	}
+	if err := st.Set("fresh", "first", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+}
+
+func testExpiry(t *testing.T, st Store, advance func(time.Duration)) {
+	t.Helper()
+
+	if err := st.Set("short", "value", 50*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	advance(100 * time.Millisecond)
+	if _, ok, _ := st.Get("short"); ok {
+		t.Fatal("Get(short) found an expired key")
+	}
+	if set, err := st.SetIfAbsent("short", "again", time.Minute); err != nil || !set {
+		t.Fatalf("SetIfAbsent(expired) = %v, %v, want true", set, err)
+	}
+
+	if _, err := st.Increment("window", 5, 50*time.Millisecond); err != nil {
+		t.Fatalf("Increment() error = %v", err)
+	}
+	if _, err := st.Increment("window", 1, 50*time.Millisecond); err != nil {
+		t.Fatalf("Increment() error = %v", err)
+	}
+	advance(100 * time.Millisecond)
+	if count, err := st.Increment("window", 1, 50*time.Millisecond); err != nil || count != 1 {
+		t.Fatalf("Increment(expired) = %d, %v, want 1", count, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+	testExpiry(t, NewMemoryStore(), time.Sleep)
+}
+
+func TestMemoryStorePrunesExpiredKeys(t *testing.T) {
+	st := NewMemoryStore()
+	st.sweepEvery = 0
+
+	for _, key := range []string{"events:1", "events:2", "events:3"} {
+		if set, err := st.SetIfAbsent(key, "1", 10*time.Millisecond); err != nil || !set {
+			t.Fatalf("SetIfAbsent(%s) = %v, %v, want true", key, set, err)
+		}
+	}
+	if err := st.Set("kept", "value", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if set, err := st.SetIfAbsent("events:4", "1", time.Minute); err != nil || !set {
+		t.Fatalf("SetIfAbsent(events:4) = %v, %v, want true", set, err)
+	}
+	if len(st.entries) != 2 {
+		t.Fatalf("store holds %d entries, want the 2 unexpired ones", len(st.entries))
+	}
+	if _, ok, _ := st.Get("kept"); !ok {
+		t.Fatal("sweep removed an unexpired key")
+	}
+}
+
+func TestMemoryStoreSweepsAtMostOncePerInterval(t *testing.T) {
+	st := NewMemoryStore()
+	if err := st.Set("short", "value", time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := st.Set("other", "value", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, ok := st.entries["short"]; !ok {
+		t.Fatal("swept before the interval elapsed")
+	}
+
+	st.lastSweep = time.Now().Add(-memorySweepInterval)
+	if err := st.Set("other", "value", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, ok := st.entries["short"]; ok {
+		t.Fatal("expired key survived a sweep")
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "store.json")
+	st, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	testStore(t, st)
+	testExpiry(t, st, time.Sleep)
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	if value, ok, _ := reopened.Get("fresh"); !ok || value != "first" {
+		t.Fatalf("reopened Get(fresh) = %q, %v, want first", value, ok)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	st, err := NewRedisStore("redis://"+server.Addr(), "test:")
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	testStore(t, st)
+	testExpiry(t, st, server.FastForward)
+
+	if !server.Exists("test:fresh") {
+		t.Fatal("key prefix was not applied")
+	}
+}
+
+func TestPrefixStore(t *testing.T) {
+	inner := NewMemoryStore()
+	testStore(t, NewPrefixStore(inner, "T1:"))
+
+	if _, ok, _ := inner.Get("T1:fresh"); !ok {
+		t.Fatal("prefix was not applied")
+	}
+	if _, ok, _ := NewPrefixStore(inner, "T2:").Get("fresh"); ok {
+		t.Fatal("prefixed stores share keys")
+	}
+}
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestEncryptedStore(t *testing.T) {
+	inner := NewMemoryStore()
+	st, err := NewEncryptedStore(inner, testKey(1))
+	if err != nil {
+		t.Fatalf("NewEncryptedStore() error = %v", err)
+	}
+	testStore(t, st)
+	testExpiry(t, st, time.Sleep)
+
+	if raw, _, _ := inner.Get("fresh"); raw == "" || strings.Contains(raw, "first") {
+		t.Fatalf("inner value %q is not encrypted", raw)
+	}
+
+	sealed, _, _ := inner.Get("fresh")
+	if err := inner.Set("moved", sealed, 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, _, err := st.Get("moved"); err == nil {
+		t.Fatal("Get() decrypted a value copied to another key")
+	}
+
+	if _, err := NewEncryptedStore(inner, []byte("short")); err == nil {
+		t.Fatal("NewEncryptedStore() accepted an invalid key")
+	}
+}
+
+func TestEncryptedStoreRotation(t *testing.T) {
+	inner := NewMemoryStore()
+	st, err := NewEncryptedStore(inner, testKey(1))
+	if err != nil {
+		t.Fatalf("NewEncryptedStore() error = %v", err)
+	}
+	if err := st.Set("old", "sealed with key 1", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if err := st.Rotate(testKey(2)); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if err := st.Set("new", "sealed with key 2", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	for key, want := range map[string]string{"old": "sealed with key 1", "new": "sealed with key 2"} {
+		if value, ok, err := st.Get(key); err != nil || !ok || value != want {
+			t.Fatalf("Get(%s) = %q, %v, %v, want %q", key, value, ok, err, want)
+		}
+	}
+
+	restarted, err := NewEncryptedStore(inner, testKey(2))
+	if err != nil {
+		t.Fatalf("NewEncryptedStore() error = %v", err)
+	}
+	if _, _, err := restarted.Get("old"); err == nil {
+		t.Fatal("Get(old) succeeded without the previous key")
+	}
+
+	restarted, err = NewEncryptedStore(inner, testKey(2), testKey(1))
+	if err != nil {
+		t.Fatalf("NewEncryptedStore() error = %v", err)
+	}
+	if value, _, err := restarted.Get("old"); err != nil || value != "sealed with key 1" {
+		t.Fatalf("Get(old) with previous key = %q, %v", value, err)
+	}
+}
+
+func testLock(t *testing.T, st Store, advance func(time.Duration)) {
+	t.Helper()
+
+	unlock, err := Lock(st, "lock:a", time.Minute, time.Second)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	if _, err := Lock(st, "lock:a", time.Minute, 50*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("second Lock() error = %v, want %v", err, ErrLockTimeout)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		release, err := Lock(st, "lock:a", time.Minute, time.Second)
+		if err == nil {
+			err = release()
+		}
+		acquired <- err
+	}()
+
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock() error = %v", err)
+	}
+	if err := <-acquired; err != nil {
+		t.Fatalf("Lock() after release error = %v", err)
+	}
+
+	expired, err := Lock(st, "lock:b", 50*time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	advance(100 * time.Millisecond)
+	current, err := Lock(st, "lock:b", time.Minute, time.Second)
+	if err != nil {
+		t.Fatalf("Lock() after expiry error = %v", err)
+	}
+	if err := expired(); !errors.Is(err, ErrLockLost) {
+		t.Fatalf("releasing an expired lock error = %v, want %v", err, ErrLockLost)
+	}
+	if _, err := Lock(st, "lock:b", time.Minute, 50*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Lock() error = %v, want the current holder's lock kept", err)
+	}
+	if err := current(); err != nil {
+		t.Fatalf("unlock() error = %v", err)
+	}
+}
+
+func TestLock(t *testing.T) {
+	testLock(t, NewMemoryStore(), time.Sleep)
+
+	server := miniredis.RunT(t)
+	st, err := NewRedisStore("redis://"+server.Addr(), "test:")
+	if err != nil {
+		t.Fatalf("NewRedisStore() error = %v", err)
+	}
+	testLock(t, st, server.FastForward)
+}